@@ -2,19 +2,28 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/scaryPonens/ev-oracle/internal/db"
-	"github.com/scaryPonens/ev-oracle/internal/embedding"
 	"github.com/scaryPonens/ev-oracle/internal/models"
 	"github.com/spf13/cobra"
 )
 
 var (
-	capacity  float64
-	power     float64
-	chemistry string
+	capacity         float64
+	power            float64
+	chemistry        string
+	addTags          []string
+	addAuthoritative bool
+	addNotes         string
+	addBodyStyle     string
+	addSource        string
+	addConfidence    float64
+	addIfNotExists   bool
+	addForce         bool
 )
 
 // addCmd represents the add command
@@ -24,8 +33,17 @@ var addCmd = &cobra.Command{
 	Long: `Add an electric vehicle specification to the database with embedding.
 This command is useful for populating the database with known EV specs.
 
+If the spec is already stored, add fails unless --force is given to
+overwrite it or --if-not-exists to leave it alone and exit successfully.
+
 Example:
-  ev-oracle add Tesla "Model 3" 2023 --capacity 75.0 --power 283.0 --chemistry "NMC"`,
+  ev-oracle add Tesla "Model 3" 2023 --capacity 75.0 --power 283.0 --chemistry "NMC"
+  ev-oracle add Tesla "Model 3" 2023 --capacity 75.0 --power 283.0 --chemistry "NMC" --tag verified --tag 2024-refresh
+  ev-oracle add Tesla "Model 3" 2023 --capacity 75.0 --power 283.0 --chemistry "NMC" --authoritative
+  ev-oracle add Tesla "Model 3" 2023 --capacity 60.0 --power 208.0 --chemistry "LFP" --notes "LFP on RWD, NMC on Long Range"
+  ev-oracle add Hyundai "Ioniq 5" 2023 --capacity 77.4 --power 239.0 --chemistry "NMC" --body-style crossover
+  ev-oracle add Tesla "Model 3" 2023 --capacity 75.0 --power 283.0 --chemistry "NMC" --source manufacturer --confidence 1.0
+  ev-oracle add Tesla "Model 3" 2023 --capacity 78.1 --power 283.0 --chemistry "NMC" --force`,
 	Args: cobra.ExactArgs(3),
 	RunE: runAdd,
 }
@@ -35,6 +53,15 @@ func init() {
 	addCmd.Flags().Float64Var(&capacity, "capacity", 0, "Battery capacity in kWh (required)")
 	addCmd.Flags().Float64Var(&power, "power", 0, "Power output in kW (required)")
 	addCmd.Flags().StringVar(&chemistry, "chemistry", "", "Battery chemistry type (required)")
+	addCmd.Flags().StringArrayVar(&addTags, "tag", nil, "Tag to attach to the spec (repeatable)")
+	addCmd.Flags().StringVar(&addNotes, "notes", "", "Free-text notes, e.g. trim differences or where the data came from")
+	addCmd.Flags().StringVar(&addBodyStyle, "body-style", "", "Body style: SUV, Sedan, Hatchback, Wagon, Coupe, Van or Truck (aliases such as crossover are normalized)")
+	addCmd.Flags().StringVar(&addSource, "source", "database", "Provenance of the spec, e.g. manufacturer")
+	addCmd.Flags().Float64Var(&addConfidence, "confidence", 0, "Confidence to report for the spec, between 0 and 1 (default: derived from the source)")
+	addCmd.Flags().BoolVar(&addAuthoritative, "authoritative", false, "Mark the spec as hand-verified so it is never overwritten by saved LLM answers or non-authoritative adds")
+	addCmd.Flags().BoolVar(&addIfNotExists, "if-not-exists", false, "Skip without error if the spec is already stored")
+	addCmd.Flags().BoolVar(&addForce, "force", false, "Overwrite the spec if it is already stored")
+	addCmd.MarkFlagsMutuallyExclusive("if-not-exists", "force")
 	addCmd.MarkFlagRequired("capacity")
 	addCmd.MarkFlagRequired("power")
 	addCmd.MarkFlagRequired("chemistry")
@@ -50,6 +77,39 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid year: %s", yearStr)
 	}
 
+	if err := models.ValidateQuery(make, model, year); err != nil {
+		return err
+	}
+
+	bodyStyle, err := models.NormalizeBodyStyle(addBodyStyle)
+	if err != nil {
+		return fmt.Errorf("invalid --body-style: %w", err)
+	}
+
+	// Create the EV spec
+	spec := &models.EVSpec{
+		Make:          make,
+		Model:         model,
+		Year:          year,
+		Capacity:      capacity,
+		Power:         power,
+		Chemistry:     strings.TrimSpace(chemistry),
+		Tags:          addTags,
+		Notes:         strings.TrimSpace(addNotes),
+		BodyStyle:     bodyStyle,
+		Source:        strings.TrimSpace(addSource),
+		Confidence:    addConfidence,
+		Authoritative: addAuthoritative,
+	}
+
+	if err := models.ValidateSpec(spec); err != nil {
+		var verr *models.ValidationError
+		if errors.As(err, &verr) {
+			return flagErrors(verr)
+		}
+		return err
+	}
+
 	// Load configuration
 	cfg, err := models.NewConfig()
 	if err != nil {
@@ -58,40 +118,46 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 
-	// Initialize database client
-	dbClient, err := db.New(ctx, cfg.DatabaseURL)
+	// Open the spec store selected by STORE_BACKEND
+	store, err := openSpecStore(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
 	}
-	defer dbClient.Close()
+	defer store.Close()
 
 	// Initialize embedding service
-	embeddingSvc := embedding.NewWithProvider(
-		embedding.ProviderType(cfg.EmbeddingProvider),
-		cfg.OpenAIAPIKey,
-		cfg.OllamaURL,
-		cfg.OllamaModel,
-	)
-
-	// Create the EV spec
-	spec := &models.EVSpec{
-		Make:      make,
-		Model:     model,
-		Year:      year,
-		Capacity:  capacity,
-		Power:     power,
-		Chemistry: chemistry,
-	}
+	embeddingSvc := newEmbeddingService(cfg, embeddingCache(store))
+	warnDocumentTemplate(ctx, store, embeddingSvc)
 
 	// Generate embedding
-	queryText := embedding.BuildQueryText(make, model, year)
-	embeddingVector, err := embeddingSvc.GetEmbedding(queryText)
+	documentText, err := embeddingSvc.DocumentText(make, model, year, spec.BodyStyle, spec.Notes)
+	if err != nil {
+		return err
+	}
+	embeddingVector, err := embeddingSvc.GetEmbedding(ctx, documentText)
 	if err != nil {
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
+	policy := db.ConflictError
+	switch {
+	case addForce:
+		policy = db.ConflictUpdate
+	case addIfNotExists:
+		policy = db.ConflictSkip
+	}
+
 	// Insert into database
-	if err := dbClient.InsertEVSpec(ctx, spec, embeddingVector); err != nil {
+	if err := store.InsertEVSpec(ctx, spec, embeddingVector, db.WithConflictPolicy(policy)); err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpecExists) && addIfNotExists:
+			fmt.Printf("%d %s %s is already in the database; skipped\n", year, make, model)
+			return nil
+		case errors.Is(err, db.ErrSpecExists):
+			return fmt.Errorf("%w; pass --force to overwrite it or --if-not-exists to skip it", err)
+		case errors.Is(err, db.ErrAuthoritative):
+			return fmt.Errorf("%w; pass --force --authoritative to replace it", err)
+		}
 		return fmt.Errorf("failed to insert spec: %w", err)
 	}
 
@@ -99,6 +165,32 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Capacity: %.1f kWh\n", capacity)
 	fmt.Printf("  Power: %.1f kW\n", power)
 	fmt.Printf("  Chemistry: %s\n", chemistry)
+	if len(addTags) > 0 {
+		fmt.Printf("  Tags: %s\n", strings.Join(addTags, ", "))
+	}
+	if spec.BodyStyle != "" {
+		fmt.Printf("  Body style: %s\n", spec.BodyStyle)
+	}
+	if spec.Notes != "" {
+		fmt.Printf("  Notes: %s\n", spec.Notes)
+	}
+	fmt.Printf("  Source: %s\n", spec.Source)
+	if spec.Confidence > 0 {
+		fmt.Printf("  Confidence: %.2f\n", spec.Confidence)
+	}
+	if addAuthoritative {
+		fmt.Println("  Authoritative: yes")
+	}
 
 	return nil
 }
+
+// flagErrors reports every invalid spec field under its add flag name
+func flagErrors(verr *models.ValidationError) error {
+	var sb strings.Builder
+	sb.WriteString("invalid flags:")
+	for _, f := range verr.Fields {
+		fmt.Fprintf(&sb, "\n  --%s %s", f.Field, f.Message)
+	}
+	return errors.New(sb.String())
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchConcurrency int
+	batchUnordered   bool
+	batchOutput      outputOptions
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch [file]",
+	Short: "Resolve many EV specifications from a CSV file",
+	Long: `Resolve every make,model,year row of a CSV file (or - for stdin) through the
+same pipeline as the main query, using several workers in parallel.
+
+A header row of make,model,year is optional. Results are printed in input
+order by default. With --format ndjson each result is written as one JSON
+line carrying its input key (line, make, model, year); add --unordered to
+emit lines as soon as they complete, so slow LLM-backed rows don't hold up
+fast database hits.
+
+Before resolving, the worst case of one embedding and one LLM call per row is
+estimated on stderr (with a cost if LLM_PRICE_PER_MTOK is set), and the run
+is refused without --yes if the file has more than LLM_CALL_THRESHOLD rows.
+The calls actually made are printed at the end.
+
+Examples:
+  ev-oracle batch vehicles.csv
+  ev-oracle batch vehicles.csv --format csv > specs.csv
+  ev-oracle batch vehicles.csv --format ndjson --unordered --concurrency 8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBatch,
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", 4, "Number of queries to resolve in parallel")
+	batchCmd.Flags().BoolVar(&batchUnordered, "unordered", false, "Emit results as they complete instead of in input order (ndjson only)")
+	addOutputFlags(batchCmd, &batchOutput)
+	addCostFlags(batchCmd)
+	batchCmd.Flags().Lookup("format").Usage = "Output format: text, json, csv, table, markdown or ndjson"
+}
+
+// batchLine is one NDJSON output line of the batch command
+type batchLine struct {
+	Line  int            `json:"line"`
+	Make  string         `json:"make"`
+	Model string         `json:"model"`
+	Year  int            `json:"year"`
+	Spec  *models.EVSpec `json:"spec,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	ndjson := batchOutput.template == "" && batchOutput.format == "ndjson"
+	if ndjson {
+		if batchOutput.meta {
+			return fmt.Errorf("--meta requires --format json")
+		}
+		if err := batchOutput.validateConfidence(); err != nil {
+			return err
+		}
+	} else {
+		if batchUnordered {
+			return fmt.Errorf("--unordered requires --format ndjson")
+		}
+		if err := batchOutput.validate(); err != nil {
+			return err
+		}
+	}
+
+	queries, lines, err := readQueriesFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	batchOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	// Open the spec store and create the embedding and LLM services
+	calls := &callCounter{}
+	res, err := newResolver(ctx, cfg,
+		resolver.WithEmbeddingOptions(embedding.WithMetrics(calls)),
+		resolver.WithLLMOptions(llm.WithMetrics(calls)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	warnDocumentTemplate(ctx, res.Store(), res.Embedding())
+
+	est, err := estimateCost(res.LLM(), queries, len(queries), len(queries))
+	if err != nil {
+		return err
+	}
+	if err := checkCost(cfg, est); err != nil {
+		return err
+	}
+	defer calls.report()
+
+	var specs []models.EVSpec
+	failed := 0
+	encoder := json.NewEncoder(os.Stdout)
+
+	err = res.ResolveBatch(batchOutput.track(ctx), queries, batchConcurrency, !batchUnordered, func(result resolver.BatchResult) error {
+		line := lines[result.Index]
+		if result.Err != nil {
+			failed++
+		}
+
+		if ndjson {
+			if result.Spec != nil {
+				result.Spec.ConfidenceBand = batchOutput.bands.Band(result.Spec.Confidence)
+			}
+			out := batchLine{
+				Line:  line,
+				Make:  result.Query.Make,
+				Model: result.Query.Model,
+				Year:  result.Query.Year,
+				Spec:  result.Spec,
+			}
+			if result.Err != nil {
+				out.Error = result.Err.Error()
+			}
+			return encoder.Encode(out)
+		}
+
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "line %d (%d %s %s): %v\n", line, result.Query.Year, result.Query.Make, result.Query.Model, result.Err)
+			return nil
+		}
+		specs = append(specs, *result.Spec)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !ndjson {
+		if err := batchOutput.writeSpecs(specs); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", failed, len(queries))
+	}
+	return nil
+}
+
+// readQueriesFile reads make,model,year queries from a CSV file, or stdin for "-".
+// It also returns the input line number of each query for error reporting.
+func readQueriesFile(path string) ([]resolver.Query, []int, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	queries, lines, err := readQueries(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(queries) == 0 {
+		return nil, nil, fmt.Errorf("no queries found in %s", path)
+	}
+	return queries, lines, nil
+}
+
+// readQueries parses make,model,year CSV records, skipping an optional header row
+func readQueries(r io.Reader) ([]resolver.Query, []int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var queries []resolver.Query
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(queries) == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "make") {
+			continue // header row
+		}
+		if len(record) < 3 {
+			return nil, nil, fmt.Errorf("line %d: expected make,model,year", line)
+		}
+
+		yearStr := strings.TrimSpace(record[2])
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid year: %s", line, yearStr)
+		}
+
+		queries = append(queries, resolver.Query{
+			Make:  strings.TrimSpace(record[0]),
+			Model: strings.TrimSpace(record[1]),
+			Year:  year,
+		})
+		lines = append(lines, line)
+	}
+
+	return queries, lines, nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var cacheClearModel string
+
+// cacheCmd groups the embedding cache commands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the embedding cache stored in the database",
+	Long: `Embeddings are cached in the embedding_cache table, keyed by provider and
+model and the SHA-256 of the input text, so repeated queries skip the
+embedding provider across processes. Pass --no-embed-cache to any command to
+bypass the cache.`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached embeddings",
+	Long: `Delete every cached embedding, or only those of one model with --model.
+Models are named provider:model, e.g. openai:text-embedding-3-small or
+ollama:nomic-embed-text.
+
+Examples:
+  ev-oracle cache clear
+  ev-oracle cache clear --model ollama:nomic-embed-text`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheClearCmd.Flags().StringVar(&cacheClearModel, "model", "", "Only delete embeddings of this provider:model")
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := models.NewConfig(models.WithDatabaseOnly())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	deleted, err := dbClient.ClearEmbeddingCache(ctx, cacheClearModel)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d cached embedding(s)\n", deleted)
+	return nil
+}
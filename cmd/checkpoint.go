@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// checkpoint records the keys of items a long-running command has finished,
+// one per line, so an interrupted run can be resumed. It is safe for
+// concurrent use. A nil *checkpoint tracks nothing.
+type checkpoint struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+// openCheckpoint opens the checkpoint file at path. With resume the keys it
+// records are loaded and new ones appended. Otherwise the run starts empty,
+// and a file recording finished keys is refused rather than truncated, so
+// forgetting --resume cannot throw away the progress of an interrupted run.
+func openCheckpoint(path string, resume bool) (*checkpoint, error) {
+	c := &checkpoint{path: path, done: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read progress file: %w", err)
+	}
+	// A line cut short by a crash has no newline and is not counted
+	lines := strings.Split(string(data), "\n")
+	for _, key := range lines[:len(lines)-1] {
+		if key = strings.TrimSpace(key); key != "" {
+			c.done[key] = true
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		if len(c.done) > 0 {
+			return nil, fmt.Errorf("%s records %d finished item(s) of an earlier run; pass --resume to continue it, or delete the file to start over", path, len(c.done))
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open progress file: %w", err)
+	}
+	c.file = file
+	return c, nil
+}
+
+// Done reports whether key was finished by a previous run
+func (c *checkpoint) Done(key string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[key]
+}
+
+// Mark records key as finished, syncing so the record survives a crash
+func (c *checkpoint) Mark(key string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintln(c.file, key); err != nil {
+		return fmt.Errorf("failed to record progress: %w", err)
+	}
+	if err := c.file.Sync(); err != nil {
+		return fmt.Errorf("failed to record progress: %w", err)
+	}
+	return nil
+}
+
+// Close closes the checkpoint file
+func (c *checkpoint) Close() error {
+	if c == nil || c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// Remove deletes the checkpoint file once nothing is left to resume
+func (c *checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+	c.Close()
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove progress file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.progress")
+
+	first, err := openCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("openCheckpoint: %v", err)
+	}
+	for _, key := range []string{"tesla|model3|2022", "nissan|leaf|2020"} {
+		if err := first.Mark(key); err != nil {
+			t.Fatalf("Mark(%q): %v", key, err)
+		}
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	resumed, err := openCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("openCheckpoint with resume: %v", err)
+	}
+	defer resumed.Close()
+	if !resumed.Done("tesla|model3|2022") || !resumed.Done("nissan|leaf|2020") {
+		t.Error("keys marked by the first run are not done after resuming")
+	}
+	if resumed.Done("kia|ev6|2023") {
+		t.Error("unmarked key is done")
+	}
+}
+
+func TestCheckpointIgnoresTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.progress")
+	// The run crashed while writing its last key
+	if err := os.WriteFile(path, []byte("12\n3"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := openCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("openCheckpoint: %v", err)
+	}
+	defer c.Close()
+	if !c.Done("12") {
+		t.Error("complete line is not done")
+	}
+	if c.Done("3") {
+		t.Error("line cut short by a crash is counted as done")
+	}
+}
+
+func TestNilCheckpointTracksNothing(t *testing.T) {
+	var c *checkpoint
+	if c.Done("key") {
+		t.Error("nil checkpoint reports a key as done")
+	}
+	if err := c.Mark("key"); err != nil {
+		t.Errorf("Mark on nil checkpoint: %v", err)
+	}
+	if err := c.Remove(); err != nil {
+		t.Errorf("Remove on nil checkpoint: %v", err)
+	}
+}
+
+func TestCheckpointRefusesToDiscardProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.progress")
+	if err := os.WriteFile(path, []byte("12\n13\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if c, err := openCheckpoint(path, false); err == nil {
+		c.Close()
+		t.Fatal("openCheckpoint without resume accepted a file with recorded progress")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "12\n13\n" {
+		t.Errorf("progress file = %q, want it left as it was", data)
+	}
+}
+
+func TestCheckpointStartsFreshOverTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.progress")
+	if err := os.WriteFile(path, []byte("1"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := openCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("openCheckpoint: %v", err)
+	}
+	defer c.Close()
+	if c.Done("1") {
+		t.Error("fresh run reports a key as done")
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+// approveCost lets a job run past LLM_CALL_THRESHOLD
+var approveCost bool
+
+// addCostFlags adds --yes to a command that checks its cost estimate
+func addCostFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&approveCost, "yes", "y", false, "Run even if the estimated LLM calls exceed LLM_CALL_THRESHOLD")
+}
+
+// costEstimate is the pre-flight estimate of a batch job's provider calls
+type costEstimate struct {
+	llmCalls     int // upper bound on LLM queries
+	embedCalls   int // upper bound on embedding requests
+	inputTokens  int
+	outputTokens int
+}
+
+// estimateCost estimates llmCalls LLM queries and embedCalls embedding
+// requests, sizing prompts by the average prompt of queries
+func estimateCost(llmSvc *llm.Service, queries []resolver.Query, llmCalls, embedCalls int) (costEstimate, error) {
+	est := costEstimate{llmCalls: llmCalls, embedCalls: embedCalls}
+	if llmCalls == 0 || len(queries) == 0 {
+		return est, nil
+	}
+
+	total := 0
+	for _, q := range queries {
+		tokens, err := llmSvc.PromptTokens(q.Make, q.Model, q.Year)
+		if err != nil {
+			return est, err
+		}
+		total += tokens
+	}
+	est.inputTokens = total / len(queries) * llmCalls
+	est.outputTokens = llm.EstimatedResponseTokens * llmCalls
+	return est, nil
+}
+
+// describe summarizes the estimate, with a dollar cost when prices are known
+func (e costEstimate) describe(cfg *models.Config) string {
+	s := fmt.Sprintf("up to %d LLM call(s) (~%d input, ~%d output tokens", e.llmCalls, e.inputTokens, e.outputTokens)
+	if cfg.LLMInputPrice > 0 || cfg.LLMOutputPrice > 0 {
+		cost := (float64(e.inputTokens)*cfg.LLMInputPrice + float64(e.outputTokens)*cfg.LLMOutputPrice) / 1e6
+		s += fmt.Sprintf(", ~$%.2f", cost)
+	}
+	return s + fmt.Sprintf(") and %d embedding call(s)", e.embedCalls)
+}
+
+// checkCost prints the estimate on stderr and refuses to go on when it
+// exceeds LLM_CALL_THRESHOLD, unless --yes was given. NewConfig has already
+// replaced a threshold of 0 with the default of 100.
+func checkCost(cfg *models.Config, est costEstimate) error {
+	fmt.Fprintf(os.Stderr, "Estimated: %s\n", est.describe(cfg))
+	if cfg.LLMCallThreshold < 0 || est.llmCalls <= cfg.LLMCallThreshold || approveCost {
+		return nil
+	}
+	return fmt.Errorf("refusing to make up to %d LLM calls (LLM_CALL_THRESHOLD is %d); pass --yes to proceed", est.llmCalls, cfg.LLMCallThreshold)
+}
+
+// callCounter is a metrics.Recorder counting the provider calls actually made
+type callCounter struct {
+	llm       atomic.Int64
+	embedding atomic.Int64
+}
+
+// IncCounter implements metrics.Recorder
+func (c *callCounter) IncCounter(name string, labels map[string]string) {
+	switch name {
+	case metrics.LLMRequestsTotal:
+		c.llm.Add(1)
+	case metrics.EmbeddingRequestsTotal:
+		c.embedding.Add(1)
+	}
+}
+
+// ObserveHistogram implements metrics.Recorder
+func (c *callCounter) ObserveHistogram(string, float64, map[string]string) {}
+
+// report prints the calls made on stderr, for comparison with the estimate
+func (c *callCounter) report() {
+	fmt.Fprintf(os.Stderr, "Used: %d LLM call(s) and %d embedding call(s)\n", c.llm.Load(), c.embedding.Load())
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteMake      string
+	deleteChemistry string
+	deleteSource    string
+	deleteTags      []string
+	deleteAnyTag    bool
+	deleteConfirm   bool
+	deleteYes       bool
+	deleteDryRun    bool
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete all EV specifications matching a filter",
+	Long: `Delete every stored EV specification matching a filter. This is intended for
+cleaning up after a bad import or batch.
+
+At least one filter is required, and so is --confirm. The matching specs are
+listed first and you are asked before anything is deleted; pass --yes to skip
+the preview and prompt. Deleted specs are kept in the history table. With
+--dry-run the matching specs are listed and nothing is deleted.
+
+Examples:
+  ev-oracle delete --make Tesla --source llm --dry-run
+  ev-oracle delete --make Tesla --source llm --confirm
+  ev-oracle delete --tag bad-import --confirm --yes`,
+	Args: cobra.NoArgs,
+	RunE: runDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().StringVar(&deleteMake, "make", "", "Only delete specs for this make")
+	deleteCmd.Flags().StringVar(&deleteChemistry, "chemistry", "", "Only delete specs with this battery chemistry")
+	deleteCmd.Flags().StringVar(&deleteSource, "source", "", "Only delete specs with this source (database or llm)")
+	deleteCmd.Flags().StringArrayVar(&deleteTags, "tag", nil, "Only delete specs with this tag (repeatable)")
+	deleteCmd.Flags().BoolVar(&deleteAnyTag, "any-tag", false, "Match specs with any of the given tags instead of all of them")
+	deleteCmd.Flags().BoolVar(&deleteConfirm, "confirm", false, "Required to delete anything")
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip the preview and confirmation prompt")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "List the specs that would be deleted without deleting them")
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	filter := db.SpecFilter{
+		Make:        deleteMake,
+		Chemistry:   deleteChemistry,
+		Source:      deleteSource,
+		Tags:        deleteTags,
+		MatchAnyTag: deleteAnyTag,
+	}
+	if filter.Make == "" && filter.Chemistry == "" && filter.Source == "" && len(filter.Tags) == 0 {
+		return fmt.Errorf("at least one of --make, --chemistry, --source or --tag is required")
+	}
+	if !deleteConfirm && !deleteDryRun {
+		return fmt.Errorf("refusing to delete without --confirm")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	if !deleteYes || deleteDryRun {
+		specs, err := dbClient.ListSpecs(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list specs: %w", err)
+		}
+		if len(specs) == 0 {
+			fmt.Println("No specs match the filter.")
+			return nil
+		}
+
+		verb := "will"
+		if deleteDryRun {
+			verb = "would"
+		}
+		fmt.Printf("The following %d spec(s) %s be deleted:\n", len(specs), verb)
+		for _, spec := range specs {
+			fmt.Printf("  %s %s %d (%s)\n", spec.Make, spec.Model, spec.Year, spec.Source)
+		}
+		if deleteDryRun {
+			printDryRun("delete", len(specs), "spec")
+			return nil
+		}
+		fmt.Print("Delete these specs? [y/N] ")
+
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && answer == "" {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	deleted, err := dbClient.DeleteWhere(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d spec(s).\n", deleted)
+	return nil
+}
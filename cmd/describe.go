@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	describeJSON bool
+)
+
+// describeCmd represents the describe command
+var describeCmd = &cobra.Command{
+	Use:   "describe [make] [model] [year]",
+	Short: "Show everything stored for an EV specification",
+	Long: `Show the stored record for an EV specification, including where it came from.
+
+For specs saved from the LLM fallback (SAVE_LLM_RESULTS=true) with
+STORE_LLM_RAW_RESPONSE=true, the raw model output is printed as well, which
+helps track down extraction bugs.
+
+Example:
+  ev-oracle describe Rivian R1T 2023
+  ev-oracle describe --json Rivian R1T 2023`,
+	Args: cobra.ExactArgs(3),
+	RunE: runDescribe,
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+	describeCmd.Flags().BoolVar(&describeJSON, "json", false, "Output result in JSON format")
+}
+
+func runDescribe(cmd *cobra.Command, args []string) error {
+	make := args[0]
+	model := args[1]
+	yearStr := args[2]
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return fmt.Errorf("invalid year: %s", yearStr)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	spec, err := dbClient.GetByMakeModelYear(ctx, make, model, year)
+	if err != nil {
+		return fmt.Errorf("database query error: %w", err)
+	}
+	if spec == nil {
+		return fmt.Errorf("no stored spec for %d %s %s", year, make, model)
+	}
+
+	if describeJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(spec); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Printf("Make:       %s\n", spec.Make)
+	fmt.Printf("Model:      %s\n", spec.Model)
+	fmt.Printf("Year:       %d\n", spec.Year)
+	fmt.Printf("Capacity:   %.1f kWh\n", spec.Capacity)
+	fmt.Printf("Power:      %.1f kW\n", spec.Power)
+	fmt.Printf("Chemistry:  %s\n", spec.Chemistry)
+	fmt.Printf("Source:     %s\n", spec.Source)
+	if spec.Authoritative {
+		fmt.Println("Authoritative: yes")
+	}
+	if len(spec.Tags) > 0 {
+		fmt.Printf("Tags:       %s\n", strings.Join(spec.Tags, ", "))
+	}
+	if spec.BodyStyle != "" {
+		fmt.Printf("Body style: %s\n", spec.BodyStyle)
+	}
+	if spec.Notes != "" {
+		fmt.Printf("Notes:      %s\n", spec.Notes)
+	}
+	if spec.RawResponse != "" {
+		fmt.Printf("\nRaw LLM response:\n%s\n", strings.TrimRight(spec.RawResponse, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// distributionBarWidth is the length of the longest bar in the chart
+const distributionBarWidth = 40
+
+var (
+	distributionField     string
+	distributionBuckets   int
+	distributionMake      string
+	distributionChemistry string
+	distributionJSON      bool
+)
+
+// distributionCmd represents the distribution command
+var distributionCmd = &cobra.Command{
+	Use:   "distribution",
+	Short: "Show a histogram of capacity, power or year across stored specs",
+	Long: `Bucket a numeric field of the stored EV specifications into equal-width ranges
+between its smallest and largest value and print the counts as a bar chart.
+Specs without a value are counted separately. Years are bucketed by whole
+years. Only the database is read.
+
+Examples:
+  ev-oracle distribution
+  ev-oracle distribution --field power --buckets 5 --make Tesla
+  ev-oracle distribution --field year --json`,
+	Args: cobra.NoArgs,
+	RunE: runDistribution,
+}
+
+func init() {
+	rootCmd.AddCommand(distributionCmd)
+	distributionCmd.Flags().StringVar(&distributionField, "field", "capacity", "Field to bucket: capacity, power or year")
+	distributionCmd.Flags().IntVar(&distributionBuckets, "buckets", 10, "Number of buckets")
+	distributionCmd.Flags().StringVar(&distributionMake, "make", "", "Only include specs for this make")
+	distributionCmd.Flags().StringVar(&distributionChemistry, "chemistry", "", "Only include specs with this battery chemistry")
+	distributionCmd.Flags().BoolVar(&distributionJSON, "json", false, "Output result in JSON format")
+}
+
+func runDistribution(cmd *cobra.Command, args []string) error {
+	field, err := db.ParseDistributionField(distributionField)
+	if err != nil {
+		return err
+	}
+	if distributionBuckets <= 0 {
+		return fmt.Errorf("--buckets must be positive")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig(models.WithDatabaseOnly())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	filter := db.SpecFilter{Make: distributionMake, Chemistry: distributionChemistry}
+	dist, err := dbClient.Distribution(ctx, field, distributionBuckets, filter)
+	if err != nil {
+		return err
+	}
+
+	if distributionJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(dist); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	if dist.Total == 0 {
+		fmt.Printf("No specs with a %s value\n", field)
+		return nil
+	}
+
+	labels := make([]string, len(dist.Buckets))
+	labelWidth, largest := 0, 0
+	for i, b := range dist.Buckets {
+		labels[i] = bucketLabel(field, b)
+		labelWidth = max(labelWidth, len(labels[i]))
+		largest = max(largest, b.Count)
+	}
+	countWidth := len(fmt.Sprint(largest))
+
+	for i, b := range dist.Buckets {
+		// Round up so that every non-empty bucket shows at least one mark
+		bar := strings.Repeat("#", (b.Count*distributionBarWidth+largest-1)/largest)
+		fmt.Printf("%*s  %*d  %s\n", labelWidth, labels[i], countWidth, b.Count, bar)
+	}
+	fmt.Printf("\n%d spec(s) with a %s value", dist.Total, field)
+	if dist.Missing > 0 {
+		fmt.Printf(", %d without", dist.Missing)
+	}
+	fmt.Println()
+	return nil
+}
+
+// bucketLabel formats a bucket's range, e.g. "50.0-62.5 kWh" or "2019-2020"
+func bucketLabel(field db.DistributionField, b db.Bucket) string {
+	switch field {
+	case db.FieldYear:
+		first, last := int(b.Low), int(b.High)-1
+		if first == last {
+			return fmt.Sprint(first)
+		}
+		return fmt.Sprintf("%d-%d", first, last)
+	case db.FieldPower:
+		return fmt.Sprintf("%.1f-%.1f kW", b.Low, b.High)
+	default:
+		return fmt.Sprintf("%.1f-%.1f kWh", b.Low, b.High)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var doctorJSON bool
+
+// knownEmbeddingDimensions lists the dimensions of common embedding models,
+// used to suggest a model that fits the column
+var knownEmbeddingDimensions = []struct {
+	Model     string
+	Dimension int
+}{
+	{"ollama all-minilm", 384},
+	{"ollama nomic-embed-text", 768},
+	{"ollama mxbai-embed-large", 1024},
+	{"openai text-embedding-3-small", 1536},
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the embedding column fits the configured embedding model",
+	Long: `Read the declared dimension of the ev_specs.embedding column from the
+Postgres catalog, make one test embedding with every configured embedding
+provider, and report whether each produces vectors of that dimension. A
+mismatch makes every insert and similarity search fail, so this catches the
+most common misconfiguration before it bites, and suggests a fix.
+
+The command fails if the column cannot be read or any provider does not match.
+
+Examples:
+  ev-oracle doctor
+  ev-oracle doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output result in JSON format")
+}
+
+// doctorReport is the result of the doctor checks
+type doctorReport struct {
+	ColumnDimension  int           `json:"column_dimension"`
+	StoredEmbeddings int           `json:"stored_embeddings"`
+	Providers        []doctorCheck `json:"providers"`
+}
+
+// doctorCheck is the dimension check for one embedding provider
+type doctorCheck struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model,omitempty"`
+	Dimension int    `json:"dimension,omitempty"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	var report doctorReport
+	if report.ColumnDimension, err = dbClient.EmbeddingDimension(ctx); err != nil {
+		return fmt.Errorf("%w; run 'ev-oracle migrate up' to create the schema", err)
+	}
+	if report.StoredEmbeddings, err = dbClient.StoredEmbeddings(ctx); err != nil {
+		return err
+	}
+
+	// Initialize embedding service
+	embeddingSvc := newEmbeddingService(cfg)
+
+	for _, provider := range embeddingSvc.Providers() {
+		check := doctorCheck{Provider: string(provider), Model: embeddingSvc.Model(provider)}
+		probeCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+		check.Dimension, err = embeddingSvc.Probe(probeCtx, provider)
+		cancel()
+		switch {
+		case err != nil:
+			check.Error = err.Error()
+			check.Hint = "fix the provider settings; 'ev-oracle providers' shows the endpoint and model in use"
+		case check.Dimension == report.ColumnDimension:
+			check.OK = true
+		default:
+			check.Hint = dimensionHint(report, check.Dimension)
+		}
+		report.Providers = append(report.Providers, check)
+	}
+
+	if doctorJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	} else {
+		writeDoctorReport(report)
+	}
+
+	failed := 0
+	for _, check := range report.Providers {
+		if !check.OK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d embedding providers do not match the embedding column", failed, len(report.Providers))
+	}
+	return nil
+}
+
+// dimensionHint suggests how to reconcile a provider producing detected
+// dimensions with the column
+func dimensionHint(report doctorReport, detected int) string {
+	if report.StoredEmbeddings == 0 {
+		return fmt.Sprintf("the table has no embeddings yet, so run 'ev-oracle init' to resize the column to %d dimensions", detected)
+	}
+
+	hint := fmt.Sprintf("%d rows already hold %d-dimension embeddings, so switch to a model that produces %d dimensions",
+		report.StoredEmbeddings, report.ColumnDimension, report.ColumnDimension)
+	var fits []string
+	for _, known := range knownEmbeddingDimensions {
+		if known.Dimension == report.ColumnDimension {
+			fits = append(fits, known.Model)
+		}
+	}
+	if len(fits) > 0 {
+		hint += " (e.g. " + strings.Join(fits, ", ") + ")"
+	}
+	return hint + fmt.Sprintf(", or clear them (UPDATE ev_specs SET embedding = NULL), run 'ev-oracle init' to resize the column to %d and 'ev-oracle reembed' to embed every row again", detected)
+}
+
+// writeDoctorReport prints the checks with one OK or MISMATCH line per provider
+func writeDoctorReport(report doctorReport) {
+	fmt.Printf("Embedding column: %d dimensions, %d stored embeddings\n", report.ColumnDimension, report.StoredEmbeddings)
+	for _, check := range report.Providers {
+		name := check.Provider
+		if check.Model != "" {
+			name += " (" + check.Model + ")"
+		}
+		switch {
+		case check.OK:
+			fmt.Printf("OK        %s: %d dimensions\n", name, check.Dimension)
+		case check.Error != "":
+			fmt.Printf("FAILED    %s: %s\n", name, check.Error)
+		default:
+			fmt.Printf("MISMATCH  %s: %d dimensions, column expects %d\n", name, check.Dimension, report.ColumnDimension)
+		}
+		if check.Hint != "" {
+			fmt.Printf("          hint: %s\n", check.Hint)
+		}
+	}
+}
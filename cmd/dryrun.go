@@ -0,0 +1,9 @@
+package cmd
+
+import "fmt"
+
+// printDryRun prints the closing line of a destructive command run with
+// --dry-run, e.g. "Dry run: would delete 3 spec(s); nothing was changed."
+func printDryRun(action string, n int, noun string) {
+	fmt.Printf("Dry run: would %s %d %s(s); nothing was changed.\n", action, n, noun)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	duplicatesThreshold float64
+	duplicatesNeighbors int
+	duplicatesJSON      bool
+)
+
+// findDuplicatesCmd represents the find-duplicates command
+var findDuplicatesCmd = &cobra.Command{
+	Use:   "find-duplicates",
+	Short: "Report stored specs whose embeddings are suspiciously similar",
+	Long: `Compare each stored spec with its nearest neighbors by embedding and report
+pairs of different rows whose cosine similarity is at least --threshold. Such
+pairs are often the same vehicle stored under a misspelled or differently
+cased make or model, or under the wrong year. Only the database is searched;
+no embedding or LLM call is made, and nothing is changed.
+
+Examples:
+  ev-oracle find-duplicates
+  ev-oracle find-duplicates --threshold 0.98 --neighbors 10
+  ev-oracle find-duplicates --json`,
+	Args: cobra.NoArgs,
+	RunE: runFindDuplicates,
+}
+
+func init() {
+	rootCmd.AddCommand(findDuplicatesCmd)
+	findDuplicatesCmd.Flags().Float64Var(&duplicatesThreshold, "threshold", 0.95, "Minimum cosine similarity for a pair to be reported")
+	findDuplicatesCmd.Flags().IntVar(&duplicatesNeighbors, "neighbors", 5, "Nearest neighbors compared with each row")
+	findDuplicatesCmd.Flags().BoolVar(&duplicatesJSON, "json", false, "Output result in JSON format")
+}
+
+func runFindDuplicates(cmd *cobra.Command, args []string) error {
+	if duplicatesThreshold <= 0 || duplicatesThreshold > 1 {
+		return fmt.Errorf("--threshold must be greater than 0 and at most 1")
+	}
+	if duplicatesNeighbors <= 0 {
+		return fmt.Errorf("--neighbors must be positive")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	pairs, err := dbClient.FindDuplicates(ctx, duplicatesThreshold, duplicatesNeighbors)
+	if err != nil {
+		return fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	if duplicatesJSON {
+		if pairs == nil {
+			pairs = []db.DuplicatePair{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(pairs); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	if len(pairs) == 0 {
+		fmt.Printf("No pairs with similarity of at least %g\n", duplicatesThreshold)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIMILARITY\tFIRST\tSECOND")
+	for _, p := range pairs {
+		fmt.Fprintf(tw, "%.4f\t%s %s %d\t%s %s %d\n",
+			p.Similarity, p.First.Make, p.First.Model, p.First.Year, p.Second.Make, p.Second.Model, p.Second.Year)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d suspected duplicate pair(s)\n", len(pairs))
+	return nil
+}
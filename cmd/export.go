@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportGzip   bool
+	exportMake   string
+	exportSource string
+	exportTags   []string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Dump stored EV specifications as CSV",
+	Long: `Write the stored specs, optionally filtered, as CSV in the layout read by
+import, so a knowledge base can be backed up and loaded elsewhere. Rows are
+streamed from the database as they are written, so exports of any size use
+constant memory. --gzip compresses the output on the fly; import detects
+gzip input automatically.
+
+Examples:
+  ev-oracle export > specs.csv
+  ev-oracle export --gzip --output specs.csv.gz
+  ev-oracle export --source llm --tag verified`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "File to write, or - for stdout")
+	exportCmd.Flags().BoolVar(&exportGzip, "gzip", false, "Compress the output with gzip")
+	exportCmd.Flags().StringVar(&exportMake, "make", "", "Only export specs for this make")
+	exportCmd.Flags().StringVar(&exportSource, "source", "", "Only export specs with this source (database or llm)")
+	exportCmd.Flags().StringArrayVar(&exportTags, "tag", nil, "Only export specs with this tag (repeatable)")
+	addProgressFlags(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportGzip && exportOutput == "-" && isTerminal(os.Stdout) {
+		return fmt.Errorf("refusing to write gzip output to a terminal; redirect it or pass --output")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	var out io.Writer = os.Stdout
+	var file *os.File
+	if exportOutput != "-" {
+		file, err = os.Create(exportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output: %w", err)
+		}
+		// Only closes the file on early returns; success closes it below
+		defer file.Close()
+		out = file
+	}
+
+	var gz *gzip.Writer
+	if exportGzip {
+		gz = gzip.NewWriter(out)
+		out = gz
+	}
+
+	cw := format.NewCSVWriter(out)
+	count := 0
+	filter := db.SpecFilter{Make: exportMake, Source: exportSource, Tags: exportTags}
+
+	// No bar is drawn over CSV written to the terminal, and counting costs a
+	// query, so the total is only fetched when the bar is shown
+	bar := progress.Hidden(os.Stderr)
+	if exportOutput != "-" && progressEnabled() {
+		total, err := dbClient.CountSpecs(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to count specs: %w", err)
+		}
+		bar = newProgressBar("Exporting", total)
+	}
+	defer bar.Finish()
+
+	err = dbClient.EachSpec(ctx, filter, func(spec models.EVSpec) error {
+		count++
+		bar.Add(1)
+		return cw.Write(spec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export specs: %w", err)
+	}
+	bar.Finish()
+	if err := cw.Flush(); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to finish gzip stream: %w", err)
+		}
+	}
+	// A failed close can mean the data never reached the disk
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to close output: %w", err)
+		}
+	}
+
+	if exportOutput != "-" && !quiet {
+		fmt.Fprintf(os.Stderr, "Exported %d specs to %s\n", count, exportOutput)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	findCapacity  float64
+	findTolerance float64
+	findChemistry string
+	findBodyStyle string
+	findLimit     int
+	findSort      string
+	findJSON      bool
+)
+
+// findCmd represents the find command
+var findCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Find stored EVs with a battery capacity close to a target",
+	Long: `Search the stored specs by battery capacity instead of by name. Specs whose
+capacity is within --tolerance kWh of --capacity are listed closest first,
+with the absolute difference from the target, optionally limited to one
+chemistry or body style. Only the database is searched; no embedding or LLM
+call is made. --sort orders the matches by make, model, year, capacity or
+power instead, with :desc for descending; --limit still keeps the closest.
+
+Examples:
+  ev-oracle find --capacity 60
+  ev-oracle find --capacity 60 --tolerance 10 --chemistry LFP
+  ev-oracle find --capacity 70 --body-style SUV
+  ev-oracle find --capacity 75 --sort power:desc
+  ev-oracle find --capacity 80 --limit 3 --json`,
+	Args: cobra.NoArgs,
+	RunE: runFind,
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+	findCmd.Flags().Float64Var(&findCapacity, "capacity", 0, "Target battery capacity in kWh (required)")
+	findCmd.Flags().Float64Var(&findTolerance, "tolerance", 10, "Maximum difference from the target capacity in kWh")
+	findCmd.Flags().StringVar(&findChemistry, "chemistry", "", "Only find specs with this battery chemistry")
+	findCmd.Flags().StringVar(&findBodyStyle, "body-style", "", "Only find specs with this body style, e.g. SUV or Sedan")
+	findCmd.Flags().IntVar(&findLimit, "limit", 10, "Maximum number of results")
+	findCmd.Flags().StringVar(&findSort, "sort", "", "Sort by make, model, year, capacity or power, with :desc for descending (default: closest first)")
+	findCmd.Flags().BoolVar(&findJSON, "json", false, "Output result in JSON format")
+	findCmd.MarkFlagRequired("capacity")
+}
+
+func runFind(cmd *cobra.Command, args []string) error {
+	if findCapacity <= 0 {
+		return fmt.Errorf("--capacity must be positive")
+	}
+	if findTolerance < 0 {
+		return fmt.Errorf("--tolerance must not be negative")
+	}
+	if findLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	bodyStyle, err := models.NormalizeBodyStyle(findBodyStyle)
+	if err != nil {
+		return fmt.Errorf("invalid --body-style: %w", err)
+	}
+	sort, err := db.ParseSort(findSort)
+	if err != nil {
+		return fmt.Errorf("invalid --sort: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	filter := db.SpecFilter{Chemistry: findChemistry, BodyStyle: bodyStyle}
+	matches, err := dbClient.FindByCapacity(ctx, findCapacity, findTolerance, filter, findLimit, db.WithSort(sort))
+	if err != nil {
+		return fmt.Errorf("failed to find specs: %w", err)
+	}
+
+	if findJSON {
+		if matches == nil {
+			matches = []db.CapacityMatch{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(matches); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No stored specs within %g kWh of %g kWh\n", findTolerance, findCapacity)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tMAKE\tMODEL\tYEAR\tCAPACITY (kWh)\tDIFF (kWh)\tPOWER (kW)\tCHEMISTRY")
+	for i, m := range matches {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%.1f\t%.1f\t%.1f\t%s\n",
+			i+1, m.Make, m.Model, m.Year, m.Capacity, m.Diff, m.Power, m.Chemistry)
+	}
+	return tw.Flush()
+}
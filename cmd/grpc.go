@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/grpcserver"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	grpcAddr  string
+	grpcProbe bool
+)
+
+// grpcCmd represents the grpc command
+var grpcCmd = &cobra.Command{
+	Use:   "grpc",
+	Short: "Serve EV specifications over gRPC",
+	Long: `Start a gRPC server exposing the EVOracle service defined in
+proto/evoracle/v1/evoracle.proto.
+
+GetSpec resolves a spec through the same pipeline as the query command,
+Search returns the most similar stored specs without consulting the LLM, and
+AddSpec embeds and stores a spec like the add command. The reflection service
+is enabled, so grpcurl can list and call the service without the proto file.
+
+Example:
+  ev-oracle grpc --addr :9090
+  grpcurl -plaintext localhost:9090 list
+  grpcurl -plaintext -d '{"make": "Tesla", "model": "Model 3", "year": 2023}' localhost:9090 evoracle.v1.EVOracle/GetSpec`,
+	Args: cobra.NoArgs,
+	RunE: runGRPC,
+}
+
+func init() {
+	rootCmd.AddCommand(grpcCmd)
+	grpcCmd.Flags().StringVar(&grpcAddr, "addr", ":9090", "Address to listen on")
+	grpcCmd.Flags().BoolVar(&grpcProbe, "probe", false, "Check the embedding and LLM providers before listening")
+}
+
+func runGRPC(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var setupOpts []resolver.SetupOption
+	if grpcProbe {
+		setupOpts = append(setupOpts, resolver.WithProbe())
+	}
+
+	// Connect to the database and create the embedding and LLM services,
+	// failing before listening if any of them is unusable
+	res, err := newResolver(ctx, cfg, setupOpts...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	warnDocumentTemplate(ctx, res.Store(), res.Embedding())
+
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
+	}
+	grpcServer := grpc.NewServer()
+	grpcserver.New(res, grpcserver.WithConfidenceBands(cfg.ConfidenceBands)).Register(grpcServer)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- grpcServer.Serve(listener)
+	}()
+	fmt.Printf("Listening on %s\n", listener.Addr())
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, grpc.ErrServerStopped) {
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Give in-flight calls a moment to finish before exiting
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		grpcServer.Stop()
+	}
+
+	fmt.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthJSON    bool
+	healthTimeout time.Duration
+	healthSkip    []string
+)
+
+// Health check and overall statuses
+const (
+	healthOK       = "ok"
+	healthDown     = "down"
+	healthDegraded = "degraded" // overall only: some checks passed, some failed
+)
+
+// healthCmd represents the health command
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check the database, embedding and LLM backends and report each one's status",
+	Long: `Probe every backend in parallel and report each one's status and latency:
+the spec store (for Postgres, a connection and a read of the embedding column),
+every configured embedding provider (including each EMBEDDING_RACE or
+EMBEDDING_CHAIN provider) and every LLM provider (including each LLM_CHAIN
+provider). A failing backend does not stop the others from being checked.
+
+The overall status is "ok" when every check passes, "down" when all fail and
+"degraded" otherwise. The command exits 0 only when the status is "ok", so it
+can serve as a readiness probe for a serve deployment. Probing the LLM is a
+billed request with Claude; use --skip llm for frequent liveness probes.
+
+Examples:
+  ev-oracle health
+  ev-oracle health --json
+  ev-oracle health --skip llm --timeout 5s`,
+	Args: cobra.NoArgs,
+	RunE: runHealth,
+}
+
+func init() {
+	rootCmd.AddCommand(healthCmd)
+	healthCmd.Flags().BoolVar(&healthJSON, "json", false, "Output result in JSON format")
+	healthCmd.Flags().DurationVar(&healthTimeout, "timeout", 10*time.Second, "Time limit for each check")
+	healthCmd.Flags().StringSliceVar(&healthSkip, "skip", nil, "Backends not to check: database, embedding or llm")
+}
+
+// healthReport is the result of the health command
+type healthReport struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks"`
+}
+
+// healthCheck is the result of probing one backend
+type healthCheck struct {
+	Name      string `json:"name"` // e.g. "database", "embedding/openai" or "llm/ollama"
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthProbe checks one backend, returning a short description on success
+type healthProbe struct {
+	name  string
+	probe func(ctx context.Context) (string, error)
+}
+
+func runHealth(cmd *cobra.Command, args []string) error {
+	for _, skip := range healthSkip {
+		if skip != "database" && skip != "embedding" && skip != "llm" {
+			return fmt.Errorf("invalid --skip: %s (use database, embedding or llm)", skip)
+		}
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	report := healthReport{Checks: runHealthProbes(context.Background(), healthProbes(cfg), healthTimeout)}
+	report.Status = overallHealth(report.Checks)
+
+	if healthJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	} else {
+		writeHealthReport(report)
+	}
+
+	failed := 0
+	for _, check := range report.Checks {
+		if check.Status != healthOK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d health checks failed", failed, len(report.Checks))
+	}
+	return nil
+}
+
+// healthProbes returns the checks for every backend not skipped with --skip
+func healthProbes(cfg *models.Config) []healthProbe {
+	var probes []healthProbe
+	if !slices.Contains(healthSkip, "database") {
+		probes = append(probes, healthProbe{name: "database", probe: func(ctx context.Context) (string, error) {
+			return probeStore(ctx, cfg)
+		}})
+	}
+	if !slices.Contains(healthSkip, "embedding") {
+		embeddingSvc := newEmbeddingService(cfg)
+		for _, provider := range embeddingSvc.Providers() {
+			probes = append(probes, healthProbe{name: "embedding/" + string(provider), probe: func(ctx context.Context) (string, error) {
+				dimension, err := embeddingSvc.Probe(ctx, provider)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d dimensions", dimension), nil
+			}})
+		}
+	}
+	if !slices.Contains(healthSkip, "llm") {
+		llmSvc := newLLMService(cfg)
+		for _, provider := range llmSvc.Providers() {
+			providerSvc := llmSvc.UsingProvider(provider)
+			probes = append(probes, healthProbe{name: "llm/" + string(provider), probe: func(ctx context.Context) (string, error) {
+				if err := providerSvc.Probe(ctx); err != nil {
+					return "", err
+				}
+				return providerSvc.Model(), nil
+			}})
+		}
+	}
+	return probes
+}
+
+// probeStore opens the configured spec store. For Postgres it also reads the
+// embedding column, which fails if the schema has not been created.
+func probeStore(ctx context.Context, cfg *models.Config) (string, error) {
+	if cfg.StoreBackend == "json" {
+		store, err := db.OpenJSONStore(cfg.StorePath)
+		if err != nil {
+			return "", err
+		}
+		store.Close()
+		return "json store " + cfg.StorePath, nil
+	}
+
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return "", err
+	}
+	defer dbClient.Close()
+
+	dimension, err := dbClient.EmbeddingDimension(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("postgres, %d-dimension embedding column", dimension), nil
+}
+
+// runHealthProbes runs every probe in parallel, each bounded by timeout, and
+// returns the results in the order of probes
+func runHealthProbes(ctx context.Context, probes []healthProbe, timeout time.Duration) []healthCheck {
+	checks := make([]healthCheck, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			detail, err := p.probe(probeCtx)
+			check := healthCheck{Name: p.name, Status: healthOK, LatencyMS: time.Since(start).Milliseconds(), Detail: detail}
+			if err != nil {
+				check.Status, check.Error = healthDown, err.Error()
+			}
+			checks[i] = check
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+// overallHealth aggregates the checks: ok if all pass, down if all fail and
+// degraded otherwise
+func overallHealth(checks []healthCheck) string {
+	failed := 0
+	for _, check := range checks {
+		if check.Status != healthOK {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return healthOK
+	case failed == len(checks):
+		return healthDown
+	default:
+		return healthDegraded
+	}
+}
+
+// writeHealthReport prints the overall status and one aligned line per check
+func writeHealthReport(report healthReport) {
+	fmt.Printf("Status: %s\n", report.Status)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tLATENCY\tDETAIL")
+	for _, check := range report.Checks {
+		detail := check.Detail
+		if check.Error != "" {
+			// Connection errors can span lines, which would break the alignment
+			detail = strings.Join(strings.Fields(check.Error), " ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d ms\t%s\n", check.Name, check.Status, check.LatencyMS, detail)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyJSON bool
+	historyRaw  bool
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [make] [model] [year]",
+	Short: "Show past revisions of an EV specification",
+	Long: `Show the current value of an EV specification followed by every superseded
+revision recorded in the history table, newest first.
+
+A revision is recorded automatically whenever a stored spec's values change
+(for example by re-running add with different flags) or the spec is deleted.
+Pass --raw to also print any stored raw LLM responses.
+
+Example:
+  ev-oracle history Tesla "Model 3" 2023
+  ev-oracle history --json Tesla "Model 3" 2023
+  ev-oracle history --raw Rivian R1T 2023`,
+	Args: cobra.ExactArgs(3),
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output result in JSON format")
+	historyCmd.Flags().BoolVar(&historyRaw, "raw", false, "Print stored raw LLM responses after the table")
+}
+
+// historyOutput is the JSON output of the history command
+type historyOutput struct {
+	Current   *models.EVSpec        `json:"current"`
+	Revisions []models.SpecRevision `json:"revisions"`
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	make := args[0]
+	model := args[1]
+	yearStr := args[2]
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return fmt.Errorf("invalid year: %s", yearStr)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	current, err := dbClient.GetByMakeModelYear(ctx, make, model, year)
+	if err != nil {
+		return fmt.Errorf("database query error: %w", err)
+	}
+
+	revisions, err := dbClient.GetHistory(ctx, make, model, year)
+	if err != nil {
+		return err
+	}
+
+	if historyJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		result := historyOutput{current, revisions}
+		if revisions == nil {
+			result.Revisions = []models.SpecRevision{}
+		}
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	if current == nil && len(revisions) == 0 {
+		fmt.Printf("No history found for %d %s %s.\n", year, make, model)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REVISION\tCAPACITY (kWh)\tPOWER (kW)\tCHEMISTRY\tSOURCE\tVALID FROM\tSUPERSEDED")
+	if current != nil {
+		fmt.Fprintf(tw, "current\t%.1f\t%.1f\t%s\t%s\t\t\n", current.Capacity, current.Power, current.Chemistry, current.Source)
+	}
+	for i, rev := range revisions {
+		validFrom := ""
+		if rev.ValidFrom != nil {
+			validFrom = rev.ValidFrom.Format(time.DateTime)
+		}
+		superseded := rev.SupersededAt.Format(time.DateTime)
+		if rev.Operation == "DELETE" {
+			superseded += " (deleted)"
+		}
+		fmt.Fprintf(tw, "-%d\t%.1f\t%.1f\t%s\t%s\t%s\t%s\n", i+1, rev.Capacity, rev.Power, rev.Chemistry, rev.Source, validFrom, superseded)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if historyRaw {
+		if current != nil && current.RawResponse != "" {
+			fmt.Printf("\nRaw LLM response (current):\n%s\n", strings.TrimRight(current.RawResponse, "\n"))
+		}
+		for i, rev := range revisions {
+			if rev.RawResponse != "" {
+				fmt.Printf("\nRaw LLM response (-%d):\n%s\n", i+1, strings.TrimRight(rev.RawResponse, "\n"))
+			}
+		}
+	}
+	return nil
+}
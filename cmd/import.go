@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importSource      string
+	importConfidence  float64
+	importIfNotExists bool
+	importForce       bool
+	importResume      bool
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Add many EV specifications from a CSV file",
+	Long: `Add every row of a CSV file (or - for stdin) to the database, as if each were
+passed to add.
+
+The file needs a header row. The make, model, year, capacity_kwh, power_kw and
+chemistry columns are required; source, confidence, tags (separated by ;),
+notes, body_style, authoritative and chemistry_source are optional, and any
+other column is ignored, so output of export and --format csv can be imported
+as is. Rows without a source or confidence use --source and --confidence. An
+empty or zero capacity or power, and an empty chemistry, are imported as
+missing, as export writes them for partial rows. A chemistry whose
+chemistry_source is inferred or prior is a guess and is not imported.
+
+Rows that fail validation or insertion are reported on stderr with their line
+number and the rest are still imported. Existing specs are left alone with a
+failure unless --force or --if-not-exists is given, as with add.
+
+Each row is inserted on its own, and the line number of every imported row is
+appended to FILE.progress as soon as it is stored. If an import is
+interrupted or some rows fail, re-run it with --resume to skip the rows that
+were already imported, without generating their embeddings again. The
+progress file is removed once every row has been imported; until then, a run
+without --resume refuses to start (delete the file to start over). Do not
+edit the input file between runs.
+
+Examples:
+  ev-oracle import specs.csv --source manufacturer --confidence 1.0
+  ev-oracle import specs.csv --if-not-exists
+  ev-oracle import specs.csv --resume`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importSource, "source", "database", "Provenance for rows without a source column value, e.g. manufacturer")
+	importCmd.Flags().Float64Var(&importConfidence, "confidence", 0, "Confidence for rows without a confidence column value, between 0 and 1 (default: derived from the source)")
+	importCmd.Flags().BoolVar(&importIfNotExists, "if-not-exists", false, "Skip rows whose spec is already stored")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite specs that are already stored")
+	importCmd.Flags().BoolVar(&importResume, "resume", false, "Skip rows recorded as imported by a previous run")
+	importCmd.MarkFlagsMutuallyExclusive("if-not-exists", "force")
+	addProgressFlags(importCmd)
+}
+
+// importRow is one parsed CSV row with its input line number
+type importRow struct {
+	line int
+	spec *models.EVSpec
+	err  error // parse or validation error
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	// The source flag is checked per row by ValidateSpec
+	if importConfidence < 0 || importConfidence > 1 {
+		return fmt.Errorf("--confidence must be between 0 and 1, got %g", importConfidence)
+	}
+
+	if importResume && args[0] == "-" {
+		return fmt.Errorf("--resume requires an input file, not stdin")
+	}
+
+	rows, err := readImportFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Progress is only tracked for files, since stdin cannot be re-read
+	var progress *checkpoint
+	if args[0] != "-" {
+		progress, err = openCheckpoint(args[0]+".progress", importResume)
+		if err != nil {
+			return err
+		}
+		defer progress.Close()
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Open the spec store selected by STORE_BACKEND
+	store, err := openSpecStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// Initialize embedding service
+	embeddingSvc := newEmbeddingService(cfg, embeddingCache(store))
+	warnDocumentTemplate(ctx, store, embeddingSvc)
+
+	policy := db.ConflictError
+	switch {
+	case importForce:
+		policy = db.ConflictUpdate
+	case importIfNotExists:
+		policy = db.ConflictSkip
+	}
+
+	pending := 0
+	for _, row := range rows {
+		if !progress.Done(strconv.Itoa(row.line)) {
+			pending++
+		}
+	}
+	bar := newProgressBar("Importing", pending)
+	defer bar.Finish()
+
+	imported, skipped, failed, resumed := 0, 0, 0, 0
+	for _, row := range rows {
+		if progress.Done(strconv.Itoa(row.line)) {
+			resumed++
+			continue
+		}
+		bar.Add(1)
+		if row.err != nil {
+			fmt.Fprintf(bar, "line %d: %v\n", row.line, row.err)
+			failed++
+			continue
+		}
+
+		spec := row.spec
+
+		// Check for a stored spec first so a conflicting row costs no embedding call
+		if policy != db.ConflictUpdate {
+			existing, err := store.GetByMakeModelYear(ctx, spec.Make, spec.Model, spec.Year)
+			if err != nil {
+				fmt.Fprintf(bar, "line %d: %v\n", row.line, err)
+				failed++
+				continue
+			}
+			if existing != nil {
+				if importIfNotExists {
+					skipped++
+					if err := progress.Mark(strconv.Itoa(row.line)); err != nil {
+						return err
+					}
+					continue
+				}
+				fmt.Fprintf(bar, "line %d: %d %s %s: %v\n", row.line, spec.Year, spec.Make, spec.Model, db.ErrSpecExists)
+				failed++
+				continue
+			}
+		}
+
+		var embeddingVector []float32
+		documentText, err := embeddingSvc.DocumentText(spec.Make, spec.Model, spec.Year, spec.BodyStyle, spec.Notes)
+		if err == nil {
+			embeddingVector, err = embeddingSvc.GetEmbedding(ctx, documentText)
+		}
+		if err != nil {
+			fmt.Fprintf(bar, "line %d (%d %s %s): failed to generate embedding: %v\n", row.line, spec.Year, spec.Make, spec.Model, err)
+			failed++
+			continue
+		}
+
+		err = store.InsertEVSpec(ctx, spec, embeddingVector, db.WithConflictPolicy(policy))
+		switch {
+		case err == nil:
+			imported++
+			if err := progress.Mark(strconv.Itoa(row.line)); err != nil {
+				return err
+			}
+		case errors.Is(err, db.ErrSpecExists) && importIfNotExists:
+			skipped++
+			if err := progress.Mark(strconv.Itoa(row.line)); err != nil {
+				return err
+			}
+		default:
+			fmt.Fprintf(bar, "line %d: %v\n", row.line, err)
+			failed++
+		}
+	}
+	bar.Finish()
+
+	if !quiet {
+		fmt.Printf("Imported: %d\n", imported)
+		if resumed > 0 {
+			fmt.Printf("Resumed:  %d (imported by a previous run)\n", resumed)
+		}
+		if skipped > 0 {
+			fmt.Printf("Skipped:  %d (already stored)\n", skipped)
+		}
+		if failed > 0 {
+			fmt.Printf("Failed:   %d\n", failed)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed; fix them and re-run with --resume", failed, len(rows))
+	}
+	return progress.Remove()
+}
+
+// readImportFile reads spec rows from a CSV file, or stdin for "-". Gzipped
+// input, such as export --gzip output, is detected and decompressed.
+func readImportFile(path string) ([]importRow, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	r, err := maybeGunzip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := readImportRows(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows found in %s", path)
+	}
+	return rows, nil
+}
+
+// gzipMagic are the first two bytes of every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maybeGunzip returns a reader that decompresses r if it starts with the gzip
+// magic bytes, and reads r unchanged otherwise
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(magic, gzipMagic) {
+		// Short or empty input is left for the CSV reader to report
+		return br, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip input: %w", err)
+	}
+	return gz, nil
+}
+
+// importColumns are the CSV columns every import file must have
+var importColumns = []string{"make", "model", "year", "capacity_kwh", "power_kw", "chemistry"}
+
+// readImportRows parses spec rows by header name. Rows that fail to parse or
+// validate are returned with their error so the caller can report them.
+func readImportRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column in header", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		spec, err := parseImportRecord(field)
+		rows = append(rows, importRow{line: line, spec: spec, err: err})
+	}
+
+	return rows, nil
+}
+
+// parseImportRecord builds and validates a spec from one record, filling the
+// source and confidence from the flags when the record has none. Empty or zero
+// capacity and power, and empty chemistry, are imported as missing, as export
+// writes them for rows stored with NULL there.
+func parseImportRecord(field func(name string) string) (*models.EVSpec, error) {
+	year, err := strconv.Atoi(field("year"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid year: %s", field("year"))
+	}
+	capacity, err := parseOptionalFloat(field("capacity_kwh"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid capacity_kwh: %s", field("capacity_kwh"))
+	}
+	power, err := parseOptionalFloat(field("power_kw"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid power_kw: %s", field("power_kw"))
+	}
+
+	spec := &models.EVSpec{
+		Make:       field("make"),
+		Model:      field("model"),
+		Year:       year,
+		Capacity:   capacity,
+		Power:      power,
+		Chemistry:  field("chemistry"),
+		Notes:      field("notes"),
+		Source:     importSource,
+		Confidence: importConfidence,
+	}
+	if source := field("source"); source != "" {
+		spec.Source = source
+	}
+	if authoritative := field("authoritative"); authoritative != "" {
+		if spec.Authoritative, err = strconv.ParseBool(authoritative); err != nil {
+			return nil, fmt.Errorf("invalid authoritative: %s", authoritative)
+		}
+	}
+	if confidence := field("confidence"); confidence != "" {
+		if spec.Confidence, err = strconv.ParseFloat(confidence, 64); err != nil {
+			return nil, fmt.Errorf("invalid confidence: %s", confidence)
+		}
+		// Export writes the confidence a stored spec reports, which is derived
+		// when none was stored; storing it would pin what is now a default
+		derived := models.DefaultConfidence(spec.Source)
+		if spec.Authoritative {
+			derived = 1.0
+		}
+		if spec.Confidence == derived {
+			spec.Confidence = 0
+		}
+	}
+	// Inferred and prior chemistries are guesses that are never stored, so
+	// the row is imported without them and lookups guess again
+	switch field("chemistry_source") {
+	case "":
+	case models.ChemistryInferred, models.SourcePrior:
+		spec.Chemistry = ""
+	default:
+		return nil, fmt.Errorf("invalid chemistry_source: %s", field("chemistry_source"))
+	}
+	if spec.BodyStyle, err = models.NormalizeBodyStyle(field("body_style")); err != nil {
+		return nil, err
+	}
+	for _, tag := range strings.Split(field("tags"), ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			spec.Tags = append(spec.Tags, tag)
+		}
+	}
+
+	if err := models.ValidateQuery(spec.Make, spec.Model, spec.Year); err != nil {
+		return nil, err
+	}
+	if err := models.ValidatePartialSpec(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// parseOptionalFloat parses a number, reading an empty string as 0
+func parseOptionalFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// openTestStore opens an empty json store holding specs
+func openTestStore(t *testing.T, specs ...models.EVSpec) *db.JSONStore {
+	t.Helper()
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	for i := range specs {
+		if err := store.InsertEVSpec(context.Background(), &specs[i], []float32{0.1, 0.2, 0.3}); err != nil {
+			t.Fatalf("failed to insert %s %s: %v", specs[i].Make, specs[i].Model, err)
+		}
+	}
+	return store
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	original := openTestStore(t,
+		models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Capacity: 75, Power: 283, Chemistry: "NMC",
+			Tags: []string{"verified", "fleet"}, Notes: "Long Range, AWD", BodyStyle: "Sedan", Source: "manufacturer"},
+		models.EVSpec{Make: "Hyundai", Model: "Ioniq 5", Year: 2022, Capacity: 77.4, Power: 239, Chemistry: "NMC",
+			Source: "database", Authoritative: true},
+		models.EVSpec{Make: "Kia", Model: "EV6", Year: 2022, Capacity: 77.4, Power: 239, Chemistry: "NMC",
+			Source: "llm", Confidence: 0.7},
+		models.EVSpec{Make: "Rivian", Model: "R1T", Year: 2023, Capacity: 135, Power: 600, Chemistry: "NCA", Source: "llm"},
+		// A partial row, as written outside ev-oracle with NULL capacity, power and chemistry
+		models.EVSpec{Make: "Nissan", Model: "Leaf", Year: 2019, Source: "database"},
+	)
+	want, err := original.ListSpecs(ctx, db.SpecFilter{})
+	if err != nil {
+		t.Fatalf("failed to list specs: %v", err)
+	}
+
+	var csv bytes.Buffer
+	cw := format.NewCSVWriter(&csv)
+	for _, spec := range want {
+		if err := cw.Write(spec); err != nil {
+			t.Fatalf("failed to export %s %s: %v", spec.Make, spec.Model, err)
+		}
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	rows, err := readImportRows(&csv)
+	if err != nil {
+		t.Fatalf("failed to read the export: %v", err)
+	}
+	var imported []models.EVSpec
+	for _, row := range rows {
+		if row.err != nil {
+			t.Fatalf("line %d of the export was rejected: %v", row.line, row.err)
+		}
+		// Only the Kia's confidence was stored; the others were derived
+		if row.spec.Model != "EV6" && row.spec.Confidence != 0 {
+			t.Errorf("%s %s imported with confidence %v, want the derived default", row.spec.Make, row.spec.Model, row.spec.Confidence)
+		}
+		imported = append(imported, *row.spec)
+	}
+
+	got, err := openTestStore(t, imported...).ListSpecs(ctx, db.SpecFilter{})
+	if err != nil {
+		t.Fatalf("failed to list imported specs: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("specs after export and import:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestParseImportRecordDropsGuessedChemistry(t *testing.T) {
+	rows, err := readImportRows(strings.NewReader("make,model,year,capacity_kwh,power_kw,chemistry,chemistry_source\n" +
+		"BYD,Atto 3,2023,60.5,150,LFP,inferred\n" +
+		"BYD,Seal,2023,82.5,390,LFP,\n" +
+		"BYD,Dolphin,2023,44.9,70,LFP,guessed\n"))
+	if err != nil {
+		t.Fatalf("readImportRows: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].err != nil || rows[0].spec.Chemistry != "" {
+		t.Errorf("inferred chemistry row = %+v, %v, want it imported without chemistry", rows[0].spec, rows[0].err)
+	}
+	if rows[1].err != nil || rows[1].spec.Chemistry != "LFP" {
+		t.Errorf("reported chemistry row = %+v, %v, want chemistry LFP", rows[1].spec, rows[1].err)
+	}
+	if rows[2].err == nil {
+		t.Error("unknown chemistry_source was accepted")
+	}
+}
+
+func TestParseImportRecordRejectsInvalidAuthoritative(t *testing.T) {
+	rows, err := readImportRows(strings.NewReader("make,model,year,capacity_kwh,power_kw,chemistry,authoritative\n" +
+		"Tesla,Model 3,2023,75,283,NMC,maybe\n"))
+	if err != nil {
+		t.Fatalf("readImportRows: %v", err)
+	}
+	if len(rows) != 1 || rows[0].err == nil || !strings.Contains(rows[0].err.Error(), "authoritative") {
+		t.Errorf("rows = %+v, want an invalid authoritative error", rows)
+	}
+}
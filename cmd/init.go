@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
 	"github.com/scaryPonens/ev-oracle/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -14,12 +15,27 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the database schema",
 	Long: `Initialize the database schema by creating the necessary tables and indexes.
-This command should be run once before using the query command.`,
+This command should be run once before using the query command.
+
+Unless --detect-dimension=false is passed, init also makes one test embedding
+call with the configured provider and resizes the embedding column to match
+the model's dimension. This only works while no embeddings are stored.
+
+With --ensure-schema, the schema is created with idempotent CREATE ... IF NOT
+EXISTS statements instead of golang-migrate, for deployments that manage
+migrations with their own tooling.`,
 	RunE: runInit,
 }
 
+var (
+	detectDimension bool
+	ensureSchema    bool
+)
+
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVar(&ensureSchema, "ensure-schema", false, "Create missing schema objects directly instead of running migrations")
+	initCmd.Flags().BoolVar(&detectDimension, "detect-dimension", true, "Size the embedding column to the configured embedding model's dimension")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -32,19 +48,99 @@ func runInit(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Initialize database client
-	dbClient, err := db.New(ctx, cfg.DatabaseURL)
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer dbClient.Close()
 
 	// Initialize schema
-	if err := dbClient.InitSchema(ctx); err != nil {
+	if ensureSchema {
+		err = dbClient.EnsureSchema(ctx)
+	} else {
+		err = dbClient.InitSchema(ctx)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
+	if detectDimension {
+		if err := syncEmbeddingDimension(ctx, cfg, dbClient); err != nil {
+			return err
+		}
+	} else if len(cfg.EmbeddingRace) > 1 || len(cfg.EmbeddingChain) > 1 {
+		if err := checkEmbeddingDimension(ctx, cfg, dbClient); err != nil {
+			return err
+		}
+	}
+
+	if err := recordDocumentTemplate(ctx, cfg, dbClient); err != nil {
+		return err
+	}
+
 	fmt.Println("Database schema initialized successfully!")
 	fmt.Println("You can now use 'ev-oracle' to query EV specifications.")
 
 	return nil
 }
+
+// syncEmbeddingDimension probes the configured embedding model, or every raced
+// or chained one, and resizes the embedding column if its declared dimension
+// differs. Providers that disagree on the dimension are refused.
+func syncEmbeddingDimension(ctx context.Context, cfg *models.Config, dbClient *db.Client) error {
+	embeddingSvc := newEmbeddingService(cfg)
+
+	detected, err := embeddingSvc.CheckDimensions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to detect embedding dimension: %w", err)
+	}
+
+	current, err := dbClient.EmbeddingDimension(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current == detected {
+		fmt.Printf("Embedding column already matches the %s model (%d dimensions)\n", cfg.EmbeddingProvider, detected)
+		return nil
+	}
+
+	if err := dbClient.SetEmbeddingDimension(ctx, detected); err != nil {
+		return fmt.Errorf("embedding model produces %d dimensions but the column has %d: %w", detected, current, err)
+	}
+	fmt.Printf("Resized embedding column from %d to %d dimensions for the %s model\n", current, detected, cfg.EmbeddingProvider)
+
+	return nil
+}
+
+// recordDocumentTemplate marks an empty table with the configured embedding
+// document template, so commands do not warn about embeddings stored from
+// now on. A table with rows keeps its record until reembed refreshes it.
+func recordDocumentTemplate(ctx context.Context, cfg *models.Config, dbClient *db.Client) error {
+	count, err := dbClient.CountSpecs(ctx, db.SpecFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to count specs: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := dbClient.SetSetting(ctx, db.SettingDocumentTemplate, newEmbeddingService(cfg).DocumentTemplateID()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkEmbeddingDimension probes every raced or chained embedding provider and
+// refuses them unless they all match the embedding column's dimension
+func checkEmbeddingDimension(ctx context.Context, cfg *models.Config, dbClient *db.Client) error {
+	current, err := dbClient.EmbeddingDimension(ctx)
+	if err != nil {
+		return err
+	}
+
+	embeddingSvc := newEmbeddingService(cfg, embedding.WithDimension(current))
+	if _, err := embeddingSvc.CheckDimensions(ctx); err != nil {
+		return fmt.Errorf("embedding providers do not fit the %d-dimension embedding column: %w", current, err)
+	}
+	return nil
+}
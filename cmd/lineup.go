@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// runLineup prints every stored model of a make for one year. Models that
+// are not stored are not guessed at; only those named with --models are
+// resolved, with the usual LLM fallback.
+func runLineup(cmd *cobra.Command, args []string) error {
+	make := args[0]
+	year, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid year: %s", args[1])
+	}
+
+	if err := models.ValidateFilter("make", make); err != nil {
+		return err
+	}
+	var requested []string
+	for _, model := range lineupModels {
+		if model = strings.TrimSpace(model); model == "" {
+			continue
+		}
+		if err := models.ValidateVehicle(make, model); err != nil {
+			return err
+		}
+		requested = append(requested, model)
+	}
+
+	// A lineup is several specs, so it defaults to a table and cannot use env
+	rootOutput.single = false
+	if jsonOutput {
+		rootOutput.format = string(format.JSON)
+	} else if !cmd.Flags().Changed("format") {
+		rootOutput.format = string(format.Table)
+	}
+	if err := rootOutput.validate(); err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	rootOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	dbClient, ok := res.Store().(*db.Client)
+	if !ok {
+		return fmt.Errorf("lineup requires the postgres store backend")
+	}
+
+	specs, err := dbClient.GetByMakeYear(ctx, make, year)
+	if err != nil {
+		return fmt.Errorf("failed to get lineup: %w", err)
+	}
+
+	for _, model := range requested {
+		if hasModel(specs, model) {
+			continue
+		}
+		spec, err := res.Resolve(rootOutput.track(ctx), make, model, year)
+		if err != nil {
+			return err
+		}
+		// A similarity match may answer with another make or year, which
+		// does not belong in the lineup, or with a model already in it
+		if !strings.EqualFold(spec.Make, make) || spec.Year != year {
+			fmt.Fprintf(os.Stderr, "Warning: could not resolve %d %s %s (the closest match is %d %s %s); leaving it out\n", year, make, model, spec.Year, spec.Make, spec.Model)
+			continue
+		}
+		if !hasModel(specs, spec.Model) {
+			specs = append(specs, *spec)
+		}
+	}
+	sort.SliceStable(specs, func(i, j int) bool {
+		return strings.ToLower(specs[i].Model) < strings.ToLower(specs[j].Model)
+	})
+
+	if len(specs) == 0 {
+		return fmt.Errorf("no stored %d %s models; name models with --models to resolve them", year, make)
+	}
+
+	return rootOutput.writeSpecs(specs)
+}
+
+// hasModel reports whether specs contains the given model, case-insensitively
+func hasModel(specs []models.EVSpec, model string) bool {
+	for _, spec := range specs {
+		if strings.EqualFold(spec.Model, model) {
+			return true
+		}
+	}
+	return false
+}
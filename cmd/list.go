@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listMake      string
+	listChemistry string
+	listSource    string
+	listBodyStyle string
+	listTags      []string
+	listAnyTag    bool
+	listSort      string
+	listOutput    outputOptions
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List EV specifications stored in the database",
+	Long: `List the EV specifications stored in the database, optionally filtered.
+
+Multiple --tag flags are combined with AND by default: a spec must carry every
+listed tag. Pass --any-tag to combine them with OR instead, matching specs that
+carry at least one of the listed tags.
+
+Specs are ordered by make, model and year. --sort orders them by make,
+model, year, capacity or power instead, ascending or with :desc descending.
+
+Examples:
+  ev-oracle list
+  ev-oracle list --tag verified
+  ev-oracle list --tag verified --tag 2024-refresh
+  ev-oracle list --tag community --tag verified --any-tag
+  ev-oracle list --body-style SUV
+  ev-oracle list --make Tesla --sort capacity:desc
+  ev-oracle list --make Tesla --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listMake, "make", "", "Only list specs for this make")
+	listCmd.Flags().StringVar(&listChemistry, "chemistry", "", "Only list specs with this battery chemistry")
+	listCmd.Flags().StringVar(&listSource, "source", "", "Only list specs with this source (database or llm)")
+	listCmd.Flags().StringVar(&listBodyStyle, "body-style", "", "Only list specs with this body style, e.g. SUV or Sedan")
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil, "Only list specs with this tag (repeatable)")
+	listCmd.Flags().BoolVar(&listAnyTag, "any-tag", false, "Match specs with any of the given tags instead of all of them")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort by make, model, year, capacity or power, with :desc for descending (default: make, model, year)")
+	addOutputFlags(listCmd, &listOutput)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	if err := listOutput.validate(); err != nil {
+		return err
+	}
+	bodyStyle, err := models.NormalizeBodyStyle(listBodyStyle)
+	if err != nil {
+		return fmt.Errorf("invalid --body-style: %w", err)
+	}
+	sort, err := db.ParseSort(listSort)
+	if err != nil {
+		return fmt.Errorf("invalid --sort: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	listOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	specs, err := dbClient.ListSpecs(ctx, db.SpecFilter{
+		Make:        listMake,
+		Chemistry:   listChemistry,
+		Source:      listSource,
+		BodyStyle:   bodyStyle,
+		Tags:        listTags,
+		MatchAnyTag: listAnyTag,
+	}, db.WithSort(sort))
+	if err != nil {
+		return fmt.Errorf("failed to list specs: %w", err)
+	}
+
+	if len(specs) == 0 && listOutput.isText() {
+		fmt.Println("No specs found.")
+		return nil
+	}
+
+	return listOutput.writeSpecs(specs)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/scaryPonens/ev-oracle/internal/db"
 	"github.com/scaryPonens/ev-oracle/internal/models"
@@ -10,35 +11,48 @@ import (
 )
 
 var (
-	migrateSteps int
+	migrateSteps  int
+	migrateDryRun bool
 )
 
 // migrateCmd represents the migrate command
 var migrateCmd = &cobra.Command{
-	Use:   "migrate [direction]",
+	Use:   "migrate [direction] [version]",
 	Short: "Run database migrations",
 	Long: `Run database migrations to update the database schema.
 
 Direction can be:
-  up   - Run all pending migrations (default)
-  down - Roll back the last migration
+  up    - Run all pending migrations (default)
+  down  - Roll back the last migration
+  force - Record the given version as applied and clear the dirty flag,
+          without running any migration (pass -- -1 for none)
+
+A migration that fails midway leaves the database "dirty", and every later
+migration is refused until it is forced. Inspect the schema first, then force
+the failed version if its changes took effect, or the previous version if
+they did not.
 
 Alternatively, use the --steps flag to run a specific number of migrations:
   --steps N  - Run N migrations forward (positive number)
   --steps -N - Roll back N migrations (negative number)
 
+With --dry-run the migrations that would run are listed and none is applied.
+
 Examples:
   ev-oracle migrate up
+  ev-oracle migrate down --dry-run
   ev-oracle migrate down
   ev-oracle migrate --steps 2
-  ev-oracle migrate --steps -1`,
-	Args: cobra.MaximumNArgs(1),
+  ev-oracle migrate --steps -1
+  ev-oracle migrate force 9`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: runMigrate,
 }
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 	migrateCmd.Flags().IntVar(&migrateSteps, "steps", 0, "Number of migration steps to run (positive for up, negative for down)")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "List the migrations that would run without applying them")
 }
 
 func runMigrate(cmd *cobra.Command, args []string) error {
@@ -51,12 +65,38 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Initialize database client
-	dbClient, err := db.New(ctx, cfg.DatabaseURL)
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer dbClient.Close()
 
+	if len(args) > 0 && args[0] == "force" {
+		if len(args) != 2 {
+			return fmt.Errorf("migrate force requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if migrateDryRun {
+			fmt.Printf("Dry run: would force migration version %d; nothing was changed.\n", version)
+			return nil
+		}
+		if err := dbClient.MigrateForce(ctx, version); err != nil {
+			return fmt.Errorf("failed to force migration version: %w", err)
+		}
+		fmt.Printf("Forced migration version %d\n", version)
+		return nil
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument: %s", args[1])
+	}
+
+	if migrateDryRun {
+		return planMigrations(ctx, dbClient, args)
+	}
+
 	// If steps flag is set, use it (takes precedence)
 	if migrateSteps != 0 {
 		if err := dbClient.MigrateSteps(ctx, migrateSteps); err != nil {
@@ -84,9 +124,37 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Println("Migration rolled back successfully!")
 	default:
-		return fmt.Errorf("invalid direction: %s. Use 'up' or 'down'", direction)
+		return fmt.Errorf("invalid direction: %s. Use 'up', 'down' or 'force'", direction)
 	}
 
 	return nil
 }
 
+// planMigrations lists the migrations runMigrate would run for args
+func planMigrations(ctx context.Context, dbClient *db.Client, args []string) error {
+	steps := migrateSteps
+	if steps == 0 && len(args) > 0 {
+		switch args[0] {
+		case "up":
+		case "down":
+			steps = -1
+		default:
+			return fmt.Errorf("invalid direction: %s. Use 'up', 'down' or 'force'", args[0])
+		}
+	}
+
+	plan, err := dbClient.PlanMigrations(ctx, steps)
+	if err != nil {
+		return fmt.Errorf("failed to plan migrations: %w", err)
+	}
+	for _, m := range plan {
+		fmt.Printf("  %-4s %06d %s\n", m.Direction, m.Version, m.Name)
+	}
+
+	action := "apply"
+	if steps < 0 {
+		action = "roll back"
+	}
+	printDryRun(action, len(plan), "migration")
+	return nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// noColor disables ANSI colors in table output
+var noColor bool
+
+// colorEnabled reports whether table output should use ANSI colors. Colors are
+// off when --no-color or NO_COLOR is set, or when stdout is not a terminal.
+func colorEnabled() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// outputOptions holds the output flags shared by commands that print specs
+type outputOptions struct {
+	format   string
+	template string
+	verbose  bool
+	compact  bool
+	single   bool // the command prints one spec, so --format env is allowed
+
+	confidence string                 // --confidence-display, or CONFIDENCE_DISPLAY once configured
+	bands      models.ConfidenceBands // CONFIDENCE_BANDS, set by useConfig
+
+	meta  bool           // --meta: wrap JSON output in a provenance envelope
+	usage *providerUsage // providers that served the results, set by useConfig with --meta
+}
+
+// addOutputFlags registers the --format, --template, --compact,
+// --confidence-display and --meta flags on cmd
+func addOutputFlags(cmd *cobra.Command, opts *outputOptions) {
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format: text, json, csv, table, markdown or env (single-spec commands only)")
+	cmd.Flags().StringVar(&opts.template, "template", "", "Render each result with a Go text/template, e.g. '{{.Make}} {{.Model}}: {{.Capacity}} kWh'")
+	cmd.Flags().BoolVar(&opts.compact, "compact", false, "Write JSON on a single line instead of pretty-printing it")
+	cmd.Flags().StringVar(&opts.confidence, "confidence-display", "", "Show confidence as a score, band (high/medium/low) or both (default: CONFIDENCE_DISPLAY or score)")
+	cmd.Flags().BoolVar(&opts.meta, "meta", false, "Wrap JSON output in an envelope with the generation time, tool version and the providers that served the results")
+}
+
+// useConfig fills in the confidence bands, the confidence display unless
+// --confidence-display was given, and with --meta starts recording the
+// providers that serve the results
+func (o *outputOptions) useConfig(cfg *models.Config) {
+	o.bands = cfg.ConfidenceBands
+	if o.confidence == "" {
+		o.confidence = cfg.ConfidenceDisplay
+	}
+	if o.meta {
+		o.usage = &providerUsage{}
+	}
+}
+
+// track returns ctx recording the embedding and LLM providers called under it
+// for the --meta envelope; without --meta it returns ctx unchanged
+func (o *outputOptions) track(ctx context.Context) context.Context {
+	if o.usage == nil {
+		return ctx
+	}
+	ctx = embedding.WithServedBy(ctx, func(provider embedding.ProviderType, model string) {
+		o.usage.add(format.Provider{Kind: "embedding", Provider: string(provider), Model: model})
+	})
+	return llm.WithServedBy(ctx, func(provider llm.ProviderType, model string) {
+		o.usage.add(format.Provider{Kind: "llm", Provider: string(provider), Model: model})
+	})
+}
+
+// providerUsage collects the distinct providers that served a command's
+// results, in the order they were first used
+type providerUsage struct {
+	mu        sync.Mutex
+	providers []format.Provider
+}
+
+// add records provider unless it was already recorded
+func (u *providerUsage) add(provider format.Provider) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !slices.Contains(u.providers, provider) {
+		u.providers = append(u.providers, provider)
+	}
+}
+
+// list returns the recorded providers, empty rather than nil when none was
+// called so the envelope always has a providers array
+func (u *providerUsage) list() []format.Provider {
+	if u == nil {
+		return []format.Provider{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]format.Provider{}, u.providers...)
+}
+
+// banded reports whether confidence bands are shown
+func (o *outputOptions) banded() bool {
+	return o.confidence != "" && o.confidence != string(format.ConfidenceScore)
+}
+
+// validate checks the flags up front so bad values fail before any lookups
+func (o *outputOptions) validate() error {
+	if err := o.validateConfidence(); err != nil {
+		return err
+	}
+	if o.compact && (o.template != "" || o.format != string(format.JSON)) {
+		return fmt.Errorf("--compact requires --format json")
+	}
+	if o.meta && (o.template != "" || o.format != string(format.JSON)) {
+		return fmt.Errorf("--meta requires --format json")
+	}
+	if o.template != "" {
+		_, err := format.ParseTemplate(o.template)
+		return err
+	}
+	if o.format == string(format.Env) && !o.single {
+		return fmt.Errorf("--format env prints a single spec and cannot be used with this command")
+	}
+	_, err := format.Parse(o.format)
+	return err
+}
+
+// validateConfidence checks and normalizes --confidence-display
+func (o *outputOptions) validateConfidence() error {
+	if o.confidence == "" {
+		return nil
+	}
+	display, err := format.ParseConfidenceDisplay(o.confidence)
+	if err != nil {
+		return fmt.Errorf("invalid --confidence-display: %w", err)
+	}
+	o.confidence = string(display)
+	return nil
+}
+
+// isText reports whether output is the default human-readable format
+func (o *outputOptions) isText() bool {
+	return o.template == "" && o.format == string(format.Text)
+}
+
+// writeSpecs prints a list of specs
+func (o *outputOptions) writeSpecs(specs []models.EVSpec) error {
+	if o.template != "" {
+		tmpl, err := format.ParseTemplate(o.template)
+		if err != nil {
+			return err
+		}
+		return format.WriteTemplate(os.Stdout, tmpl, specs)
+	}
+
+	f, err := format.Parse(o.format)
+	if err != nil {
+		return err
+	}
+	return format.Write(os.Stdout, f, specs, o.formatOptions()...)
+}
+
+// writeSpec prints a single spec
+func (o *outputOptions) writeSpec(spec *models.EVSpec) error {
+	if o.template != "" {
+		return o.writeSpecs([]models.EVSpec{*spec})
+	}
+
+	f, err := format.Parse(o.format)
+	if err != nil {
+		return err
+	}
+	return format.WriteSpec(os.Stdout, f, spec, o.formatOptions()...)
+}
+
+// formatOptions returns the format options implied by the flags
+func (o *outputOptions) formatOptions() []format.Option {
+	opts := []format.Option{
+		format.WithColor(colorEnabled()),
+		format.WithVerbose(o.verbose),
+		format.WithCompact(o.compact),
+	}
+	// Bands are always passed, since JSON and env carry them at any display
+	display := format.ConfidenceScore
+	if o.banded() {
+		display = format.ConfidenceDisplay(o.confidence)
+	}
+	opts = append(opts, format.WithConfidenceBands(display, o.bands))
+	if o.meta {
+		opts = append(opts, format.WithMeta(format.Meta{
+			GeneratedAt: time.Now().UTC(),
+			Version:     version.Get(),
+			Providers:   o.usage.list(),
+		}))
+	}
+	return opts
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// newTrackedResolver returns a resolver over a json store holding specs,
+// with a fake Ollama server for embeddings and LLM answers
+func newTrackedResolver(t *testing.T, specs ...models.EVSpec) *resolver.Resolver {
+	t.Helper()
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch r.URL.Path {
+		case "/api/embed":
+			fmt.Fprint(w, `{"embeddings": [[0.1, 0.2, 0.3]]}`)
+		case "/api/generate":
+			fmt.Fprint(w, `{"response": "Capacity: 82 kWh\nPower: 340 kW\nChemistry: NMC"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ollama.Close)
+	return resolver.New(openTestStore(t, specs...),
+		embedding.NewWithProvider(embedding.ProviderOllama, "", ollama.URL, "nomic-embed-text"),
+		llm.NewWithProvider(llm.ProviderOllama, "", ollama.URL, "gemma3"))
+}
+
+func TestMetaListsOnlyProvidersThatServedResults(t *testing.T) {
+	ctx := context.Background()
+	res := newTrackedResolver(t, models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Capacity: 75, Power: 283, Chemistry: "NMC"})
+
+	exact := &outputOptions{meta: true}
+	exact.useConfig(&models.Config{})
+	if _, err := res.Resolve(exact.track(ctx), "Tesla", "Model 3", 2023); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if got := exact.usage.list(); got == nil || len(got) != 0 {
+		t.Errorf("providers for an exact database hit = %#v, want an empty list", got)
+	}
+
+	// Nothing is stored, so the vector stage finds no match and the LLM answers
+	fallback := &outputOptions{meta: true}
+	fallback.useConfig(&models.Config{})
+	if _, err := newTrackedResolver(t).Resolve(fallback.track(ctx), "Rivian", "R1T", 2023); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	got := fallback.usage.list()
+	for _, want := range []format.Provider{
+		{Kind: "embedding", Provider: "ollama", Model: "nomic-embed-text"},
+		{Kind: "llm", Provider: "ollama", Model: "gemma3"},
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("providers for an LLM answer = %v, want %v among them", got, want)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("providers = %v, want each provider listed once", got)
+	}
+}
+
+func TestMetaWithoutTrackingHasEmptyProviders(t *testing.T) {
+	var o outputOptions
+	if got := o.usage.list(); got == nil || len(got) != 0 {
+		t.Errorf("providers without --meta = %#v, want an empty list", got)
+	}
+	if ctx := context.Background(); o.track(ctx) != ctx {
+		t.Error("track wrapped the context without --meta")
+	}
+}
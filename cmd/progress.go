@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showProgress bool
+	quiet        bool
+)
+
+// addProgressFlags adds --progress and --quiet to a long-running batch command
+func addProgressFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&showProgress, "progress", true, "Show a progress bar with rate and ETA on stderr when stdout is a terminal")
+	cmd.Flags().BoolVar(&quiet, "quiet", false, "Print only errors: no progress bar or summary")
+}
+
+// progressEnabled reports whether a progress bar should be drawn: not with
+// --progress=false or --quiet, and only when stdout and stderr are terminals
+func progressEnabled() bool {
+	return showProgress && !quiet && isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+// newProgressBar returns a progress bar on stderr for total items, hidden
+// unless progressEnabled. Row errors should be written to the bar so they
+// don't collide with it.
+func newProgressBar(label string, total int) *progress.Bar {
+	if !progressEnabled() {
+		return progress.Hidden(os.Stderr)
+	}
+	return progress.New(os.Stderr, label, total)
+}
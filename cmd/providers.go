@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	providersJSON bool
+)
+
+// providerProbeTimeout bounds each provider probe
+const providerProbeTimeout = 30 * time.Second
+
+// providersCmd represents the providers command
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Show the configured embedding and LLM providers and probe each one",
+	Long: `Print the exact model and endpoint of every configured embedding and LLM
+provider, then send each a tiny probe request and report whether it worked,
+how long it took and, for embedding providers, the dimension it returned.
+Every raced or chained embedding provider (EMBEDDING_RACE, EMBEDDING_CHAIN)
+and every chained LLM provider (LLM_CHAIN) is probed on its own.
+
+The database is not contacted. The command fails if any probe fails.
+
+Examples:
+  ev-oracle providers
+  ev-oracle providers --json`,
+	Args: cobra.NoArgs,
+	RunE: runProviders,
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+	providersCmd.Flags().BoolVar(&providersJSON, "json", false, "Output result in JSON format")
+}
+
+// providerStatus is the probe result for one provider
+type providerStatus struct {
+	Kind      string `json:"kind"` // "embedding" or "llm"
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	URL       string `json:"url"`
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Dimension int    `json:"dimension,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func runProviders(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize embedding service
+	embeddingSvc := newEmbeddingService(cfg)
+
+	// Initialize LLM service
+	llmSvc := newLLMService(cfg)
+
+	var statuses []providerStatus
+	for _, provider := range embeddingSvc.Providers() {
+		status := providerStatus{
+			Kind:     "embedding",
+			Provider: string(provider),
+			Model:    embeddingSvc.Model(provider),
+			URL:      embeddingSvc.URL(provider),
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+		start := time.Now()
+		dimension, err := embeddingSvc.Probe(probeCtx, provider)
+		cancel()
+		status.LatencyMS = time.Since(start).Milliseconds()
+		status.Dimension = dimension
+		status.setResult(err)
+		statuses = append(statuses, status)
+	}
+
+	for _, provider := range llmSvc.Providers() {
+		providerSvc := llmSvc.UsingProvider(provider)
+		status := providerStatus{
+			Kind:     "llm",
+			Provider: string(provider),
+			Model:    providerSvc.Model(),
+			URL:      providerSvc.URL(),
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+		start := time.Now()
+		err := providerSvc.Probe(probeCtx)
+		cancel()
+		status.LatencyMS = time.Since(start).Milliseconds()
+		status.setResult(err)
+		statuses = append(statuses, status)
+	}
+
+	if providersJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(statuses); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	} else {
+		writeProviderStatuses(statuses)
+	}
+
+	failed := 0
+	for _, s := range statuses {
+		if !s.OK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d providers failed", failed, len(statuses))
+	}
+	return nil
+}
+
+// setResult records the probe outcome
+func (s *providerStatus) setResult(err error) {
+	s.OK = err == nil
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
+
+// writeProviderStatuses prints one aligned line per provider
+func writeProviderStatuses(statuses []providerStatus) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tPROVIDER\tMODEL\tURL\tRESULT")
+	for _, s := range statuses {
+		result := fmt.Sprintf("ok (%d ms)", s.LatencyMS)
+		if s.Dimension > 0 {
+			result = fmt.Sprintf("ok (%d ms, %d dimensions)", s.LatencyMS, s.Dimension)
+		}
+		if !s.OK {
+			result = "failed: " + s.Error
+		}
+		model := s.Model
+		if model == "" {
+			model = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Kind, s.Provider, model, s.URL, result)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reembedColumn      string
+	reembedMissing     bool
+	reembedMake        string
+	reembedResume      bool
+	reembedConcurrency int
+	reembedRetries     int
+)
+
+// reembedRetryDelay is the wait before the first retry of a failed row,
+// doubled for each later one
+const reembedRetryDelay = time.Second
+
+// reembedCmd represents the reembed command
+var reembedCmd = &cobra.Command{
+	Use:   "reembed",
+	Short: "Regenerate the stored embeddings of every spec",
+	Long: `Embed the document text of every stored spec again and overwrite one
+embedding column, leaving the spec values and revision history untouched.
+
+--column primary (the default) rewrites the embedding used by lookups with
+the configured EMBEDDING_PROVIDER, e.g. after adding notes directly in SQL.
+--column alt fills the secondary embedding_alt column with
+EMBEDDING_ALT_PROVIDER instead, so two embedding models can be compared on
+the same rows with search --column alt.
+
+Rows are embedded by --concurrency workers. A row that fails is retried
+--retries times with backoff; if it still fails it is reported on stderr and
+the rest are still embedded.
+
+Every re-embedded row is recorded in reembed-COLUMN.progress in the current
+directory as soon as it is stored. If a run is interrupted (including with
+Ctrl-C) or some rows fail, re-run it with --resume to skip the rows already
+done instead of paying to embed them again; the number of rows remaining is
+printed at the end. The progress file is removed once every row succeeds;
+until then, a run without --resume refuses to start (delete the file to
+start over).
+
+Examples:
+  ev-oracle reembed
+  ev-oracle reembed --resume
+  EMBEDDING_ALT_PROVIDER=ollama EMBEDDING_ALT_MODEL=mxbai-embed-large ev-oracle reembed --column alt
+  ev-oracle reembed --column alt --missing`,
+	Args: cobra.NoArgs,
+	RunE: runReembed,
+}
+
+func init() {
+	rootCmd.AddCommand(reembedCmd)
+	reembedCmd.Flags().StringVar(&reembedColumn, "column", "primary", "Embedding column to rewrite: primary or alt")
+	reembedCmd.Flags().BoolVar(&reembedMissing, "missing", false, "Only embed rows that have no embedding in the column yet")
+	reembedCmd.Flags().StringVar(&reembedMake, "make", "", "Only embed specs for this make")
+	reembedCmd.Flags().BoolVar(&reembedResume, "resume", false, "Skip rows re-embedded by a previous interrupted or failed run")
+	reembedCmd.Flags().IntVar(&reembedConcurrency, "concurrency", 4, "Number of rows to embed in parallel")
+	reembedCmd.Flags().IntVar(&reembedRetries, "retries", 2, "Times to retry a row that fails before reporting it")
+	addProgressFlags(reembedCmd)
+}
+
+func runReembed(cmd *cobra.Command, args []string) error {
+	column, err := db.ParseEmbeddingColumn(reembedColumn)
+	if err != nil {
+		return err
+	}
+	if reembedConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", reembedConcurrency)
+	}
+	if reembedRetries < 0 {
+		return fmt.Errorf("--retries must not be negative, got %d", reembedRetries)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Stop starting rows on Ctrl-C; rows already stored stay recorded
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	// Initialize embedding service
+	var embeddingSvc *embedding.Service
+	if column == db.ColumnAlt {
+		if embeddingSvc, err = newAltEmbeddingService(cfg, embeddingCache(dbClient)); err != nil {
+			return err
+		}
+	} else {
+		embeddingSvc = newEmbeddingService(cfg, embeddingCache(dbClient))
+	}
+
+	// Only a complete primary run rebuilds every stored document text
+	fullRun := column == db.ColumnPrimary && !reembedMissing && reembedMake == ""
+	if column == db.ColumnPrimary && !fullRun {
+		warnDocumentTemplate(ctx, dbClient, embeddingSvc)
+	}
+
+	filter := db.SpecFilter{Make: reembedMake}
+	if reembedMissing {
+		filter.MissingEmbedding = column
+	}
+	specs, err := dbClient.ListSpecs(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list specs: %w", err)
+	}
+
+	progress, err := openCheckpoint(fmt.Sprintf("reembed-%s.progress", column), reembedResume)
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+
+	var pending []models.EVSpec
+	for _, spec := range specs {
+		if !progress.Done(models.MatchKey(spec.Make, spec.Model, spec.Year)) {
+			pending = append(pending, spec)
+		}
+	}
+	resumed := len(specs) - len(pending)
+
+	bar := newProgressBar("Embedding", len(pending))
+	defer bar.Finish()
+
+	work := make(chan models.EVSpec)
+	var mu sync.Mutex
+	var embedded, failed int
+	var markErr error
+	var wg sync.WaitGroup
+	for range reembedConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for spec := range work {
+				err := reembedSpec(ctx, dbClient, embeddingSvc, column, &spec)
+				var recordErr error
+				if err == nil {
+					recordErr = progress.Mark(models.MatchKey(spec.Make, spec.Model, spec.Year))
+				}
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					embedded++
+				case ctx.Err() == nil:
+					// Rows cut off by an interrupt are left for --resume, not reported
+					fmt.Fprintf(bar, "%d %s %s: %v\n", spec.Year, spec.Make, spec.Model, err)
+					failed++
+				}
+				if recordErr != nil {
+					markErr = recordErr
+				}
+				mu.Unlock()
+				bar.Add(1)
+			}
+		}()
+	}
+feed:
+	for _, spec := range pending {
+		select {
+		case work <- spec:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	bar.Finish()
+
+	remaining := len(pending) - embedded
+	if !quiet {
+		fmt.Printf("Re-embedded %d of %d spec(s) into %s (%d failed", embedded, len(specs), column, failed)
+		if resumed > 0 {
+			fmt.Printf(", %d done by a previous run", resumed)
+		}
+		fmt.Println(")")
+		if remaining > 0 {
+			fmt.Printf("%d spec(s) remain; re-run with --resume to continue\n", remaining)
+		}
+	}
+	if markErr != nil {
+		return markErr
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted with %d spec(s) remaining", remaining)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d spec(s) failed to re-embed", failed)
+	}
+	if err := progress.Remove(); err != nil {
+		return err
+	}
+	if fullRun {
+		if err := dbClient.SetSetting(ctx, db.SettingDocumentTemplate, embeddingSvc.DocumentTemplateID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reembedSpec embeds the document text of spec and stores it in column,
+// retrying up to --retries times with backoff
+func reembedSpec(ctx context.Context, dbClient *db.Client, embeddingSvc *embedding.Service, column db.EmbeddingColumn, spec *models.EVSpec) error {
+	documentText, err := embeddingSvc.DocumentText(spec.Make, spec.Model, spec.Year, spec.BodyStyle, spec.Notes)
+	if err != nil {
+		return err
+	}
+
+	delay := reembedRetryDelay
+	for attempt := 0; ; attempt++ {
+		var embeddingVector []float32
+		embeddingVector, err = embeddingSvc.GetEmbedding(ctx, documentText)
+		if err == nil {
+			err = dbClient.UpdateEmbedding(ctx, column, spec, embeddingVector)
+		}
+		// A dimension mismatch fails the same way every time
+		if err == nil || attempt == reembedRetries || errors.Is(err, db.ErrDimensionMismatch) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	refreshMaxCalls      int
+	refreshMinConfidence float64
+)
+
+// refreshCmd represents the refresh-llm-rows command
+var refreshCmd = &cobra.Command{
+	Use:   "refresh-llm-rows",
+	Short: "Re-query the LLM for stored LLM answers that are incomplete or low-confidence",
+	Long: `Re-query the LLM for stored specs with source "llm" (saved with
+SAVE_LLM_RESULTS=true or warm --save) that are missing a capacity, power or
+chemistry, or whose confidence is below --min-confidence (default: the
+medium CONFIDENCE_BANDS threshold). An answer more confident than the stored
+row replaces its values; otherwise only the missing fields are filled in and
+values a row already has are kept. Complete rows at or above
+--min-confidence are not queried, and authoritative rows are skipped.
+
+At most --max-calls LLM queries are made, so the command can run on a
+schedule with a fixed budget; incomplete rows are queried first, and rows
+over the budget are left for the next run. Set
+LLM_PROVIDER to re-check the rows with a different model than the one that
+answered them. A summary of upgraded, unchanged, failed and skipped rows is
+printed at the end.
+
+Before starting, the number of LLM calls and their tokens are estimated
+(with a cost if LLM_PRICE_PER_MTOK is set), and the run is refused without
+--yes if more than LLM_CALL_THRESHOLD calls would be made.
+
+Examples:
+  ev-oracle refresh-llm-rows
+  ev-oracle refresh-llm-rows --max-calls 20
+  ev-oracle refresh-llm-rows --max-calls 0 --yes
+  ev-oracle refresh-llm-rows --min-confidence 0
+  LLM_PROVIDER=claude ev-oracle refresh-llm-rows`,
+	Args: cobra.NoArgs,
+	RunE: runRefresh,
+}
+
+func init() {
+	rootCmd.AddCommand(refreshCmd)
+	refreshCmd.Flags().IntVar(&refreshMaxCalls, "max-calls", 100, "Maximum number of LLM queries to make (0 for no limit)")
+	refreshCmd.Flags().Float64Var(&refreshMinConfidence, "min-confidence", 0, "Re-query complete rows below this confidence (default: the medium confidence band)")
+	addCostFlags(refreshCmd)
+}
+
+func runRefresh(cmd *cobra.Command, args []string) error {
+	if refreshMaxCalls < 0 {
+		return fmt.Errorf("--max-calls must not be negative")
+	}
+	if refreshMinConfidence < 0 || refreshMinConfidence > 1 {
+		return fmt.Errorf("--min-confidence must be between 0 and 1")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Open the spec store and create the embedding and LLM services
+	calls := &callCounter{}
+	res, err := newResolver(ctx, cfg,
+		resolver.WithEmbeddingOptions(embedding.WithMetrics(calls)),
+		resolver.WithLLMOptions(llm.WithMetrics(calls)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	minConfidence := cfg.ConfidenceBands.Medium
+	if cmd.Flags().Changed("min-confidence") {
+		minConfidence = refreshMinConfidence
+	}
+
+	queries, err := res.RefreshQueries(ctx, refreshMaxCalls, minConfidence)
+	if err != nil {
+		return fmt.Errorf("failed to list LLM rows: %w", err)
+	}
+	est, err := estimateCost(res.LLM(), queries, len(queries), 0)
+	if err != nil {
+		return err
+	}
+	if err := checkCost(cfg, est); err != nil {
+		return err
+	}
+
+	report, err := res.RefreshLLMRows(ctx, refreshMaxCalls, minConfidence)
+	calls.report()
+	if err != nil {
+		return fmt.Errorf("failed to refresh LLM rows: %w", err)
+	}
+
+	fmt.Printf("Upgraded:  %d\n", report.Upgraded)
+	fmt.Printf("Unchanged: %d\n", report.Unchanged)
+	fmt.Printf("Failed:    %d\n", report.Failed)
+	if report.Skipped > 0 {
+		fmt.Printf("Skipped:   %d (authoritative, or over the --max-calls budget)\n", report.Skipped)
+	}
+	return nil
+}
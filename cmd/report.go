@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportConcurrency int
+	reportJSON        bool
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report [file]",
+	Short: "Summarize the battery specifications of a fleet",
+	Long: `Resolve every make,model,year row of a CSV file (or - for stdin), in the same
+format as batch, and print an aggregate report: total fleet capacity, average
+power, chemistry distribution and how many vehicles were answered from the
+database versus the LLM.
+
+Vehicles that fail to resolve are reported on stderr and counted as failed;
+they do not contribute to the totals.
+
+Examples:
+  ev-oracle report fleet.csv
+  ev-oracle report fleet.csv --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().IntVar(&reportConcurrency, "concurrency", 4, "Number of queries to resolve in parallel")
+	reportCmd.Flags().BoolVar(&reportJSON, "json", false, "Output the report in JSON format")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	queries, lines, err := readQueriesFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	report := resolver.NewFleetReport()
+	err = res.ResolveBatch(ctx, queries, reportConcurrency, false, func(result resolver.BatchResult) error {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "line %d (%d %s %s): %v\n", lines[result.Index], result.Query.Year, result.Query.Make, result.Query.Model, result.Err)
+		}
+		report.Add(result)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if reportJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	return writeFleetReport(report)
+}
+
+// writeFleetReport prints the report as a human-readable summary
+func writeFleetReport(report *resolver.FleetReport) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Vehicles:\t%d (%d resolved, %d failed)\n", report.Vehicles, report.Resolved, report.Failed)
+	fmt.Fprintf(tw, "Total capacity:\t%.1f kWh\n", report.TotalCapacityKWh)
+	fmt.Fprintf(tw, "Average power:\t%.1f kW\n", report.AveragePowerKW)
+
+	fmt.Fprintln(tw, "\nChemistry:")
+	for _, name := range sortedByCount(report.Chemistries) {
+		fmt.Fprintf(tw, "  %s\t%d\t%.0f%%\n", name, report.Chemistries[name], percent(report.Chemistries[name], report.Resolved))
+	}
+
+	fmt.Fprintln(tw, "\nSource:")
+	for _, name := range sortedByCount(report.Sources) {
+		fmt.Fprintf(tw, "  %s\t%d\t%.0f%%\n", name, report.Sources[name], percent(report.Sources[name], report.Resolved))
+	}
+
+	return tw.Flush()
+}
+
+// sortedByCount returns the keys of counts, most frequent first and then by name
+func sortedByCount(counts map[string]int) []string {
+	return slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+}
+
+// percent returns n as a percentage of total
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
@@ -2,20 +2,32 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/scaryPonens/ev-oracle/internal/db"
-	"github.com/scaryPonens/ev-oracle/internal/embedding"
-	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/format"
 	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/scaryPonens/ev-oracle/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var (
-	jsonOutput bool
+	jsonOutput    bool
+	traceHTTP     bool
+	pullModels    bool
+	noEmbedCache  bool
+	noFillPartial bool
+	strictYears   bool
+	exactScan     bool
+	allYears      bool
+	allModels     bool
+	lineupModels  []string
+	explain       bool
+	readOnly      bool
+	rootOutput    outputOptions
 )
 
 // rootCmd represents the base command
@@ -27,9 +39,16 @@ It queries a pgvector-backed knowledge base and falls back to LLM reasoning when
 
 Example:
   ev-oracle Tesla "Model 3" 2023
-  ev-oracle --json Nissan Leaf 2022`,
-	Args: cobra.ExactArgs(3),
-	RunE: runQuery,
+  ev-oracle --json Nissan Leaf 2022
+  ev-oracle --format csv Nissan Leaf 2022
+  ev-oracle --template '{{.Make}} {{.Model}}: {{.Capacity}} kWh' Nissan Leaf 2022
+  ev-oracle --exact Tesla "Model Y" 2023
+  ev-oracle --all-years Hyundai "Ioniq 5"
+  ev-oracle --all-models Tesla 2023
+  ev-oracle --explain Kia EV9 2024`,
+	Args:    queryArgs,
+	RunE:    runQuery,
+	Version: version.Get(),
 }
 
 // Execute runs the root command
@@ -41,12 +60,51 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output result in JSON format")
+	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output result in JSON format (shorthand for --format json)")
+	rootOutput.single = true
+	addOutputFlags(rootCmd, &rootOutput)
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&noFillPartial, "no-fallback-on-partial", false, "Return database results with missing fields as is instead of filling the gaps from the LLM")
+	rootCmd.PersistentFlags().BoolVar(&strictYears, "strict", false, "Fail instead of warning when the model year precedes the vehicle's production start")
+	rootCmd.PersistentFlags().BoolVar(&pullModels, "pull", false, "Pull a missing Ollama model through the Ollama API and retry instead of failing")
+	rootCmd.PersistentFlags().BoolVar(&noEmbedCache, "no-embed-cache", false, "Always call the embedding provider instead of reusing embeddings cached in the database")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Open the database read-only: writes fail, LLM answers are not saved and embeddings are not cached")
+	rootCmd.PersistentFlags().BoolVar(&traceHTTP, "trace-http", false, "Dump embedding and LLM HTTP traffic to stderr, with API keys redacted")
+	rootCmd.Flags().BoolVar(&rootOutput.verbose, "verbose", false, "Show extra detail such as chemistry candidates in text output")
+	rootCmd.Flags().BoolVar(&allYears, "all-years", false, "List every stored year of the make and model as a timeline; a given year is resolved too if it is not stored")
+	rootCmd.Flags().BoolVar(&allModels, "all-models", false, "List every stored model of the make for the year (args: make year); only models named with --models are resolved if missing")
+	rootCmd.Flags().StringSliceVar(&lineupModels, "models", nil, "Comma-separated models to resolve with --all-models when they are not stored, e.g. \"Model 3,Cybertruck\"")
+	rootCmd.MarkFlagsMutuallyExclusive("all-years", "all-models")
+	rootCmd.Flags().BoolVar(&exactScan, "exact", false, "Force an exact nearest-neighbor scan instead of the approximate index (slower)")
+	rootCmd.Flags().BoolVar(&explain, "explain", false, "Print the resolution trace (stages tried, latencies, confidence, chosen source) on stderr")
+}
+
+// queryArgs requires make, model and year, with the year optional for
+// --all-years and no model for --all-models
+func queryArgs(cmd *cobra.Command, args []string) error {
+	if allModels {
+		return cobra.ExactArgs(2)(cmd, args)
+	}
+	if len(lineupModels) > 0 {
+		return fmt.Errorf("--models requires --all-models")
+	}
+	if allYears {
+		return cobra.RangeArgs(2, 3)(cmd, args)
+	}
+	return cobra.ExactArgs(3)(cmd, args)
 }
 
 // runQuery executes the main query logic
 func runQuery(cmd *cobra.Command, args []string) error {
-	fmt.Printf("Running query for %s %s %s\n", args[0], args[1], args[2])
+	if allYears {
+		return runTimeline(cmd, args)
+	}
+	if allModels {
+		return runLineup(cmd, args)
+	}
+
+	// Progress goes to stderr so that stdout can be parsed or eval'd
+	fmt.Fprintf(os.Stderr, "Running query for %s %s %s\n", args[0], args[1], args[2])
 	make := args[0]
 	model := args[1]
 	yearStr := args[2]
@@ -56,91 +114,86 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid year: %s", yearStr)
 	}
 
+	if jsonOutput {
+		rootOutput.format = string(format.JSON)
+	}
+	if err := rootOutput.validate(); err != nil {
+		return err
+	}
+
 	// Load configuration
 	cfg, err := models.NewConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	rootOutput.useConfig(cfg)
 
 	ctx := context.Background()
 
-	// Initialize database client
-	dbClient, err := db.New(ctx, cfg.DatabaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	var searchOpts []db.SearchOption
+	if exactScan {
+		searchOpts = append(searchOpts, db.WithExactScan())
 	}
-	defer dbClient.Close()
 
-	// Try exact match first
-	spec, err := dbClient.GetByMakeModelYear(ctx, make, model, year)
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg, resolver.WithResolverOptions(resolver.WithSearchOptions(searchOpts...)))
 	if err != nil {
-		return fmt.Errorf("database query error: %w", err)
+		return err
 	}
-
-	// If exact match found, return it
-	if spec != nil {
-		return outputSpec(spec)
+	defer res.Close()
+	warnDocumentTemplate(ctx, res.Store(), res.Embedding())
+
+	var spec *models.EVSpec
+	if explain {
+		var trace *resolver.Resolution
+		spec, trace, err = res.ResolveExplain(rootOutput.track(ctx), make, model, year)
+		trace.WriteText(os.Stderr)
+	} else {
+		spec, err = res.Resolve(rootOutput.track(ctx), make, model, year)
 	}
-
-	// Initialize embedding service
-	embeddingSvc := embedding.NewWithProvider(
-		embedding.ProviderType(cfg.EmbeddingProvider),
-		cfg.OpenAIAPIKey,
-		cfg.OllamaURL,
-		cfg.OllamaModel,
-	)
-
-	// Build query text and get embedding
-	queryText := embedding.BuildQueryText(make, model, year)
-	embeddingVector, err := embeddingSvc.GetEmbedding(queryText)
 	if err != nil {
-		return fmt.Errorf("failed to get embedding: %w", err)
+		return err
 	}
 
-	// Perform similarity search
-	results, err := dbClient.SimilaritySearch(ctx, embeddingVector, 1)
-	if err != nil {
-		return fmt.Errorf("similarity search error: %w", err)
-	}
+	return rootOutput.writeSpec(spec)
+}
 
-	// Check if we have results with sufficient confidence
-	if len(results) > 0 && results[0].Confidence >= models.ConfidenceThreshold {
-		return outputSpec(&results[0])
+// newResolver builds the resolver described by the configuration and global
+// flags with resolver.NewResolver
+func newResolver(ctx context.Context, cfg *models.Config, extra ...resolver.SetupOption) (*resolver.Resolver, error) {
+	cfg = flagConfig(cfg)
+	opts := []resolver.SetupOption{
+		resolver.WithStoreOptions(dbFlagOptions()...),
+		resolver.WithEmbeddingOptions(embeddingFlagOptions()...),
+		resolver.WithLLMOptions(llmFlagOptions()...),
+		resolver.WithResolverOptions(resolverFlagOptions(cfg)...),
 	}
-
-	fmt.Println("Falling back to LLM")
-	// Fall back to LLM
-	llmSvc := llm.NewWithProvider(
-		llm.ProviderType(cfg.LLMProvider),
-		cfg.AnthropicAPIKey,
-		cfg.OllamaURL,
-		cfg.OllamaLLMModel,
-	)
-	spec, err = llmSvc.QueryEVSpecs(make, model, year)
-	if err != nil {
-		return fmt.Errorf("LLM query error: %w", err)
+	if noEmbedCache || readOnly {
+		opts = append(opts, resolver.WithoutEmbeddingCache())
 	}
+	return resolver.NewResolver(ctx, cfg, append(opts, extra...)...)
+}
 
-	return outputSpec(spec)
+// flagConfig returns cfg adjusted for global flags: --read-only turns off
+// saving LLM answers
+func flagConfig(cfg *models.Config) *models.Config {
+	if !readOnly || !cfg.SaveLLMResults {
+		return cfg
+	}
+	adjusted := *cfg
+	adjusted.SaveLLMResults = false
+	return &adjusted
 }
 
-// outputSpec outputs the EV spec in the requested format
-func outputSpec(spec *models.EVSpec) error {
-	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(spec); err != nil {
-			return fmt.Errorf("failed to encode JSON: %w", err)
-		}
-	} else {
-		fmt.Printf("Make:       %s\n", spec.Make)
-		fmt.Printf("Model:      %s\n", spec.Model)
-		fmt.Printf("Year:       %d\n", spec.Year)
-		fmt.Printf("Capacity:   %.1f kWh\n", spec.Capacity)
-		fmt.Printf("Power:      %.1f kW\n", spec.Power)
-		fmt.Printf("Chemistry:  %s\n", spec.Chemistry)
-		fmt.Printf("Confidence: %.2f\n", spec.Confidence)
-		fmt.Printf("Source:     %s\n", spec.Source)
-	}
-	return nil
+// resolverFlagOptions returns the resolver options set by global flags, which
+// are applied after resolver.ConfigOptions
+func resolverFlagOptions(cfg *models.Config) []resolver.Option {
+	var opts []resolver.Option
+	if strictYears {
+		opts = append(opts, resolver.WithProductionYearCheck(resolver.NewProductionYears(cfg.ProductionYears...), true))
+	}
+	if noFillPartial {
+		opts = append(opts, resolver.WithPartialPolicy(resolver.PartialAccept))
+	}
+	return opts
 }
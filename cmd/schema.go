@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/jsonschema"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// outputSchema is a JSON output shape that schema can describe
+type outputSchema struct {
+	name   string
+	usedBy string
+	schema func() *jsonschema.Schema
+}
+
+// outputSchemas lists the JSON outputs, generated from the Go types that are encoded
+var outputSchemas = []outputSchema{
+	{"spec", "query and describe with --json", jsonschema.For[models.EVSpec]},
+	{"specs", "list, search, batch and --all-years with --format json", jsonschema.For[[]models.EVSpec]},
+	{"envelope", "spec commands with --format json --meta", jsonschema.For[format.Envelope]},
+	{"batch-line", "each line of batch --format ndjson", jsonschema.For[batchLine]},
+	{"capacity-matches", "find --json", jsonschema.For[[]db.CapacityMatch]},
+	{"duplicates", "find-duplicates --json", jsonschema.For[[]db.DuplicatePair]},
+	{"history", "history --json", jsonschema.For[historyOutput]},
+	{"similarity-matrix", "similarity-matrix --json", jsonschema.For[similarityMatrix]},
+}
+
+var schemaList bool
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: "Print the JSON Schema of an output format",
+	Long: `Print a JSON Schema (draft 2020-12) describing the JSON output of a command,
+for validating it or generating client types. The schemas are generated from
+the Go types at run time, so they always match the output of this build.
+Without a name, the schema of a single spec is printed; --list shows every
+available schema.
+
+Examples:
+  ev-oracle schema
+  ev-oracle schema specs > specs.schema.json
+  ev-oracle schema --list`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().BoolVar(&schemaList, "list", false, "List the available schemas")
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaList {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tUSED BY")
+		for _, s := range outputSchemas {
+			fmt.Fprintf(tw, "%s\t%s\n", s.name, s.usedBy)
+		}
+		return tw.Flush()
+	}
+
+	name := "spec"
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	var names []string
+	for _, s := range outputSchemas {
+		if s.name == name {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(s.schema()); err != nil {
+				return fmt.Errorf("failed to encode JSON: %w", err)
+			}
+			return nil
+		}
+		names = append(names, s.name)
+	}
+	return fmt.Errorf("unknown schema: %s (use %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchLimit      int
+	searchCandidates int
+	searchOutput     outputOptions
+	searchRerank     resolver.RerankOptions
+	searchColumn     string
+	searchAggregate  string
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search [make] [model] [year]",
+	Short: "List the closest matches from the vector similarity search",
+	Long: `Search the knowledge base by embedding similarity and list the closest matches.
+Unlike the main query, this never falls back to the LLM.
+
+Pure embedding similarity ignores the numeric fields. Set --target-capacity
+and/or --target-power to rerank the top --candidates matches by a weighted
+combination of embedding similarity and numeric closeness to the targets.
+Closeness falls linearly from 1 at the target to 0 at the tolerance.
+
+--column alt searches the secondary embedding column filled by
+reembed --column alt, embedding the query with EMBEDDING_ALT_PROVIDER, so the
+matches of two embedding models can be compared.
+
+--aggregate combines the listed matches, e.g. the trims of a model, into one
+synthesized spec with source "aggregate": capacity, power and confidence are
+the mean, median or max of the matches, and chemistry is the most common one.
+
+Examples:
+  ev-oracle search Tesla "Model Y" 2023
+  ev-oracle search Hyundai Ioniq 2023 --target-capacity 77 --capacity-tolerance 10
+  ev-oracle search Kia EV6 2023 --target-power 230 --power-weight 2 --limit 3
+  ev-oracle search Tesla "Model Y" 2023 --column alt
+  ev-oracle search Tesla "Model 3" 2023 --limit 3 --aggregate median`,
+	Args: cobra.ExactArgs(3),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 5, "Number of results to show")
+	searchCmd.Flags().IntVar(&searchCandidates, "candidates", 20, "Number of vector matches to rerank (at least --limit)")
+	searchCmd.Flags().StringVar(&searchColumn, "column", "primary", "Embedding column to search: primary or alt")
+	searchCmd.Flags().StringVar(&searchAggregate, "aggregate", "", "Combine the listed matches into one spec: mean, median or max")
+	addOutputFlags(searchCmd, &searchOutput)
+	searchCmd.Flags().Float64Var(&searchRerank.TargetCapacity, "target-capacity", 0, "Target battery capacity in kWh to rerank by")
+	searchCmd.Flags().Float64Var(&searchRerank.TargetPower, "target-power", 0, "Target power in kW to rerank by")
+	searchCmd.Flags().Float64Var(&searchRerank.CapacityTolerance, "capacity-tolerance", 10, "Capacity difference in kWh at which closeness drops to zero")
+	searchCmd.Flags().Float64Var(&searchRerank.PowerTolerance, "power-tolerance", 50, "Power difference in kW at which closeness drops to zero")
+	searchCmd.Flags().Float64Var(&searchRerank.VectorWeight, "vector-weight", 1, "Weight of embedding similarity in the rerank score")
+	searchCmd.Flags().Float64Var(&searchRerank.CapacityWeight, "capacity-weight", 1, "Weight of capacity closeness in the rerank score")
+	searchCmd.Flags().Float64Var(&searchRerank.PowerWeight, "power-weight", 1, "Weight of power closeness in the rerank score")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	make := args[0]
+	model := args[1]
+	yearStr := args[2]
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return fmt.Errorf("invalid year: %s", yearStr)
+	}
+
+	if err := searchOutput.validate(); err != nil {
+		return err
+	}
+
+	column, err := db.ParseEmbeddingColumn(searchColumn)
+	if err != nil {
+		return err
+	}
+
+	var aggregate resolver.AggregateMethod
+	if searchAggregate != "" {
+		if aggregate, err = resolver.ParseAggregateMethod(searchAggregate); err != nil {
+			return err
+		}
+	}
+
+	if searchLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	candidates := searchLimit
+	if searchRerank.Enabled() && searchCandidates > candidates {
+		candidates = searchCandidates
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	searchOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	setupOpts := []resolver.SetupOption{
+		resolver.WithResolverOptions(resolver.WithSearchOptions(db.WithEmbeddingColumn(column))),
+	}
+	if column == db.ColumnAlt {
+		alt, altOpts, err := altEmbeddingConfig(cfg)
+		if err != nil {
+			return err
+		}
+		setupOpts = append(setupOpts, resolver.WithEmbeddingConfig(alt), resolver.WithEmbeddingOptions(altOpts...))
+	}
+
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg, setupOpts...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	if column != db.ColumnAlt {
+		warnDocumentTemplate(ctx, res.Store(), res.Embedding())
+	}
+
+	results, err := res.Search(searchOutput.track(ctx), make, model, year, candidates)
+	if err != nil {
+		return err
+	}
+
+	results = resolver.Rerank(results, searchRerank)
+	if len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+
+	if len(results) == 0 && searchOutput.isText() {
+		fmt.Println("No matches found.")
+		return nil
+	}
+
+	if aggregate != "" {
+		spec, err := resolver.Aggregate(resolver.Query{Make: make, Model: model, Year: year}, results, aggregate)
+		if err != nil {
+			return err
+		}
+		return searchOutput.writeSpec(spec)
+	}
+
+	return searchOutput.writeSpecs(results)
+}
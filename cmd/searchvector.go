@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchVector       string
+	searchVectorFile   string
+	searchVectorLimit  int
+	searchVectorExact  bool
+	searchVectorColumn string
+	searchVectorOutput outputOptions
+)
+
+// searchVectorCmd represents the search-vector command
+var searchVectorCmd = &cobra.Command{
+	Use:   "search-vector",
+	Short: "Run a similarity search with a precomputed embedding",
+	Long: `Run the vector similarity search with an embedding supplied on the command
+line instead of generating one, and list the closest stored specs. The vector
+is a JSON array of numbers and must have as many dimensions as the embedding
+column. No embedding or LLM provider is called, or needs to be configured;
+only NEON_DATABASE_URL is required.
+
+Examples:
+  ev-oracle search-vector --vector '[0.012, -0.034, ...]'
+  ev-oracle search-vector --vector-file query.json --limit 10
+  my-embedder "2023 Tesla Model 3" | ev-oracle search-vector --vector-file -`,
+	Args: cobra.NoArgs,
+	RunE: runSearchVector,
+}
+
+func init() {
+	rootCmd.AddCommand(searchVectorCmd)
+	searchVectorCmd.Flags().StringVar(&searchVector, "vector", "", "Embedding as a JSON array of numbers")
+	searchVectorCmd.Flags().StringVar(&searchVectorFile, "vector-file", "", "Read the embedding from a file, or - for stdin")
+	searchVectorCmd.Flags().IntVar(&searchVectorLimit, "limit", 5, "Number of results to show")
+	searchVectorCmd.Flags().StringVar(&searchVectorColumn, "column", "primary", "Embedding column to search: primary or alt")
+	searchVectorCmd.Flags().BoolVar(&searchVectorExact, "exact", false, "Force an exact nearest-neighbor scan instead of the approximate index (slower)")
+	searchVectorCmd.MarkFlagsOneRequired("vector", "vector-file")
+	searchVectorCmd.MarkFlagsMutuallyExclusive("vector", "vector-file")
+	addOutputFlags(searchVectorCmd, &searchVectorOutput)
+}
+
+func runSearchVector(cmd *cobra.Command, args []string) error {
+	if err := searchVectorOutput.validate(); err != nil {
+		return err
+	}
+	if searchVectorLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	text := searchVector
+	if searchVectorFile != "" {
+		data, err := readVectorFile(searchVectorFile)
+		if err != nil {
+			return err
+		}
+		text = data
+	}
+	vector, err := parseVector(text)
+	if err != nil {
+		return err
+	}
+	column, err := db.ParseEmbeddingColumn(searchVectorColumn)
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig(models.WithDatabaseOnly())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	searchVectorOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	// The alt column has no fixed dimension to check against
+	if column == db.ColumnPrimary {
+		if err := dbClient.CheckDimension(ctx, vector); err != nil {
+			return err
+		}
+	}
+
+	opts := []db.SearchOption{db.WithEmbeddingColumn(column)}
+	if searchVectorExact {
+		opts = append(opts, db.WithExactScan())
+	}
+	results, err := dbClient.SimilaritySearch(ctx, vector, searchVectorLimit, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to search database: %w", err)
+	}
+
+	if len(results) == 0 && searchVectorOutput.isText() {
+		fmt.Println("No matches found.")
+		return nil
+	}
+
+	return searchVectorOutput.writeSpecs(results)
+}
+
+// readVectorFile reads a vector file, or stdin for "-"
+func readVectorFile(path string) (string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open vector file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vector file: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseVector parses an embedding written as a JSON array of numbers
+func parseVector(text string) ([]float32, error) {
+	var vector []float32
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &vector); err != nil {
+		return nil, fmt.Errorf("invalid vector: must be a JSON array of numbers: %w", err)
+	}
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("invalid vector: must not be empty")
+	}
+	return vector, nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/selftest"
+	"github.com/spf13/cobra"
+)
+
+// selftestCmd represents the selftest command
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check the resolver pipeline against built-in mock providers",
+	Long: `Run the resolver end to end against built-in mock embedding and LLM
+providers and a temporary spec store. Nothing is read from the environment,
+and no network or database is used, so a failure points at the build rather
+than the setup.
+
+Each branch of the pipeline is checked in turn: an exact database hit, a
+similarity (vector) hit, and the LLM fallback. A PASS or FAIL line is printed
+per stage, and the command exits non-zero if any stage fails.
+
+Example:
+  ev-oracle selftest`,
+	Args: cobra.NoArgs,
+	RunE: runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	failed, err := selftest.Run(context.Background(), func(r selftest.Result) {
+		q := r.Case.Query
+		if r.Err != nil {
+			fmt.Printf("FAIL  %-6s  %d %s %s: %v\n", r.Case.Name, q.Year, q.Make, q.Model, r.Err)
+			return
+		}
+		fmt.Printf("PASS  %-6s  %d %s %s: %d %s, %.1f kWh, source %s, confidence %.2f\n",
+			r.Case.Name, q.Year, q.Make, q.Model, r.Spec.Year, r.Spec.Model, r.Spec.Capacity, r.Spec.Source, r.Spec.Confidence)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up self-test: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d self-test stages failed", failed, len(selftest.Cases))
+	}
+	fmt.Println("All stages passed.")
+	return nil
+}
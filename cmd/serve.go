@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/metrics/prometheus"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/scaryPonens/ev-oracle/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr    string
+	serveMetrics bool
+	serveProbe   bool
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve EV specifications over HTTP",
+	Long: `Start an HTTP server exposing the same lookup pipeline as the query command.
+
+The /specs endpoint takes make, model and year query parameters. The response
+format is chosen with the Accept header (application/json, text/csv or
+text/plain) or the ?format= query parameter (json, csv or text), and defaults
+to JSON. Unsupported formats return 406 Not Acceptable.
+
+Without model and year, /specs lists the stored specs as a paginated JSON
+collection, filtered by the optional make and chemistry parameters and paged
+with limit (default 50, at most 500) and offset.
+
+With --metrics, Prometheus metrics for resolutions and provider calls are
+served at /metrics.
+
+Example:
+  ev-oracle serve --addr :8080
+  curl 'localhost:8080/specs?make=Tesla&model=Model%203&year=2023'
+  curl -H 'Accept: text/plain' 'localhost:8080/specs?make=Tesla&model=Model%203&year=2023'
+  curl 'localhost:8080/specs?make=Tesla&limit=20'`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveMetrics, "metrics", false, "Expose Prometheus metrics at /metrics")
+	serveCmd.Flags().BoolVar(&serveProbe, "probe", false, "Check the embedding and LLM providers before listening")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var recorder metrics.Recorder = metrics.Nop{}
+	var serverOpts []server.Option
+	if serveMetrics {
+		prom := prometheus.New()
+		recorder = prom
+		serverOpts = append(serverOpts, server.WithMetricsHandler(prom.Handler()))
+	}
+	serverOpts = append(serverOpts, server.WithFormatOptions(format.WithConfidenceBands(format.ConfidenceDisplay(cfg.ConfidenceDisplay), cfg.ConfidenceBands)))
+
+	setupOpts := []resolver.SetupOption{
+		resolver.WithEmbeddingOptions(embedding.WithMetrics(recorder)),
+		resolver.WithLLMOptions(llm.WithMetrics(recorder)),
+		resolver.WithResolverOptions(resolver.WithMetrics(recorder)),
+	}
+	if serveProbe {
+		setupOpts = append(setupOpts, resolver.WithProbe())
+	}
+
+	// Connect to the database and create the embedding and LLM services,
+	// failing before listening if any of them is unusable
+	res, err := newResolver(ctx, cfg, setupOpts...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	dbClient, ok := res.Store().(*db.Client)
+	if !ok {
+		return fmt.Errorf("serve requires the postgres store backend")
+	}
+	warnDocumentTemplate(ctx, dbClient, res.Embedding())
+
+	httpServer := &http.Server{
+		Addr:    serveAddr,
+		Handler: server.New(res, dbClient, serverOpts...),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+	fmt.Printf("Listening on %s\n", serveAddr)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Give in-flight requests a moment to finish before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+
+	fmt.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/httplog"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// newEmbeddingService creates the embedding service described by the
+// configuration and global flags
+func newEmbeddingService(cfg *models.Config, extra ...embedding.Option) *embedding.Service {
+	return resolver.NewEmbeddingService(cfg, append(embeddingFlagOptions(), extra...)...)
+}
+
+// embeddingFlagOptions returns the embedding options set by global flags
+func embeddingFlagOptions() []embedding.Option {
+	var opts []embedding.Option
+	if pullModels {
+		opts = append(opts, embedding.WithPullMissingModel())
+	}
+	if traceHTTP {
+		opts = append(opts, embedding.WithHTTPClient(tracingClient()))
+	}
+	return opts
+}
+
+// newAltEmbeddingService creates the embedding service for the secondary
+// embedding column
+func newAltEmbeddingService(cfg *models.Config, extra ...embedding.Option) (*embedding.Service, error) {
+	alt, opts, err := altEmbeddingConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newEmbeddingService(alt, append(opts, extra...)...), nil
+}
+
+// altEmbeddingConfig returns the configuration and options of the embedding
+// service for the secondary embedding column, from EMBEDDING_ALT_PROVIDER and
+// EMBEDDING_ALT_MODEL, which names the model of whichever provider that is
+func altEmbeddingConfig(cfg *models.Config) (*models.Config, []embedding.Option, error) {
+	if cfg.AltEmbedProvider == "" {
+		return nil, nil, fmt.Errorf("EMBEDDING_ALT_PROVIDER is required to use the alt embedding column")
+	}
+
+	alt := *cfg
+	alt.EmbeddingProvider = cfg.AltEmbedProvider
+	alt.EmbeddingRace = nil
+	alt.EmbeddingChain = nil
+	alt.LocalEmbedDim = 0
+	var opts []embedding.Option
+	if cfg.AltEmbedModel != "" {
+		alt.OllamaModel = cfg.AltEmbedModel
+		alt.LocalEmbedModel = cfg.AltEmbedModel
+		opts = append(opts, embedding.WithOpenAIModel(cfg.AltEmbedModel))
+	}
+	return &alt, opts, nil
+}
+
+// openSpecStore opens the spec store selected by STORE_BACKEND
+func openSpecStore(ctx context.Context, cfg *models.Config) (db.SpecStore, error) {
+	return resolver.OpenStore(ctx, cfg, dbFlagOptions()...)
+}
+
+// dbOptions returns the database client options derived from configuration
+// and global flags
+func dbOptions(cfg *models.Config) []db.Option {
+	return append(resolver.DBOptions(cfg), dbFlagOptions()...)
+}
+
+// dbFlagOptions returns the database client options set by global flags
+func dbFlagOptions() []db.Option {
+	var opts []db.Option
+	if readOnly {
+		opts = append(opts, db.WithReadOnly())
+	}
+	return opts
+}
+
+// embeddingCache caches embeddings in the database unless --no-embed-cache or
+// --read-only is set. The json store backend has no cache.
+func embeddingCache(store db.SpecStore) embedding.Option {
+	dbClient, ok := store.(*db.Client)
+	if noEmbedCache || readOnly || !ok {
+		return embedding.WithCache(nil)
+	}
+	return embedding.WithCache(dbClient)
+}
+
+// warnDocumentTemplate warns on stderr when the stored embeddings were built
+// from differently templated document texts than the configured ones, since
+// lookups then compare texts of two shapes until reembed refreshes them. It
+// only reads: init and reembed record the template, and an empty table never
+// warns. Postgres errors, e.g. from an unmigrated database, are ignored.
+func warnDocumentTemplate(ctx context.Context, store db.SpecStore, embeddingSvc *embedding.Service) {
+	dbClient, ok := store.(*db.Client)
+	if !ok {
+		return
+	}
+
+	stored, err := dbClient.Setting(ctx, db.SettingDocumentTemplate)
+	if err != nil {
+		return
+	}
+	// Embeddings stored before the setting existed used the default texts
+	if stored == "" {
+		stored = embedding.DefaultDocumentTemplateID()
+	}
+	current := embeddingSvc.DocumentTemplateID()
+	if stored == current {
+		return
+	}
+
+	if count, err := dbClient.CountSpecs(ctx, db.SpecFilter{}); err != nil || count == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Warning: the stored embeddings were built with a different embedding text template; run 'ev-oracle reembed' to refresh them")
+}
+
+// newLLMService creates the LLM service described by the configuration and
+// global flags
+func newLLMService(cfg *models.Config, extra ...llm.Option) *llm.Service {
+	return resolver.NewLLMService(cfg, append(llmFlagOptions(), extra...)...)
+}
+
+// llmFlagOptions returns the LLM options set by global flags
+func llmFlagOptions() []llm.Option {
+	var opts []llm.Option
+	if pullModels {
+		opts = append(opts, llm.WithPullMissingModel())
+	}
+	if traceHTTP {
+		opts = append(opts, llm.WithHTTPClient(tracingClient()))
+	}
+	return opts
+}
+
+// tracingClient returns an HTTP client that dumps provider traffic to stderr
+func tracingClient() *http.Client {
+	return &http.Client{Transport: httplog.NewTransport(http.DefaultTransport, os.Stderr)}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// maxMatrixRowsLimit bounds --max-rows: the matrix grows with the square of
+// the row count
+const maxMatrixRowsLimit = 1000
+
+var (
+	matrixMakes   []string
+	matrixYear    int
+	matrixColumn  string
+	matrixMaxRows int
+	matrixJSON    bool
+)
+
+// similarityMatrixCmd represents the similarity-matrix command
+var similarityMatrixCmd = &cobra.Command{
+	Use:   "similarity-matrix",
+	Short: "Print the pairwise cosine similarities of stored embeddings",
+	Long: `Fetch the stored embeddings of the specs matching --makes and --year and
+print the cosine similarity of every pair as a matrix, to see how the
+embedding space clusters makes and models. The similarities are computed
+locally; no embedding or LLM call is made.
+
+The matrix has one row and column per spec, numbered in the order of the
+legend above it. Since it grows with the square of the number of rows, the
+command refuses to run when more than --max-rows specs match; narrow the
+selection or raise the limit (at most 1000).
+
+Examples:
+  ev-oracle similarity-matrix --makes Tesla,Hyundai --year 2023
+  ev-oracle similarity-matrix --makes Kia --json
+  ev-oracle similarity-matrix --year 2024 --column alt --max-rows 200`,
+	Args: cobra.NoArgs,
+	RunE: runSimilarityMatrix,
+}
+
+func init() {
+	rootCmd.AddCommand(similarityMatrixCmd)
+	similarityMatrixCmd.Flags().StringSliceVar(&matrixMakes, "makes", nil, "Only include specs of these makes, comma-separated")
+	similarityMatrixCmd.Flags().IntVar(&matrixYear, "year", 0, "Only include specs of this model year")
+	similarityMatrixCmd.Flags().StringVar(&matrixColumn, "column", "primary", "Embedding column to compare: primary or alt")
+	similarityMatrixCmd.Flags().IntVar(&matrixMaxRows, "max-rows", 100, "Refuse to run when more specs than this match")
+	similarityMatrixCmd.Flags().BoolVar(&matrixJSON, "json", false, "Output result in JSON format")
+}
+
+// similarityMatrix is the JSON output of the similarity-matrix command.
+// Similarities[i][j] is the cosine similarity of Specs[i] and Specs[j].
+type similarityMatrix struct {
+	Specs        []matrixSpec `json:"specs"`
+	Similarities [][]float64  `json:"similarities"`
+}
+
+// matrixSpec identifies one row and column of the matrix
+type matrixSpec struct {
+	Make  string `json:"make"`
+	Model string `json:"model"`
+	Year  int    `json:"year"`
+}
+
+func runSimilarityMatrix(cmd *cobra.Command, args []string) error {
+	column, err := db.ParseEmbeddingColumn(matrixColumn)
+	if err != nil {
+		return err
+	}
+	if matrixMaxRows < 1 || matrixMaxRows > maxMatrixRowsLimit {
+		return fmt.Errorf("--max-rows must be between 1 and %d, got %d", maxMatrixRowsLimit, matrixMaxRows)
+	}
+	var makes []string
+	for _, make := range matrixMakes {
+		if make = strings.TrimSpace(make); make == "" {
+			continue
+		}
+		if err := models.ValidateFilter("make", make); err != nil {
+			return err
+		}
+		makes = append(makes, make)
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	// One row past the cap tells "exactly at the cap" from "over it"
+	rows, err := dbClient.SpecEmbeddings(ctx, db.SpecFilter{Makes: makes, Year: matrixYear}, column, matrixMaxRows+1)
+	if err != nil {
+		return fmt.Errorf("failed to get embeddings: %w", err)
+	}
+	if len(rows) > matrixMaxRows {
+		return fmt.Errorf("more than %d specs with %s embeddings match; narrow the selection with --makes or --year, or raise --max-rows", matrixMaxRows, column)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("no specs with %s embeddings match", column)
+	}
+
+	matrix, err := computeSimilarityMatrix(rows)
+	if err != nil {
+		return err
+	}
+
+	if matrixJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(matrix); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+	return writeSimilarityMatrix(matrix)
+}
+
+// computeSimilarityMatrix returns the pairwise cosine similarities of rows.
+// Every embedding must have the same dimension, which the alt column does not
+// enforce.
+func computeSimilarityMatrix(rows []db.SpecEmbedding) (similarityMatrix, error) {
+	n := len(rows)
+	matrix := similarityMatrix{Specs: make([]matrixSpec, n), Similarities: make([][]float64, n)}
+	for i, row := range rows {
+		if len(row.Embedding) != len(rows[0].Embedding) {
+			return similarityMatrix{}, fmt.Errorf("%d %s %s has a %d-dimension embedding, but %d %s %s has %d; reembed the column with one model",
+				row.Spec.Year, row.Spec.Make, row.Spec.Model, len(row.Embedding),
+				rows[0].Spec.Year, rows[0].Spec.Make, rows[0].Spec.Model, len(rows[0].Embedding))
+		}
+		matrix.Specs[i] = matrixSpec{Make: row.Spec.Make, Model: row.Spec.Model, Year: row.Spec.Year}
+		matrix.Similarities[i] = make([]float64, n)
+	}
+
+	// The matrix is symmetric, so each pair is computed once
+	for i := range rows {
+		matrix.Similarities[i][i] = 1
+		for j := i + 1; j < n; j++ {
+			similarity := db.CosineSimilarity(rows[i].Embedding, rows[j].Embedding)
+			matrix.Similarities[i][j] = similarity
+			matrix.Similarities[j][i] = similarity
+		}
+	}
+	return matrix, nil
+}
+
+// writeSimilarityMatrix prints a numbered legend of the specs followed by the
+// matrix, with columns headed by the legend numbers
+func writeSimilarityMatrix(matrix similarityMatrix) error {
+	for i, spec := range matrix.Specs {
+		fmt.Printf("%3d  %d %s %s\n", i+1, spec.Year, spec.Make, spec.Model)
+	}
+	fmt.Println()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t")
+	for i := range matrix.Specs {
+		fmt.Fprintf(tw, "%d\t", i+1)
+	}
+	fmt.Fprintln(tw)
+	for i, row := range matrix.Similarities {
+		fmt.Fprintf(tw, "%d\t", i+1)
+		for _, similarity := range row {
+			fmt.Fprintf(tw, "%.3f\t", similarity)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestComputeSimilarityMatrix(t *testing.T) {
+	rows := []db.SpecEmbedding{
+		{Spec: models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023}, Embedding: []float32{1, 0}},
+		{Spec: models.EVSpec{Make: "Tesla", Model: "Model Y", Year: 2023}, Embedding: []float32{1, 1}},
+		{Spec: models.EVSpec{Make: "Kia", Model: "EV6", Year: 2023}, Embedding: []float32{0, 1}},
+	}
+	matrix, err := computeSimilarityMatrix(rows)
+	if err != nil {
+		t.Fatalf("computeSimilarityMatrix: %v", err)
+	}
+	if len(matrix.Specs) != 3 || matrix.Specs[2].Make != "Kia" {
+		t.Fatalf("specs = %+v", matrix.Specs)
+	}
+	want := [][]float64{
+		{1, math.Sqrt2 / 2, 0},
+		{math.Sqrt2 / 2, 1, math.Sqrt2 / 2},
+		{0, math.Sqrt2 / 2, 1},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if got := matrix.Similarities[i][j]; math.Abs(got-want[i][j]) > 1e-6 {
+				t.Errorf("similarity[%d][%d] = %v, want %v", i, j, got, want[i][j])
+			}
+		}
+	}
+}
+
+func TestComputeSimilarityMatrixDimensionMismatch(t *testing.T) {
+	rows := []db.SpecEmbedding{
+		{Spec: models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023}, Embedding: []float32{1, 0}},
+		{Spec: models.EVSpec{Make: "Kia", Model: "EV6", Year: 2023}, Embedding: []float32{1, 0, 0}},
+	}
+	if _, err := computeSimilarityMatrix(rows); err == nil {
+		t.Fatal("computeSimilarityMatrix accepted embeddings of different dimensions")
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// runTimeline prints every stored year of a make and model, oldest first. Gaps
+// between stored years are left as they are; only a year given explicitly
+// that is not stored is resolved, with the usual LLM fallback.
+func runTimeline(cmd *cobra.Command, args []string) error {
+	make := args[0]
+	model := args[1]
+	year := 0
+	if len(args) == 3 {
+		var err error
+		if year, err = strconv.Atoi(args[2]); err != nil {
+			return fmt.Errorf("invalid year: %s", args[2])
+		}
+	}
+
+	if err := models.ValidateVehicle(make, model); err != nil {
+		return err
+	}
+
+	// A timeline is several specs, so it defaults to a table and cannot use env
+	rootOutput.single = false
+	if jsonOutput {
+		rootOutput.format = string(format.JSON)
+	} else if !cmd.Flags().Changed("format") {
+		rootOutput.format = string(format.Table)
+	}
+	if err := rootOutput.validate(); err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	rootOutput.useConfig(cfg)
+
+	ctx := context.Background()
+
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	dbClient, ok := res.Store().(*db.Client)
+	if !ok {
+		return fmt.Errorf("timeline requires the postgres store backend")
+	}
+
+	specs, err := dbClient.GetAllYears(ctx, make, model)
+	if err != nil {
+		return fmt.Errorf("failed to get timeline: %w", err)
+	}
+
+	if year != 0 && !hasYear(specs, year) {
+		spec, err := res.Resolve(rootOutput.track(ctx), make, model, year)
+		if err != nil {
+			return err
+		}
+		// A similarity match may answer with a neighbouring year or another
+		// model, which must not be shown in place of the one asked for
+		if strings.EqualFold(spec.Make, make) && strings.EqualFold(spec.Model, model) && spec.Year == year {
+			specs = append(specs, *spec)
+			sort.SliceStable(specs, func(i, j int) bool { return specs[i].Year < specs[j].Year })
+		} else if len(specs) == 0 {
+			return fmt.Errorf("could not resolve %d %s %s: the closest match is %d %s %s", year, make, model, spec.Year, spec.Make, spec.Model)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: could not resolve %d %s %s (the closest match is %d %s %s); showing the stored years only\n", year, make, model, spec.Year, spec.Make, spec.Model)
+		}
+	}
+
+	if len(specs) == 0 {
+		return fmt.Errorf("no stored years for %s %s; pass a year to resolve one", make, model)
+	}
+
+	return rootOutput.writeSpecs(specs)
+}
+
+// hasYear reports whether specs contains the given model year
+func hasYear(specs []models.EVSpec, year int) bool {
+	for _, spec := range specs {
+		if spec.Year == year {
+			return true
+		}
+	}
+	return false
+}
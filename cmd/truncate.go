@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	truncateConfirm bool
+	truncateDryRun  bool
+)
+
+// truncateCmd represents the truncate command
+var truncateCmd = &cobra.Command{
+	Use:   "truncate",
+	Short: "Remove every stored EV specification",
+	Long: `Remove every stored EV specification and reset the id sequence, keeping the
+schema, the embedding cache and the history of earlier revisions. Use it to
+start over on a development database without dropping and migrating it again.
+
+--confirm is required, and truncate refuses to run with --read-only. Unlike
+delete, the removed specs are not copied to the history table. With --dry-run
+the specs are only counted.
+
+Examples:
+  ev-oracle truncate --dry-run
+  ev-oracle truncate --confirm`,
+	Args: cobra.NoArgs,
+	RunE: runTruncate,
+}
+
+func init() {
+	rootCmd.AddCommand(truncateCmd)
+	truncateCmd.Flags().BoolVar(&truncateConfirm, "confirm", false, "Required to remove anything")
+	truncateCmd.Flags().BoolVar(&truncateDryRun, "dry-run", false, "Count the specs that would be removed without removing them")
+}
+
+func runTruncate(cmd *cobra.Command, args []string) error {
+	if !truncateConfirm && !truncateDryRun {
+		return fmt.Errorf("refusing to truncate without --confirm")
+	}
+	if readOnly && !truncateDryRun {
+		return fmt.Errorf("refusing to truncate with --read-only")
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig(models.WithDatabaseOnly())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	// Initialize database client
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, dbOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbClient.Close()
+
+	count, err := dbClient.CountSpecs(ctx, db.SpecFilter{})
+	if err != nil {
+		return err
+	}
+	if truncateDryRun {
+		printDryRun("remove", count, "spec")
+		return nil
+	}
+	if err := dbClient.TruncateSpecs(ctx); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d spec(s).\n", count)
+	return nil
+}
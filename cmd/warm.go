@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	warmConcurrency int
+	warmSave        bool
+)
+
+// warmCmd represents the warm command
+var warmCmd = &cobra.Command{
+	Use:   "warm [file]",
+	Short: "Pre-resolve popular queries ahead of real traffic",
+	Long: `Resolve every make,model,year row of a file (or - for stdin), in the same
+format as batch, without printing the specs. Use it when a new deployment goes
+live so the first real requests do not all hit the LLM at once.
+
+With --save, LLM answers are stored in the database (as with
+SAVE_LLM_RESULTS=true), so later queries for the same vehicles are answered by
+exact match. A summary of how many queries were answered from each source is
+printed at the end.
+
+Examples:
+  ev-oracle warm popular.csv --save
+  ev-oracle warm popular.csv --save --concurrency 8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWarm,
+}
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+	warmCmd.Flags().IntVar(&warmConcurrency, "concurrency", 4, "Number of queries to resolve in parallel")
+	warmCmd.Flags().BoolVar(&warmSave, "save", false, "Store LLM answers in the database")
+}
+
+func runWarm(cmd *cobra.Command, args []string) error {
+	if warmSave && readOnly {
+		return fmt.Errorf("--save cannot be used with --read-only")
+	}
+
+	queries, lines, err := readQueriesFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := models.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx := context.Background()
+
+	var setupOpts []resolver.SetupOption
+	if warmSave {
+		setupOpts = append(setupOpts, resolver.WithResolverOptions(resolver.WithSaveLLMResults(cfg.StoreRawResponse)))
+	}
+
+	// Open the spec store and create the embedding and LLM services
+	res, err := newResolver(ctx, cfg, setupOpts...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	warnDocumentTemplate(ctx, res.Store(), res.Embedding())
+
+	report := resolver.NewFleetReport()
+	err = res.ResolveBatch(ctx, queries, warmConcurrency, false, func(result resolver.BatchResult) error {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "line %d (%d %s %s): %v\n", lines[result.Index], result.Query.Year, result.Query.Make, result.Query.Model, result.Err)
+		}
+		report.Add(result)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Warmed %d of %d queries (%d failed)\n", report.Resolved, report.Vehicles, report.Failed)
+	for _, source := range sortedByCount(report.Sources) {
+		fmt.Printf("  %-14s %d\n", source+":", report.Sources[source])
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CachedEmbedding returns the embedding cached for model and the SHA-256 hex
+// digest of the input text, or nil if there is none
+func (c *Client) CachedEmbedding(ctx context.Context, model, textHash string) ([]float32, error) {
+	var embedding []float32
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx,
+			`SELECT embedding FROM embedding_cache WHERE model = $1 AND text_sha256 = $2`,
+			model, textHash,
+		).Scan(&embedding)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+	return embedding, nil
+}
+
+// StoreCachedEmbedding caches embedding under model and the SHA-256 hex
+// digest of the input text, keeping an entry that is already cached
+func (c *Client) StoreCachedEmbedding(ctx context.Context, model, textHash string, embedding []float32) error {
+	_, err := c.pool.Exec(ctx, `
+		INSERT INTO embedding_cache (model, text_sha256, embedding)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (model, text_sha256) DO NOTHING
+	`, model, textHash, embedding)
+	if err != nil {
+		return fmt.Errorf("failed to write embedding cache: %w", err)
+	}
+	return nil
+}
+
+// ClearEmbeddingCache deletes the cached embeddings of model, or of every
+// model if model is empty, and returns how many were deleted
+func (c *Client) ClearEmbeddingCache(ctx context.Context, model string) (int64, error) {
+	query := `DELETE FROM embedding_cache`
+	var args []any
+	if model != "" {
+		query += ` WHERE model = $1`
+		args = append(args, model)
+	}
+	tag, err := c.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear embedding cache: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
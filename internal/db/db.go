@@ -2,14 +2,18 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq" // PostgreSQL driver for golang-migrate
 	"github.com/scaryPonens/ev-oracle/internal/models"
@@ -19,24 +23,57 @@ import (
 type Client struct {
 	pool        *pgxpool.Pool
 	databaseURL string
+	dimension   atomic.Int64 // cached embedding column dimension, 0 until loaded
+	readOnly    bool
+	// queryAttempts is how many times read queries are tried on transient errors
+	queryAttempts int
+}
+
+// Option is a functional option for New
+type Option func(*Client)
+
+// WithReadOnly opens every connection with default_transaction_read_only,
+// so Postgres rejects any write, and refuses migrations and TruncateSpecs
+// up front
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
 }
 
 // New creates a new database client
-func New(ctx context.Context, databaseURL string) (*Client, error) {
-	pool, err := pgxpool.New(ctx, databaseURL)
+func New(ctx context.Context, databaseURL string, opts ...Option) (*Client, error) {
+	// pgx would otherwise fall back to a local server and fail to connect
+	if databaseURL == "" {
+		return nil, fmt.Errorf("no database URL configured (set NEON_DATABASE_URL)")
+	}
+
+	c := &Client{databaseURL: databaseURL, queryAttempts: defaultQueryAttempts}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+		return nil, redactError(fmt.Errorf("failed to create connection pool: %w", err), databaseURL)
+	}
+	if c.readOnly {
+		config.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, redactError(fmt.Errorf("failed to create connection pool: %w", err), databaseURL)
 	}
 
 	// Test the connection
 	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		pool.Close()
+		return nil, redactError(fmt.Errorf("failed to ping database: %w", err), databaseURL)
 	}
 
-	return &Client{
-		pool:        pool,
-		databaseURL: databaseURL,
-	}, nil
+	c.pool = pool
+	return c, nil
 }
 
 // Close closes the database connection pool
@@ -59,7 +96,7 @@ func (c *Client) MigrateUp(ctx context.Context) error {
 	defer m.Close()
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return redactError(fmt.Errorf("failed to run migrations: %w", dirtyError(m, err)), c.databaseURL)
 	}
 
 	return nil
@@ -74,7 +111,7 @@ func (c *Client) MigrateDown(ctx context.Context) error {
 	defer m.Close()
 
 	if err := m.Down(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to rollback migration: %w", err)
+		return redactError(fmt.Errorf("failed to rollback migration: %w", dirtyError(m, err)), c.databaseURL)
 	}
 
 	return nil
@@ -89,45 +126,302 @@ func (c *Client) MigrateSteps(ctx context.Context, n int) error {
 	defer m.Close()
 
 	if err := m.Steps(n); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migration steps: %w", err)
+		return redactError(fmt.Errorf("failed to run migration steps: %w", dirtyError(m, err)), c.databaseURL)
+	}
+
+	return nil
+}
+
+// MigrateForce sets the recorded migration version without running any
+// migration and clears the dirty flag. Use -1 for no applied migrations.
+func (c *Client) MigrateForce(ctx context.Context, version int) error {
+	if version < -1 {
+		return fmt.Errorf("invalid migration version: %d", version)
+	}
+
+	m, err := c.getMigrateInstance()
+	if err != nil {
+		return fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	defer m.Close()
+
+	if err := m.Force(version); err != nil {
+		return redactError(fmt.Errorf("failed to force migration version: %w", err), c.databaseURL)
 	}
 
 	return nil
 }
 
+// dirtyError wraps err in a *DirtyError if the database is dirty, either
+// because it already was or because the migration that returned err failed
+// midway, so the caller learns how to recover
+func dirtyError(m *migrate.Migrate, err error) error {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return &DirtyError{Version: dirty.Version, Err: err}
+	}
+	if version, isDirty, verr := m.Version(); verr == nil && isDirty {
+		return &DirtyError{Version: int(version), Err: err}
+	}
+	return err
+}
+
+// EmbeddingDimension returns the declared dimension of the ev_specs.embedding
+// column. pgvector stores the dimension as the column's type modifier, so the
+// value set by migrations or SetEmbeddingDimension is always queryable with:
+//
+//	SELECT atttypmod FROM pg_attribute
+//	WHERE attrelid = 'ev_specs'::regclass AND attname = 'embedding'
+func (c *Client) EmbeddingDimension(ctx context.Context) (int, error) {
+	query := `
+		SELECT atttypmod
+		FROM pg_attribute
+		WHERE attrelid = 'ev_specs'::regclass AND attname = 'embedding' AND NOT attisdropped
+	`
+
+	var dimension int
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, query).Scan(&dimension)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedding dimension: %w", err)
+	}
+	if dimension <= 0 {
+		return 0, fmt.Errorf("embedding column has no declared dimension")
+	}
+
+	return dimension, nil
+}
+
+// StoredEmbeddings returns how many rows have a primary embedding
+func (c *Client) StoredEmbeddings(ctx context.Context) (int, error) {
+	var stored int
+	if err := c.pool.QueryRow(ctx, "SELECT COUNT(*) FROM ev_specs WHERE embedding IS NOT NULL").Scan(&stored); err != nil {
+		return 0, fmt.Errorf("failed to count stored embeddings: %w", err)
+	}
+	return stored, nil
+}
+
+// SetEmbeddingDimension changes the ev_specs.embedding column to vector(n) and
+// rebuilds the similarity index. It refuses to run if any embeddings are
+// already stored, since they would not fit the new dimension.
+func (c *Client) SetEmbeddingDimension(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid embedding dimension: %d", n)
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var stored int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM ev_specs WHERE embedding IS NOT NULL").Scan(&stored); err != nil {
+		return fmt.Errorf("failed to count stored embeddings: %w", err)
+	}
+	if stored > 0 {
+		return fmt.Errorf("cannot change embedding dimension: %d rows already have embeddings", stored)
+	}
+
+	statements := []string{
+		"DROP INDEX IF EXISTS ev_specs_embedding_idx",
+		fmt.Sprintf("ALTER TABLE ev_specs ALTER COLUMN embedding TYPE vector(%d)", n),
+		`CREATE INDEX ev_specs_embedding_idx ON ev_specs
+			USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to change embedding dimension: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	c.dimension.Store(int64(n))
+	return nil
+}
+
+// CheckDimension validates an embedding against the column dimension,
+// loading and caching the dimension on first use. A mismatch is returned as a
+// *DimensionMismatchError.
+func (c *Client) CheckDimension(ctx context.Context, embedding []float32) error {
+	dimension := int(c.dimension.Load())
+	if dimension == 0 {
+		loaded, err := c.EmbeddingDimension(ctx)
+		if err != nil {
+			return err
+		}
+		dimension = loaded
+		c.dimension.Store(int64(dimension))
+	}
+
+	if len(embedding) != dimension {
+		return &DimensionMismatchError{Expected: dimension, Actual: len(embedding)}
+	}
+	return nil
+}
+
 // getMigrateInstance creates a migrate instance for the database
 func (c *Client) getMigrateInstance() (*migrate.Migrate, error) {
-	// Get migrations directory path (relative to project root)
-	migrationsPath, err := filepath.Abs("migrations")
+	// golang-migrate opens its own connection, which the pool's read-only
+	// session setting does not cover
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	sourceURL, err := migrationsSourceURL()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get migrations path: %w", err)
+		return nil, err
 	}
 
 	// Use the database URL directly
 	// golang-migrate accepts both postgres:// and postgresql:// formats
 	dbURL := c.databaseURL
 
-	m, err := migrate.New(
-		fmt.Sprintf("file://%s", migrationsPath),
-		dbURL,
-	)
+	m, err := migrate.New(sourceURL, dbURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+		return nil, redactError(fmt.Errorf("failed to create migrate instance: %w", err), c.databaseURL)
 	}
 
 	return m, nil
 }
 
-// SimilaritySearch performs a vector similarity search
-func (c *Client) SimilaritySearch(ctx context.Context, embedding []float32, limit int) ([]models.EVSpec, error) {
-	// Format embedding as a string in pgvector format: [1.0,2.0,3.0]
-	embeddingStrs := make([]string, len(embedding))
+// migrationsSourceURL returns the golang-migrate source URL of the migrations
+// directory, relative to the working directory
+func migrationsSourceURL() (string, error) {
+	migrationsPath, err := filepath.Abs("migrations")
+	if err != nil {
+		return "", fmt.Errorf("failed to get migrations path: %w", err)
+	}
+	return fmt.Sprintf("file://%s", migrationsPath), nil
+}
+
+// formatVector formats an embedding as a pgvector literal: [1.0,2.0,3.0].
+// It appends into a single pre-sized buffer instead of allocating a string
+// per element, which matters for 768/1536-dimensional vectors on every request.
+func formatVector(embedding []float32) string {
+	// Most float32 values format in at most 15 bytes plus a separator
+	buf := make([]byte, 0, 2+len(embedding)*16)
+	buf = append(buf, '[')
 	for i, v := range embedding {
-		embeddingStrs[i] = fmt.Sprintf("%g", v)
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
 	}
-	embeddingStr := "[" + strings.Join(embeddingStrs, ",") + "]"
+	buf = append(buf, ']')
+	return string(buf)
+}
 
-	query := `
+// querier is the subset of pgx query methods shared by pools and transactions
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// EmbeddingColumn selects which stored embedding a search or update uses
+type EmbeddingColumn string
+
+const (
+	// ColumnPrimary is the embedding column used by lookups
+	ColumnPrimary EmbeddingColumn = "embedding"
+	// ColumnAlt is the optional secondary column for comparing a second
+	// embedding model on the same rows. It has no fixed dimension and no
+	// index, so searches over it are exact scans.
+	ColumnAlt EmbeddingColumn = "embedding_alt"
+)
+
+// ParseEmbeddingColumn converts "primary" or "alt" into an EmbeddingColumn
+func ParseEmbeddingColumn(name string) (EmbeddingColumn, error) {
+	switch name {
+	case "", "primary":
+		return ColumnPrimary, nil
+	case "alt":
+		return ColumnAlt, nil
+	default:
+		return "", fmt.Errorf("invalid embedding column: %s (use primary or alt)", name)
+	}
+}
+
+// searchOptions holds the optional settings for SimilaritySearch
+type searchOptions struct {
+	exact         bool
+	column        EmbeddingColumn
+	recencyYear   int     // query year for WithRecencyBoost
+	recencyWeight float64 // 0 disables the recency boost
+}
+
+// SearchOption is a functional option for SimilaritySearch
+type SearchOption func(*searchOptions)
+
+// WithExactScan disables index scans for the search so Postgres performs an
+// exact nearest-neighbor scan over every row instead of using the approximate
+// IVFFlat index. This is slower but returns the true nearest neighbors.
+func WithExactScan() SearchOption {
+	return func(o *searchOptions) {
+		o.exact = true
+	}
+}
+
+// WithEmbeddingColumn searches column instead of the primary embedding
+func WithEmbeddingColumn(column EmbeddingColumn) SearchOption {
+	return func(o *searchOptions) {
+		o.column = column
+	}
+}
+
+// SimilaritySearch performs a vector similarity search
+func (c *Client) SimilaritySearch(ctx context.Context, embedding []float32, limit int, opts ...SearchOption) ([]models.EVSpec, error) {
+	return retryRead(ctx, c, func() ([]models.EVSpec, error) {
+		return c.similaritySearchOnce(ctx, embedding, limit, opts...)
+	})
+}
+
+// similaritySearchOnce runs one attempt of SimilaritySearch
+func (c *Client) similaritySearchOnce(ctx context.Context, embedding []float32, limit int, opts ...SearchOption) ([]models.EVSpec, error) {
+	options := searchOptions{column: ColumnPrimary}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.column != ColumnPrimary && options.column != ColumnAlt {
+		return nil, fmt.Errorf("invalid embedding column: %s", options.column)
+	}
+
+	if !options.exact {
+		specs, err := similaritySearch(ctx, c.pool, options.column, embedding, limit)
+		if err != nil {
+			return nil, err
+		}
+		return boostRecency(specs, options), nil
+	}
+
+	// SET LOCAL only applies inside a transaction, so the planner setting
+	// is scoped to this search and never leaks back into the pool
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL enable_indexscan = off"); err != nil {
+		return nil, fmt.Errorf("failed to disable index scan: %w", err)
+	}
+
+	specs, err := similaritySearch(ctx, tx, options.column, embedding, limit)
+	if err != nil {
+		return nil, err
+	}
+	return boostRecency(specs, options), nil
+}
+
+// similaritySearch runs the nearest-neighbor query over column against the
+// given querier. column must be ColumnPrimary or ColumnAlt.
+func similaritySearch(ctx context.Context, q querier, column EmbeddingColumn, embedding []float32, limit int) ([]models.EVSpec, error) {
+	embeddingStr := formatVector(embedding)
+
+	query := fmt.Sprintf(`
 		SELECT 
 			make, 
 			model, 
@@ -135,98 +429,337 @@ func (c *Client) SimilaritySearch(ctx context.Context, embedding []float32, limi
 			capacity_kwh, 
 			power_kw, 
 			chemistry,
-			1 - (embedding <=> $1::vector) as confidence
+			tags,
+			COALESCE(notes, ''),
+			COALESCE(body_style, ''),
+			1 - (%[1]s <=> $1::vector) as confidence
 		FROM ev_specs
-		WHERE embedding IS NOT NULL
-		ORDER BY embedding <=> $1::vector
+		WHERE %[1]s IS NOT NULL
+		ORDER BY %[1]s <=> $1::vector
 		LIMIT $2
-	`
+	`, column)
 
-	rows, err := c.pool.Query(ctx, query, embeddingStr, limit)
+	rows, err := q.Query(ctx, query, embeddingStr, limit)
 	if err != nil {
+		if dimErr := asDimensionMismatch(err); dimErr != nil {
+			return nil, dimErr
+		}
 		return nil, fmt.Errorf("failed to query database: %w", err)
 	}
 	defer rows.Close()
 
 	var specs []models.EVSpec
 	for rows.Next() {
+		// Stop promptly if the caller gave up; the deferred rows.Close()
+		// still releases the connection
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		var spec models.EVSpec
+		var nf nullableFields
 		err := rows.Scan(
 			&spec.Make,
 			&spec.Model,
 			&spec.Year,
-			&spec.Capacity,
-			&spec.Power,
-			&spec.Chemistry,
+			&nf.capacity,
+			&nf.power,
+			&nf.chemistry,
+			&spec.Tags,
+			&spec.Notes,
+			&spec.BodyStyle,
 			&spec.Confidence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
+		nf.apply(&spec)
 		spec.Source = "database"
 		specs = append(specs, spec)
 	}
 
 	if err := rows.Err(); err != nil {
+		if dimErr := asDimensionMismatch(err); dimErr != nil {
+			return nil, dimErr
+		}
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
 	return specs, nil
 }
 
-// InsertEVSpec inserts a new EV specification with its embedding
-func (c *Client) InsertEVSpec(ctx context.Context, spec *models.EVSpec, embedding []float32) error {
-	// Format embedding as a string in pgvector format: [1.0,2.0,3.0]
-	embeddingStrs := make([]string, len(embedding))
-	for i, v := range embedding {
-		embeddingStrs[i] = fmt.Sprintf("%g", v)
-	}
-	embeddingStr := "[" + strings.Join(embeddingStrs, ",") + "]"
+// ConflictPolicy controls what InsertEVSpec does when a spec with the same
+// make, model and year is already stored
+type ConflictPolicy int
 
-	query := `
-		INSERT INTO ev_specs (make, model, year, capacity_kwh, power_kw, chemistry, embedding)
-		VALUES ($1, $2, $3, $4, $5, $6, $7::vector)
-		ON CONFLICT (make, model, year) 
-		DO UPDATE SET 
+const (
+	// ConflictUpdate replaces the stored spec (ON CONFLICT DO UPDATE)
+	ConflictUpdate ConflictPolicy = iota
+	// ConflictSkip leaves the stored spec alone (ON CONFLICT DO NOTHING)
+	ConflictSkip
+	// ConflictError fails on the unique constraint (no ON CONFLICT clause)
+	ConflictError
+)
+
+// clause returns the ON CONFLICT clause for the policy
+func (p ConflictPolicy) clause() string {
+	switch p {
+	case ConflictSkip:
+		return "ON CONFLICT (match_key) DO NOTHING"
+	case ConflictError:
+		return ""
+	default:
+		return `ON CONFLICT (match_key)
+		DO UPDATE SET
 			capacity_kwh = EXCLUDED.capacity_kwh,
 			power_kw = EXCLUDED.power_kw,
 			chemistry = EXCLUDED.chemistry,
+			tags = EXCLUDED.tags,
+			source = EXCLUDED.source,
+			raw_response = EXCLUDED.raw_response,
+			authoritative = EXCLUDED.authoritative,
+			notes = EXCLUDED.notes,
+			body_style = EXCLUDED.body_style,
+			confidence = EXCLUDED.confidence,
 			embedding = EXCLUDED.embedding
-	`
+		WHERE NOT ev_specs.authoritative OR EXCLUDED.authoritative`
+	}
+}
+
+type insertOptions struct {
+	conflict ConflictPolicy
+}
+
+// InsertOption is a functional option for InsertEVSpec
+type InsertOption func(*insertOptions)
+
+// WithConflictPolicy sets the behavior when the spec is already stored
+// (default: ConflictUpdate)
+func WithConflictPolicy(p ConflictPolicy) InsertOption {
+	return func(o *insertOptions) {
+		o.conflict = p
+	}
+}
+
+// InsertEVSpec inserts a new EV specification with its embedding. By default
+// any stored spec with the same match key is replaced, keeping its stored
+// make and model spelling, except that an authoritative stored spec is only
+// replaced by another authoritative one; otherwise ErrAuthoritative is
+// returned. With ConflictSkip or ConflictError
+// the stored spec is never touched and ErrSpecExists is returned.
+func (c *Client) InsertEVSpec(ctx context.Context, spec *models.EVSpec, embedding []float32, opts ...InsertOption) error {
+	var options insertOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := c.CheckDimension(ctx, embedding); err != nil {
+		return err
+	}
 
-	_, err := c.pool.Exec(ctx, query,
+	embeddingStr := formatVector(embedding)
+
+	query := `
+		INSERT INTO ev_specs (make, model, year, capacity_kwh, power_kw, chemistry, tags, source, raw_response, authoritative, notes, body_style, confidence, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, 0::float), $14::vector)
+		` + options.conflict.clause()
+
+	// A nil slice would be sent as NULL and violate the NOT NULL constraint
+	tags := spec.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	source := spec.Source
+	if source == "" {
+		source = "database"
+	}
+
+	// Raw responses are only meaningful for LLM-derived specs
+	var rawResponse *string
+	if source == "llm" && spec.RawResponse != "" {
+		rawResponse = &spec.RawResponse
+	}
+
+	tag, err := c.pool.Exec(ctx, query,
 		spec.Make,
 		spec.Model,
 		spec.Year,
 		spec.Capacity,
 		spec.Power,
 		spec.Chemistry,
+		tags,
+		source,
+		rawResponse,
+		spec.Authoritative,
+		spec.Notes,
+		spec.BodyStyle,
+		spec.Confidence,
 		embeddingStr,
 	)
 	if err != nil {
+		if dimErr := asDimensionMismatch(err); dimErr != nil {
+			// The cached dimension is stale if the column was changed underneath us
+			c.dimension.Store(0)
+			return dimErr
+		}
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, ErrSpecExists)
+		}
 		return fmt.Errorf("failed to insert spec: %w", err)
 	}
+	// The conflict clause writes nothing when skipping, or when the stored
+	// row is authoritative
+	if tag.RowsAffected() == 0 {
+		if options.conflict == ConflictSkip {
+			return fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, ErrSpecExists)
+		}
+		return fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, ErrAuthoritative)
+	}
 
 	return nil
 }
 
-// GetByMakeModelYear retrieves an EV spec by exact make, model, and year
+// updateOptions holds the settings of an UpdateSpecFields call
+type updateOptions struct {
+	confidence bool
+}
+
+// UpdateOption is a functional option for UpdateSpecFields
+type UpdateOption func(*updateOptions)
+
+// WithConfidence also overwrites the stored confidence with spec.Confidence
+func WithConfidence() UpdateOption {
+	return func(o *updateOptions) {
+		o.confidence = true
+	}
+}
+
+// UpdateSpecFields overwrites the capacity, power, chemistry and source of the
+// stored spec with the same make, model and year, leaving its embedding and
+// tags untouched. Authoritative specs are never updated.
+func (c *Client) UpdateSpecFields(ctx context.Context, spec *models.EVSpec, opts ...UpdateOption) error {
+	var options updateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// $8 is only written with WithConfidence
+	query := `
+		UPDATE ev_specs
+		SET capacity_kwh = $4, power_kw = $5, chemistry = $6, source = $7,
+			confidence = CASE WHEN $9 THEN $8 ELSE confidence END
+		WHERE match_key = ev_match_key($1, $2, $3) AND NOT authoritative
+	`
+
+	tag, err := c.pool.Exec(ctx, query, spec.Make, spec.Model, spec.Year, spec.Capacity, spec.Power, spec.Chemistry, spec.Source,
+		spec.Confidence, options.confidence)
+	if err != nil {
+		return fmt.Errorf("failed to update spec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no stored non-authoritative spec for %d %s %s", spec.Year, spec.Make, spec.Model)
+	}
+	return nil
+}
+
+// UpdateEmbedding replaces the embedding in column of the stored spec with the
+// same make, model and year, leaving every other field untouched. Embeddings
+// for ColumnPrimary must match its dimension; ColumnAlt accepts any dimension.
+func (c *Client) UpdateEmbedding(ctx context.Context, column EmbeddingColumn, spec *models.EVSpec, embedding []float32) error {
+	switch column {
+	case ColumnPrimary:
+		if err := c.CheckDimension(ctx, embedding); err != nil {
+			return err
+		}
+	case ColumnAlt:
+	default:
+		return fmt.Errorf("invalid embedding column: %s", column)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE ev_specs
+		SET %s = $4::vector
+		WHERE match_key = ev_match_key($1, $2, $3)
+	`, column)
+
+	tag, err := c.pool.Exec(ctx, query, spec.Make, spec.Model, spec.Year, formatVector(embedding))
+	if err != nil {
+		if dimErr := asDimensionMismatch(err); dimErr != nil {
+			return dimErr
+		}
+		return fmt.Errorf("failed to update embedding: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no stored spec for %d %s %s", spec.Year, spec.Make, spec.Model)
+	}
+	return nil
+}
+
+// nullableFields holds scan targets for spec columns that can be NULL in rows
+// written outside ev-oracle, e.g. by direct SQL or a partial import, so one
+// such row does not fail a whole query
+type nullableFields struct {
+	capacity   pgtype.Float8
+	power      pgtype.Float8
+	chemistry  pgtype.Text
+	confidence pgtype.Float8 // NULL unless supplied when the spec was stored
+}
+
+// apply copies the scanned values into spec. NULLs become zero or empty,
+// which the resolver treats as missing fields.
+func (n *nullableFields) apply(spec *models.EVSpec) {
+	spec.Capacity = n.capacity.Float64
+	spec.Power = n.power.Float64
+	spec.Chemistry = n.chemistry.String
+}
+
+// storedConfidence returns the confidence of a stored spec. Authoritative
+// specs always report 1.0. Otherwise a confidence supplied at insert time
+// wins; without one, saved LLM answers keep the LLM's confidence and
+// everything else reports a curated 1.0.
+func (n *nullableFields) storedConfidence(spec *models.EVSpec) float64 {
+	switch {
+	case spec.Authoritative:
+		return 1.0
+	case n.confidence.Valid:
+		return n.confidence.Float64
+	default:
+		return models.DefaultConfidence(spec.Source)
+	}
+}
+
+// GetByMakeModelYear retrieves an EV spec by make, model, and year, compared
+// by match key (see models.MatchKey), so case, whitespace and punctuation
+// differences still match. The stored display values are returned.
 func (c *Client) GetByMakeModelYear(ctx context.Context, make, model string, year int) (*models.EVSpec, error) {
 	query := `
-		SELECT make, model, year, capacity_kwh, power_kw, chemistry
+		SELECT make, model, year, capacity_kwh, power_kw, chemistry, tags,
+			COALESCE(source, 'database'), COALESCE(raw_response, ''), authoritative, COALESCE(notes, ''),
+			COALESCE(body_style, ''), confidence
 		FROM ev_specs
-		WHERE LOWER(make) = LOWER($1) AND LOWER(model) = LOWER($2) AND year = $3
+		WHERE match_key = ev_match_key($1, $2, $3)
 	`
 
 	var spec models.EVSpec
-	err := c.pool.QueryRow(ctx, query, make, model, year).Scan(
-		&spec.Make,
-		&spec.Model,
-		&spec.Year,
-		&spec.Capacity,
-		&spec.Power,
-		&spec.Chemistry,
-	)
+	var nf nullableFields
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, query, make, model, year).Scan(
+			&spec.Make,
+			&spec.Model,
+			&spec.Year,
+			&nf.capacity,
+			&nf.power,
+			&nf.chemistry,
+			&spec.Tags,
+			&spec.Source,
+			&spec.RawResponse,
+			&spec.Authoritative,
+			&spec.Notes,
+			&spec.BodyStyle,
+			&nf.confidence,
+		)
+	})
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -234,9 +767,590 @@ func (c *Client) GetByMakeModelYear(ctx context.Context, make, model string, yea
 		}
 		return nil, fmt.Errorf("failed to query spec: %w", err)
 	}
-
-	spec.Confidence = 1.0
-	spec.Source = "database"
+	nf.apply(&spec)
+	spec.Confidence = nf.storedConfidence(&spec)
 
 	return &spec, nil
 }
+
+// GetAllYears retrieves every stored year of a make and model, oldest first
+func (c *Client) GetAllYears(ctx context.Context, make, model string) ([]models.EVSpec, error) {
+	return retryRead(ctx, c, func() ([]models.EVSpec, error) {
+		return c.getAllYearsOnce(ctx, make, model)
+	})
+}
+
+// getAllYearsOnce runs one attempt of GetAllYears
+func (c *Client) getAllYearsOnce(ctx context.Context, make, model string) ([]models.EVSpec, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ev_specs
+		WHERE LOWER(make) = LOWER($1) AND LOWER(model) = LOWER($2)
+		ORDER BY year
+	`, listedColumns)
+
+	rows, err := c.pool.Query(ctx, query, make, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	var specs []models.EVSpec
+	for rows.Next() {
+		spec, err := scanListed(rows)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return specs, nil
+}
+
+// GetByMakeYear retrieves every stored model of a make for one model year,
+// ordered by model
+func (c *Client) GetByMakeYear(ctx context.Context, make string, year int) ([]models.EVSpec, error) {
+	return retryRead(ctx, c, func() ([]models.EVSpec, error) {
+		return c.getByMakeYearOnce(ctx, make, year)
+	})
+}
+
+// getByMakeYearOnce runs one attempt of GetByMakeYear
+func (c *Client) getByMakeYearOnce(ctx context.Context, make string, year int) ([]models.EVSpec, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ev_specs
+		WHERE LOWER(make) = LOWER($1) AND year = $2
+		ORDER BY model
+	`, listedColumns)
+
+	rows, err := c.pool.Query(ctx, query, make, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	var specs []models.EVSpec
+	for rows.Next() {
+		spec, err := scanListed(rows)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return specs, nil
+}
+
+// SpecFilter narrows the rows returned by ListSpecs, counted by CountSpecs and
+// removed by DeleteWhere
+type SpecFilter struct {
+	Make      string   // Exact make, case-insensitive
+	Makes     []string // Any of these makes, case-insensitive
+	Year      int      // Exact model year, 0 for any
+	Chemistry string   // Exact chemistry, case-insensitive
+	Source    string   // Exact source, e.g. database or llm
+	BodyStyle string   // Exact normalized body style, e.g. SUV
+	Tags      []string // Rows must carry all of these tags (or any, with MatchAnyTag)
+	// MatchAnyTag switches tag matching from AND (tags @> ...) to OR (tags && ...)
+	MatchAnyTag bool
+	// MissingEmbedding, if set, keeps only rows without an embedding in that column
+	MissingEmbedding EmbeddingColumn
+}
+
+// where builds the WHERE clause and its positional arguments for the filter
+func (f SpecFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+
+	if f.Make != "" {
+		args = append(args, f.Make)
+		conditions = append(conditions, fmt.Sprintf("LOWER(make) = LOWER($%d)", len(args)))
+	}
+	if len(f.Makes) > 0 {
+		lower := make([]string, len(f.Makes))
+		for i, m := range f.Makes {
+			lower[i] = strings.ToLower(m)
+		}
+		args = append(args, lower)
+		conditions = append(conditions, fmt.Sprintf("LOWER(make) = ANY($%d::text[])", len(args)))
+	}
+	if f.Year != 0 {
+		args = append(args, f.Year)
+		conditions = append(conditions, fmt.Sprintf("year = $%d", len(args)))
+	}
+	if f.Chemistry != "" {
+		args = append(args, f.Chemistry)
+		conditions = append(conditions, fmt.Sprintf("LOWER(chemistry) = LOWER($%d)", len(args)))
+	}
+	if f.Source != "" {
+		args = append(args, f.Source)
+		conditions = append(conditions, fmt.Sprintf("source = $%d", len(args)))
+	}
+	if f.BodyStyle != "" {
+		args = append(args, f.BodyStyle)
+		conditions = append(conditions, fmt.Sprintf("LOWER(body_style) = LOWER($%d)", len(args)))
+	}
+	if len(f.Tags) > 0 {
+		args = append(args, f.Tags)
+		operator := "@>"
+		if f.MatchAnyTag {
+			operator = "&&"
+		}
+		conditions = append(conditions, fmt.Sprintf("tags %s $%d::text[]", operator, len(args)))
+	}
+
+	if f.MissingEmbedding == ColumnPrimary || f.MissingEmbedding == ColumnAlt {
+		conditions = append(conditions, fmt.Sprintf("%s IS NULL", f.MissingEmbedding))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// listOptions holds settings for ListSpecs
+type listOptions struct {
+	limit  int // 0 for no limit
+	offset int
+	sort   Sort
+}
+
+// ListOption is a functional option for ListSpecs
+type ListOption func(*listOptions)
+
+// WithLimit returns at most n specs
+func WithLimit(n int) ListOption {
+	return func(o *listOptions) {
+		o.limit = n
+	}
+}
+
+// WithOffset skips the first n matching specs
+func WithOffset(n int) ListOption {
+	return func(o *listOptions) {
+		o.offset = n
+	}
+}
+
+// WithSort orders the specs by s instead of the default order
+func WithSort(s Sort) ListOption {
+	return func(o *listOptions) {
+		o.sort = s
+	}
+}
+
+// listedColumns are the columns read by scanListed
+const listedColumns = `make, model, year, capacity_kwh, power_kw, chemistry, tags, COALESCE(source, 'database'), COALESCE(notes, ''),
+			COALESCE(body_style, ''), confidence, authoritative`
+
+// scanListed scans a row selected with listedColumns, followed by any extra
+// columns into extra
+func scanListed(rows pgx.Rows, extra ...any) (models.EVSpec, error) {
+	var spec models.EVSpec
+	var nf nullableFields
+	dest := append([]any{
+		&spec.Make,
+		&spec.Model,
+		&spec.Year,
+		&nf.capacity,
+		&nf.power,
+		&nf.chemistry,
+		&spec.Tags,
+		&spec.Source,
+		&spec.Notes,
+		&spec.BodyStyle,
+		&nf.confidence,
+		&spec.Authoritative,
+	}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return models.EVSpec{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+	nf.apply(&spec)
+	spec.Confidence = nf.storedConfidence(&spec)
+	return spec, nil
+}
+
+// ListSpecs retrieves the EV specs matching the filter, ordered by make, model
+// and year unless WithSort is given
+func (c *Client) ListSpecs(ctx context.Context, filter SpecFilter, opts ...ListOption) ([]models.EVSpec, error) {
+	return retryRead(ctx, c, func() ([]models.EVSpec, error) {
+		return c.listSpecsOnce(ctx, filter, opts...)
+	})
+}
+
+// listSpecsOnce runs one attempt of ListSpecs
+func (c *Client) listSpecsOnce(ctx context.Context, filter SpecFilter, opts ...ListOption) ([]models.EVSpec, error) {
+	var options listOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	where, args := filter.where()
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ev_specs
+		%s
+		ORDER BY %s
+	`, listedColumns, where, options.sort.orderBy("make, model, year"))
+	if options.limit > 0 {
+		args = append(args, options.limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if options.offset > 0 {
+		args = append(args, options.offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	var specs []models.EVSpec
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		spec, err := scanListed(rows)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return specs, nil
+}
+
+// EachSpec calls fn for every spec matching the filter, ordered by make,
+// model and year. Rows are decoded as they arrive from the server rather than
+// collected first, so whole tables can be streamed in constant memory. An
+// error from fn stops the iteration and is returned as is.
+func (c *Client) EachSpec(ctx context.Context, filter SpecFilter, fn func(models.EVSpec) error) error {
+	where, args := filter.where()
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ev_specs
+		%s
+		ORDER BY make, model, year
+	`, listedColumns, where)
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		spec, err := scanListed(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(spec); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// CapacityMatch is a spec found by FindByCapacity with its distance from the
+// target capacity
+type CapacityMatch struct {
+	models.EVSpec
+	Diff float64 `json:"capacity_diff_kwh"` // Absolute difference from the target in kWh
+}
+
+// FindByCapacity returns up to limit specs matching the filter whose capacity
+// is within tolerance kWh of target, closest first. WithSort reorders the
+// closest limit matches; of the options only WithSort applies.
+func (c *Client) FindByCapacity(ctx context.Context, target, tolerance float64, filter SpecFilter, limit int, opts ...ListOption) ([]CapacityMatch, error) {
+	return retryRead(ctx, c, func() ([]CapacityMatch, error) {
+		return c.findByCapacityOnce(ctx, target, tolerance, filter, limit, opts...)
+	})
+}
+
+// findByCapacityOnce runs one attempt of FindByCapacity
+func (c *Client) findByCapacityOnce(ctx context.Context, target, tolerance float64, filter SpecFilter, limit int, opts ...ListOption) ([]CapacityMatch, error) {
+	var options listOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	where, args := filter.where()
+	args = append(args, target-tolerance, target+tolerance, target, limit)
+	n := len(args)
+	condition := fmt.Sprintf("capacity_kwh BETWEEN $%d AND $%d", n-3, n-2)
+	if where == "" {
+		where = "WHERE " + condition
+	} else {
+		where += " AND " + condition
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, diff
+		FROM (
+			SELECT *, ABS(capacity_kwh - $%d) AS diff
+			FROM ev_specs
+			%s
+			ORDER BY diff, make, model, year
+			LIMIT $%d
+		) AS closest
+		ORDER BY %s
+	`, listedColumns, n-1, where, n, options.sort.orderBy("diff, make, model, year"))
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []CapacityMatch
+	for rows.Next() {
+		var diff float64
+		spec, err := scanListed(rows, &diff)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, CapacityMatch{EVSpec: spec, Diff: diff})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return matches, nil
+}
+
+// DuplicatePair is two stored specs whose embeddings are suspiciously similar
+type DuplicatePair struct {
+	First      models.EVSpec `json:"first"`
+	Second     models.EVSpec `json:"second"`
+	Similarity float64       `json:"similarity"` // Cosine similarity of the two embeddings
+}
+
+// FindDuplicates returns pairs of distinct rows whose embedding cosine
+// similarity is at least threshold, most similar first. Each row is compared
+// with its neighbors nearest rows via the vector index rather than with every
+// other row, so a pair is found as long as either row is among the other's
+// nearest neighbors.
+func (c *Client) FindDuplicates(ctx context.Context, threshold float64, neighbors int) ([]DuplicatePair, error) {
+	query := `
+		SELECT LEAST(a.id, n.id), GREATEST(a.id, n.id), MAX(1 - n.distance) AS similarity
+		FROM ev_specs a
+		CROSS JOIN LATERAL (
+			SELECT b.id, b.embedding <=> a.embedding AS distance
+			FROM ev_specs b
+			WHERE b.id <> a.id AND b.embedding IS NOT NULL
+			ORDER BY b.embedding <=> a.embedding
+			LIMIT $2
+		) n
+		WHERE a.embedding IS NOT NULL AND 1 - n.distance >= $1
+		GROUP BY 1, 2
+		ORDER BY similarity DESC, 1, 2
+	`
+
+	rows, err := c.pool.Query(ctx, query, threshold, neighbors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	type idPair struct {
+		first, second int
+		similarity    float64
+	}
+	var pairs []idPair
+	ids := map[int]bool{}
+	for rows.Next() {
+		var p idPair
+		if err := rows.Scan(&p.first, &p.second, &p.similarity); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pairs = append(pairs, p)
+		ids[p.first] = true
+		ids[p.second] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	specs, err := c.specsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]DuplicatePair, len(pairs))
+	for i, p := range pairs {
+		duplicates[i] = DuplicatePair{First: specs[p.first], Second: specs[p.second], Similarity: p.similarity}
+	}
+	return duplicates, nil
+}
+
+// specsByID loads the specs with the given row ids, keyed by id
+func (c *Client) specsByID(ctx context.Context, ids map[int]bool) (map[int]models.EVSpec, error) {
+	idList := make([]int, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+
+	query := fmt.Sprintf("SELECT %s, id FROM ev_specs WHERE id = ANY($1)", listedColumns)
+	rows, err := c.pool.Query(ctx, query, idList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	specs := make(map[int]models.EVSpec, len(idList))
+	for rows.Next() {
+		var id int
+		spec, err := scanListed(rows, &id)
+		if err != nil {
+			return nil, err
+		}
+		specs[id] = spec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return specs, nil
+}
+
+// CountSpecs returns the number of EV specs matching the filter
+func (c *Client) CountSpecs(ctx context.Context, filter SpecFilter) (int, error) {
+	where, args := filter.where()
+
+	var count int
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, "SELECT COUNT(*) FROM ev_specs "+where, args...).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count specs: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteWhere removes every EV spec matching the filter and returns the number
+// of rows deleted. An empty filter is rejected rather than clearing the table.
+func (c *Client) DeleteWhere(ctx context.Context, filter SpecFilter) (int64, error) {
+	where, args := filter.where()
+	if where == "" {
+		return 0, fmt.Errorf("refusing to delete without a filter")
+	}
+
+	tag, err := c.pool.Exec(ctx, "DELETE FROM ev_specs "+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete specs: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// TruncateSpecs removes every EV spec and resets the id sequence, keeping the
+// schema. Unlike DeleteWhere it is not recorded in the history table, since
+// row triggers do not fire on TRUNCATE.
+func (c *Client) TruncateSpecs(ctx context.Context) error {
+	if c.readOnly {
+		return ErrReadOnly
+	}
+	if _, err := c.pool.Exec(ctx, "TRUNCATE ev_specs RESTART IDENTITY"); err != nil {
+		return fmt.Errorf("failed to truncate specs: %w", err)
+	}
+	return nil
+}
+
+// GetHistory retrieves the superseded revisions of an EV spec, newest first
+func (c *Client) GetHistory(ctx context.Context, make, model string, year int) ([]models.SpecRevision, error) {
+	return retryRead(ctx, c, func() ([]models.SpecRevision, error) {
+		return c.getHistoryOnce(ctx, make, model, year)
+	})
+}
+
+// getHistoryOnce runs one attempt of GetHistory
+func (c *Client) getHistoryOnce(ctx context.Context, make, model string, year int) ([]models.SpecRevision, error) {
+	query := `
+		SELECT make, model, year, capacity_kwh, power_kw, chemistry, tags,
+			COALESCE(source, 'database'), COALESCE(raw_response, ''), operation, valid_from, superseded_at
+		FROM ev_specs_history
+		WHERE LOWER(make) = LOWER($1) AND LOWER(model) = LOWER($2) AND year = $3
+		ORDER BY superseded_at DESC, id DESC
+	`
+
+	rows, err := c.pool.Query(ctx, query, make, model, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []models.SpecRevision
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// scanRevision scans a history row selected by getHistoryOnce. History rows
+// are copies of ev_specs rows, so capacity, power and chemistry may be NULL.
+func scanRevision(rows pgx.Rows) (models.SpecRevision, error) {
+	var rev models.SpecRevision
+	var nf nullableFields
+	err := rows.Scan(
+		&rev.Make,
+		&rev.Model,
+		&rev.Year,
+		&nf.capacity,
+		&nf.power,
+		&nf.chemistry,
+		&rev.Tags,
+		&rev.Source,
+		&rev.RawResponse,
+		&rev.Operation,
+		&rev.ValidFrom,
+		&rev.SupersededAt,
+	)
+	if err != nil {
+		return models.SpecRevision{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+	nf.apply(&rev.EVSpec)
+	return rev, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// benchmarkVector returns an embedding of n dimensions with realistic values
+func benchmarkVector(n int) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = float32(i%97)/97 - 0.5
+	}
+	return v
+}
+
+// sprintfVector is the fmt.Sprintf join formatVector replaced, kept as the
+// baseline for BenchmarkFormatVector
+func sprintfVector(embedding []float32) string {
+	parts := make([]string, len(embedding))
+	for i, v := range embedding {
+		parts[i] = fmt.Sprintf("%g", v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func TestFormatVector(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []float32
+		want string
+	}{
+		{"empty", nil, "[]"},
+		{"single", []float32{1}, "[1]"},
+		{"mixed", []float32{1, -2.5, 0.125}, "[1,-2.5,0.125]"},
+		{"shortest float32 form", []float32{0.1, 1.0 / 3}, "[0.1,0.33333334]"},
+		{"exponent", []float32{3e-05, -1.2345678e-12, 6e+20}, "[3e-05,-1.2345678e-12,6e+20]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatVector(tt.in); got != tt.want {
+				t.Errorf("formatVector(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatVectorMatchesSprintf(t *testing.T) {
+	v := benchmarkVector(1536)
+	if got, want := formatVector(v), sprintfVector(v); got != want {
+		t.Errorf("formatVector differs from the fmt.Sprintf join:\n got %.80s...\nwant %.80s...", got, want)
+	}
+}
+
+func TestFormatVectorAllocations(t *testing.T) {
+	v := benchmarkVector(1536)
+	// One for the buffer and one for the returned string
+	if allocs := testing.AllocsPerRun(10, func() { formatVector(v) }); allocs > 2 {
+		t.Errorf("formatVector made %v allocations for 1536 dimensions, want at most 2", allocs)
+	}
+}
+
+func BenchmarkFormatVector(b *testing.B) {
+	for _, n := range []int{768, 1536} {
+		v := benchmarkVector(n)
+		b.Run(fmt.Sprintf("AppendFloat/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				formatVector(v)
+			}
+		})
+		b.Run(fmt.Sprintf("Sprintf/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				sprintfVector(v)
+			}
+		})
+	}
+}
+
+// fakeRow is a single pgx row whose Scan assigns values in column order, with
+// nil standing for SQL NULL
+type fakeRow struct {
+	pgx.Rows
+	values []any
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("scanned %d columns into %d targets", len(r.values), len(dest))
+	}
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(r.values[i]); err != nil {
+				return fmt.Errorf("column %d: %w", i, err)
+			}
+			continue
+		}
+		if r.values[i] == nil {
+			return fmt.Errorf("column %d: cannot scan NULL into %T", i, d)
+		}
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+func TestScanListedToleratesNullSpecColumns(t *testing.T) {
+	// make, model, year, capacity, power, chemistry, tags, source, notes,
+	// body style, confidence, authoritative
+	row := &fakeRow{values: []any{
+		"Nissan", "Leaf", 2019, nil, nil, nil, []string(nil), "import", "", "", nil, false,
+	}}
+	spec, err := scanListed(row)
+	if err != nil {
+		t.Fatalf("scanListed: %v", err)
+	}
+	if spec.Capacity != 0 || spec.Power != 0 || spec.Chemistry != "" {
+		t.Errorf("spec = %+v, want NULL capacity, power and chemistry as missing", spec)
+	}
+	if spec.Make != "Nissan" || spec.Model != "Leaf" || spec.Year != 2019 {
+		t.Errorf("spec = %+v, want the non-NULL columns kept", spec)
+	}
+	if spec.Confidence != 1.0 {
+		t.Errorf("confidence = %v, want 1.0 for a curated row without one", spec.Confidence)
+	}
+}
+
+func TestScanListedKeepsNonNullSpecColumns(t *testing.T) {
+	row := &fakeRow{values: []any{
+		"Nissan", "Leaf", 2019, 62.0, 160.0, "NMC", []string{"hatchback"}, "llm", "", "", nil, false,
+	}}
+	spec, err := scanListed(row)
+	if err != nil {
+		t.Fatalf("scanListed: %v", err)
+	}
+	if spec.Capacity != 62 || spec.Power != 160 || spec.Chemistry != "NMC" {
+		t.Errorf("spec = %+v, want the stored values", spec)
+	}
+	if spec.Confidence != models.LLMConfidenceScore {
+		t.Errorf("confidence = %v, want the LLM score for an llm row without one", spec.Confidence)
+	}
+}
+
+func TestStoredConfidence(t *testing.T) {
+	var supplied nullableFields
+	if err := supplied.confidence.Scan(0.42); err != nil {
+		t.Fatalf("failed to set confidence: %v", err)
+	}
+	tests := []struct {
+		name string
+		nf   nullableFields
+		spec models.EVSpec
+		want float64
+	}{
+		{"authoritative", supplied, models.EVSpec{Authoritative: true}, 1.0},
+		{"supplied", supplied, models.EVSpec{Source: "llm"}, 0.42},
+		{"llm without one", nullableFields{}, models.EVSpec{Source: "llm"}, models.LLMConfidenceScore},
+		{"curated without one", nullableFields{}, models.EVSpec{Source: "manual"}, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.nf.storedConfidence(&tt.spec); got != tt.want {
+				t.Errorf("storedConfidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRevisionToleratesNullSpecColumns(t *testing.T) {
+	validFrom := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	supersededAt := validFrom.Add(time.Hour)
+	// make, model, year, capacity, power, chemistry, tags, source, raw
+	// response, operation, valid from, superseded at
+	row := &fakeRow{values: []any{
+		"Nissan", "Leaf", 2019, nil, nil, nil, []string(nil), "import", "", "DELETE", &validFrom, supersededAt,
+	}}
+	rev, err := scanRevision(row)
+	if err != nil {
+		t.Fatalf("scanRevision: %v", err)
+	}
+	if rev.Capacity != 0 || rev.Power != 0 || rev.Chemistry != "" {
+		t.Errorf("revision = %+v, want NULL capacity, power and chemistry as missing", rev)
+	}
+	if rev.Make != "Nissan" || rev.Operation != "DELETE" || !rev.SupersededAt.Equal(supersededAt) {
+		t.Errorf("revision = %+v, want the non-NULL columns kept", rev)
+	}
+}
+
+func TestScanRevisionKeepsNonNullSpecColumns(t *testing.T) {
+	row := &fakeRow{values: []any{
+		"Nissan", "Leaf", 2019, 62.0, 160.0, "NMC", []string{"hatchback"}, "llm", "", "UPDATE", (*time.Time)(nil), time.Now(),
+	}}
+	rev, err := scanRevision(row)
+	if err != nil {
+		t.Fatalf("scanRevision: %v", err)
+	}
+	if rev.Capacity != 62 || rev.Power != 160 || rev.Chemistry != "NMC" || rev.ValidFrom != nil {
+		t.Errorf("revision = %+v, want the stored values", rev)
+	}
+}
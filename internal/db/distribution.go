@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DistributionField is a numeric spec column Distribution can bucket
+type DistributionField string
+
+const (
+	FieldCapacity DistributionField = "capacity"
+	FieldPower    DistributionField = "power"
+	FieldYear     DistributionField = "year"
+)
+
+// ParseDistributionField converts "capacity", "power" or "year" into a
+// DistributionField
+func ParseDistributionField(name string) (DistributionField, error) {
+	switch f := DistributionField(strings.ToLower(name)); f {
+	case FieldCapacity, FieldPower, FieldYear:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid field: %s (use capacity, power or year)", name)
+	}
+}
+
+// column returns the ev_specs column holding the field
+func (f DistributionField) column() string {
+	switch f {
+	case FieldPower:
+		return "power_kw"
+	case FieldYear:
+		return "year"
+	default:
+		return "capacity_kwh"
+	}
+}
+
+// Bucket is one bucket of a Distribution, counting values in [Low, High). The
+// last bucket of capacity and power also includes High.
+type Bucket struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int     `json:"count"`
+}
+
+// Distribution is a histogram of one field across the stored specs
+type Distribution struct {
+	Field   DistributionField `json:"field"`
+	Total   int               `json:"total"`   // Specs with a value, i.e. the sum of the counts
+	Missing int               `json:"missing"` // Matching specs without a value
+	Min     float64           `json:"min"`
+	Max     float64           `json:"max"`
+	Buckets []Bucket          `json:"buckets"`
+}
+
+// Distribution buckets field across the specs matching filter into buckets
+// equal-width ranges from the smallest to the largest value, using
+// width_bucket. Years are bucketed by whole years, so there are never more
+// buckets than years. Missing capacities and powers are counted separately.
+func (c *Client) Distribution(ctx context.Context, field DistributionField, buckets int, filter SpecFilter) (*Distribution, error) {
+	if buckets < 1 {
+		return nil, fmt.Errorf("buckets must be positive")
+	}
+
+	column := field.column()
+	base, args := filter.where()
+	where := fmt.Sprintf("WHERE %s > 0", column)
+	if base != "" {
+		where = fmt.Sprintf("%s AND %s > 0", base, column)
+	}
+
+	dist := &Distribution{Field: field, Buckets: []Bucket{}}
+	var low, high *float64
+	var all int
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, fmt.Sprintf(`
+			SELECT (MIN(%[1]s) FILTER (WHERE %[1]s > 0))::float8,
+				(MAX(%[1]s) FILTER (WHERE %[1]s > 0))::float8,
+				COUNT(*) FILTER (WHERE %[1]s > 0),
+				COUNT(*)
+			FROM ev_specs
+			%[2]s
+		`, column, base), args...).Scan(&low, &high, &dist.Total, &all)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s range: %w", field, err)
+	}
+	dist.Missing = all - dist.Total
+	if dist.Total == 0 {
+		return dist, nil
+	}
+	dist.Min, dist.Max = *low, *high
+
+	// Years are whole numbers, so each bucket spans a whole number of years
+	// and the upper bound is exclusive
+	lowBound, highBound, width := dist.Min, dist.Max, (dist.Max-dist.Min)/float64(buckets)
+	if field == FieldYear {
+		years := int(dist.Max-dist.Min) + 1
+		buckets = min(buckets, years)
+		width = float64((years + buckets - 1) / buckets)
+		buckets = (years + int(width) - 1) / int(width)
+		highBound = lowBound + width*float64(buckets)
+	}
+	if width == 0 {
+		// Every value is the same: one bucket holding them all
+		dist.Buckets = append(dist.Buckets, Bucket{Low: dist.Min, High: dist.Max, Count: dist.Total})
+		return dist, nil
+	}
+
+	counts, err := retryRead(ctx, c, func() (map[int]int, error) {
+		n := len(args)
+		query := fmt.Sprintf(`
+			SELECT LEAST(width_bucket(%s::float8, $%d, $%d, $%d), $%d) AS bucket, COUNT(*)
+			FROM ev_specs
+			%s
+			GROUP BY bucket
+		`, column, n+1, n+2, n+3, n+3, where)
+		rows, err := c.pool.Query(ctx, query, append(args, lowBound, highBound, buckets)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query database: %w", err)
+		}
+		defer rows.Close()
+
+		counts := make(map[int]int)
+		for rows.Next() {
+			var bucket, count int
+			if err := rows.Scan(&bucket, &count); err != nil {
+				return nil, fmt.Errorf("failed to scan row: %w", err)
+			}
+			counts[bucket] = count
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating rows: %w", err)
+		}
+		return counts, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i <= buckets; i++ {
+		dist.Buckets = append(dist.Buckets, Bucket{
+			Low:   lowBound + width*float64(i-1),
+			High:  lowBound + width*float64(i),
+			Count: counts[i],
+		})
+	}
+	return dist, nil
+}
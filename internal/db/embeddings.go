@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// SpecEmbedding is a stored spec with one of its embeddings
+type SpecEmbedding struct {
+	Spec      models.EVSpec
+	Embedding []float32
+}
+
+// SpecEmbeddings retrieves the specs matching filter that have an embedding
+// in column, with that embedding, ordered by make, model and year. At most
+// limit rows are returned; 0 means no limit.
+func (c *Client) SpecEmbeddings(ctx context.Context, filter SpecFilter, column EmbeddingColumn, limit int) ([]SpecEmbedding, error) {
+	if column != ColumnPrimary && column != ColumnAlt {
+		return nil, fmt.Errorf("invalid embedding column: %s", column)
+	}
+	return retryRead(ctx, c, func() ([]SpecEmbedding, error) {
+		return c.specEmbeddingsOnce(ctx, filter, column, limit)
+	})
+}
+
+// specEmbeddingsOnce runs one attempt of SpecEmbeddings
+func (c *Client) specEmbeddingsOnce(ctx context.Context, filter SpecFilter, column EmbeddingColumn, limit int) ([]SpecEmbedding, error) {
+	where, args := filter.where()
+	if where == "" {
+		where = "WHERE "
+	} else {
+		where += " AND "
+	}
+	query := fmt.Sprintf(`SELECT %s, %s::text FROM ev_specs %s%s IS NOT NULL ORDER BY make, model, year`, listedColumns, column, where, column)
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SpecEmbedding
+	for rows.Next() {
+		var text string
+		spec, err := scanListed(rows, &text)
+		if err != nil {
+			return nil, err
+		}
+		embedding, err := parseVector(text)
+		if err != nil {
+			return nil, fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, err)
+		}
+		result = append(result, SpecEmbedding{Spec: spec, Embedding: embedding})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return result, nil
+}
+
+// parseVector parses a pgvector text literal such as [1,2.5,3], the inverse
+// of formatVector
+func parseVector(text string) ([]float32, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return nil, fmt.Errorf("invalid vector: %q", text)
+	}
+	inner := trimmed[1 : len(trimmed)-1]
+	if strings.TrimSpace(inner) == "" {
+		return []float32{}, nil
+	}
+
+	parts := strings.Split(inner, ",")
+	vector := make([]float32, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector element %q: %w", part, err)
+		}
+		vector[i] = float32(v)
+	}
+	return vector, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseVectorRoundTrip(t *testing.T) {
+	want := []float32{1, -2.5, 0.125, 3e-5}
+	got, err := parseVector(formatVector(want))
+	if err != nil {
+		t.Fatalf("parseVector: %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseVector(formatVector(%v)) = %v", want, got)
+	}
+}
+
+func TestParseVectorRejectsMalformedInput(t *testing.T) {
+	for _, text := range []string{"", "1,2,3", "[1,2", "1,2]", "[1,x,3]"} {
+		if _, err := parseVector(text); err == nil {
+			t.Errorf("parseVector(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: CosineSimilarity = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkCosineSimilarity(b *testing.B) {
+	x, y := benchmarkVector(1536), benchmarkVector(1536)
+	y[0] = 1
+	for b.Loop() {
+		CosineSimilarity(x, y)
+	}
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrAuthoritative is returned when a write would overwrite an authoritative spec
+var ErrAuthoritative = errors.New("stored spec is authoritative")
+
+// ErrReadOnly is returned by operations refused on a client opened WithReadOnly
+var ErrReadOnly = errors.New("database client is read-only")
+
+// ErrSpecExists is returned when an insert that must not overwrite finds the
+// spec already stored
+var ErrSpecExists = errors.New("spec already exists")
+
+// uniqueViolation is the Postgres SQLSTATE for unique_violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// ErrDimensionMismatch is matched by errors.Is for any *DimensionMismatchError
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// DimensionMismatchError reports an embedding whose length does not match the
+// ev_specs.embedding column
+type DimensionMismatchError struct {
+	Expected int // dimension of the embedding column
+	Actual   int // dimension of the embedding that was sent
+}
+
+func (e *DimensionMismatchError) Error() string {
+	return fmt.Sprintf("embedding has %d dimensions but the ev_specs.embedding column expects %d; run 'ev-oracle init' against an empty table or use a matching embedding model", e.Actual, e.Expected)
+}
+
+// Is reports whether target is ErrDimensionMismatch
+func (e *DimensionMismatchError) Is(target error) bool {
+	return target == ErrDimensionMismatch
+}
+
+// pgvector raises these on insert and on distance operators respectively
+var (
+	expectedDimensionsPattern  = regexp.MustCompile(`expected (\d+) dimensions, not (\d+)`)
+	differentDimensionsPattern = regexp.MustCompile(`different vector dimensions (\d+) and (\d+)`)
+)
+
+// asDimensionMismatch converts a pgvector dimension error into a
+// *DimensionMismatchError, returning nil for any other error. Both patterns
+// list the column dimension first because queries put the column on the left
+// of the distance operator.
+func asDimensionMismatch(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	for _, pattern := range []*regexp.Regexp{expectedDimensionsPattern, differentDimensionsPattern} {
+		match := pattern.FindStringSubmatch(pgErr.Message)
+		if match == nil {
+			continue
+		}
+		expected, _ := strconv.Atoi(match[1])
+		actual, _ := strconv.Atoi(match[2])
+		return &DimensionMismatchError{Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// IsTransient reports whether err is a connection-level failure, timeout,
+// serialization failure or deadlock that may succeed if the statement is
+// retried. Constraint and data errors are never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection_exception; 57P01 admin_shutdown
+		return strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "40001" || pgErr.Code == "40P01" || pgErr.Code == "57P01"
+	}
+	var connErr *pgconn.ConnectError
+	if pgconn.SafeToRetry(err) || pgconn.Timeout(err) || errors.As(err, &connErr) {
+		return true
+	}
+	// A connection dropped mid-statement surfaces as a socket error rather
+	// than a Postgres one, and pgx discards the connection
+	var netErr net.Error
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.As(err, &netErr)
+}
+
+// ErrDirty is matched by errors.Is for any *DirtyError
+var ErrDirty = errors.New("database is dirty")
+
+// DirtyError reports a database left dirty by a migration that failed midway.
+// golang-migrate refuses to run further migrations until the version is
+// forced.
+type DirtyError struct {
+	Version int // version of the migration that failed
+	Err     error
+}
+
+func (e *DirtyError) Error() string {
+	msg := fmt.Sprintf("database is dirty at migration version %d after a failed migration; inspect the schema, then run 'ev-oracle migrate force %d' if the migration took effect or 'ev-oracle migrate force %d' if it did not, and migrate again",
+		e.Version, e.Version, e.Version-1)
+	// golang-migrate's own ErrDirty only repeats the version
+	var dirty migrate.ErrDirty
+	if e.Err != nil && !errors.As(e.Err, &dirty) {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *DirtyError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDirty
+func (e *DirtyError) Is(target error) bool {
+	return target == ErrDirty
+}
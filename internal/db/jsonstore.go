@@ -0,0 +1,392 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// JSONStore is a SpecStore kept in a local JSON file, for trying the tool
+// without Postgres. Similarity search is a brute-force cosine scan in Go, so
+// it suits a few thousand specs rather than a full catalog.
+type JSONStore struct {
+	path string
+
+	mu      sync.RWMutex
+	records []jsonRecord
+}
+
+// jsonRecord is one spec as stored in the JSON file
+type jsonRecord struct {
+	Make          string    `json:"make"`
+	Model         string    `json:"model"`
+	Year          int       `json:"year"`
+	Capacity      float64   `json:"capacity_kwh,omitempty"`
+	Power         float64   `json:"power_kw,omitempty"`
+	Chemistry     string    `json:"chemistry,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Source        string    `json:"source"`
+	RawResponse   string    `json:"raw_response,omitempty"`
+	Authoritative bool      `json:"authoritative,omitempty"`
+	Notes         string    `json:"notes,omitempty"`
+	BodyStyle     string    `json:"body_style,omitempty"`
+	Confidence    float64   `json:"confidence,omitempty"`
+	Embedding     []float32 `json:"embedding,omitempty"`
+	EmbeddingAlt  []float32 `json:"embedding_alt,omitempty"`
+}
+
+// spec converts the record into an EVSpec with its stored confidence
+func (r *jsonRecord) spec() models.EVSpec {
+	spec := models.EVSpec{
+		Make:          r.Make,
+		Model:         r.Model,
+		Year:          r.Year,
+		Capacity:      r.Capacity,
+		Power:         r.Power,
+		Chemistry:     r.Chemistry,
+		Tags:          slices.Clone(r.Tags),
+		Source:        r.Source,
+		Authoritative: r.Authoritative,
+		Notes:         r.Notes,
+		BodyStyle:     r.BodyStyle,
+	}
+	nf := nullableFields{}
+	nf.confidence.Float64, nf.confidence.Valid = r.Confidence, r.Confidence != 0
+	spec.Confidence = nf.storedConfidence(&spec)
+	return spec
+}
+
+// matches reports whether the record is the spec for make, model and year,
+// comparing match keys as the Postgres store does
+func (r *jsonRecord) matches(make, model string, year int) bool {
+	return r.Year == year && models.MatchKey(r.Make, r.Model, r.Year) == models.MatchKey(make, model, year)
+}
+
+// embedding returns the record's embedding in column
+func (r *jsonRecord) embedding(column EmbeddingColumn) []float32 {
+	if column == ColumnAlt {
+		return r.EmbeddingAlt
+	}
+	return r.Embedding
+}
+
+// OpenJSONStore loads the specs stored at path. A missing file is an empty
+// store; it is created on the first write.
+func OpenJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("failed to parse spec store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Close is a no-op; every write is saved immediately
+func (s *JSONStore) Close() {}
+
+// save writes the records to a temporary file and renames it over the store,
+// so an interrupted write never leaves a truncated file. Callers hold s.mu.
+func (s *JSONStore) save() error {
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode spec store: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write spec store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write spec store: %w", err)
+	}
+	return nil
+}
+
+// find returns the index of the record for make, model and year, or -1.
+// Callers hold s.mu.
+func (s *JSONStore) find(make, model string, year int) int {
+	return slices.IndexFunc(s.records, func(r jsonRecord) bool {
+		return r.matches(make, model, year)
+	})
+}
+
+// GetByMakeModelYear retrieves an EV spec by exact make, model, and year
+func (s *JSONStore) GetByMakeModelYear(ctx context.Context, make, model string, year int) (*models.EVSpec, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.find(make, model, year)
+	if i < 0 {
+		return nil, nil // Not found
+	}
+	spec := s.records[i].spec()
+	spec.RawResponse = s.records[i].RawResponse
+	return &spec, nil
+}
+
+// SimilaritySearch returns the limit specs whose embedding in the searched
+// column is closest to embedding by cosine similarity. Every search is an
+// exact scan, so WithExactScan has no effect.
+func (s *JSONStore) SimilaritySearch(ctx context.Context, embedding []float32, limit int, opts ...SearchOption) ([]models.EVSpec, error) {
+	options := searchOptions{column: ColumnPrimary}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.column != ColumnPrimary && options.column != ColumnAlt {
+		return nil, fmt.Errorf("invalid embedding column: %s", options.column)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var specs []models.EVSpec
+	for i := range s.records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		stored := s.records[i].embedding(options.column)
+		if len(stored) == 0 {
+			continue
+		}
+		if len(stored) != len(embedding) {
+			return nil, &DimensionMismatchError{Expected: len(stored), Actual: len(embedding)}
+		}
+
+		spec := s.records[i].spec()
+		spec.Source = "database"
+		spec.Confidence = CosineSimilarity(stored, embedding)
+		specs = append(specs, spec)
+	}
+
+	slices.SortStableFunc(specs, func(a, b models.EVSpec) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	if len(specs) > limit {
+		specs = specs[:limit]
+	}
+	return boostRecency(specs, options), nil
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, matching
+// 1 - (a <=> b) in pgvector. It is 0 when either vector is all zeros.
+func CosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// InsertEVSpec stores the spec with its embedding, following the same
+// conflict rules as Client.InsertEVSpec. Every stored primary embedding must
+// have the same dimension.
+func (s *JSONStore) InsertEVSpec(ctx context.Context, spec *models.EVSpec, embedding []float32, opts ...InsertOption) error {
+	var options insertOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.records {
+		if stored := s.records[i].Embedding; len(stored) > 0 && len(stored) != len(embedding) {
+			return &DimensionMismatchError{Expected: len(stored), Actual: len(embedding)}
+		}
+	}
+
+	source := spec.Source
+	if source == "" {
+		source = "database"
+	}
+	record := jsonRecord{
+		Make:          spec.Make,
+		Model:         spec.Model,
+		Year:          spec.Year,
+		Capacity:      spec.Capacity,
+		Power:         spec.Power,
+		Chemistry:     spec.Chemistry,
+		Tags:          slices.Clone(spec.Tags),
+		Source:        source,
+		Authoritative: spec.Authoritative,
+		Notes:         spec.Notes,
+		BodyStyle:     spec.BodyStyle,
+		Confidence:    spec.Confidence,
+		Embedding:     slices.Clone(embedding),
+	}
+	// Raw responses are only meaningful for LLM-derived specs
+	if source == "llm" {
+		record.RawResponse = spec.RawResponse
+	}
+
+	i := s.find(spec.Make, spec.Model, spec.Year)
+	switch {
+	case i < 0:
+		s.records = append(s.records, record)
+	case options.conflict == ConflictSkip || options.conflict == ConflictError:
+		return fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, ErrSpecExists)
+	case s.records[i].Authoritative && !spec.Authoritative:
+		return fmt.Errorf("%d %s %s: %w", spec.Year, spec.Make, spec.Model, ErrAuthoritative)
+	default:
+		// Like the Postgres upsert, the secondary embedding survives
+		record.EmbeddingAlt = s.records[i].EmbeddingAlt
+		s.records[i] = record
+	}
+
+	return s.save()
+}
+
+// UpdateSpecFields overwrites the capacity, power, chemistry and source of the
+// stored spec with the same make, model and year, leaving its embedding and
+// tags untouched. Authoritative specs are never updated.
+func (s *JSONStore) UpdateSpecFields(ctx context.Context, spec *models.EVSpec, opts ...UpdateOption) error {
+	var options updateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(spec.Make, spec.Model, spec.Year)
+	if i < 0 || s.records[i].Authoritative {
+		return fmt.Errorf("no stored non-authoritative spec for %d %s %s", spec.Year, spec.Make, spec.Model)
+	}
+
+	r := &s.records[i]
+	r.Capacity, r.Power, r.Chemistry, r.Source = spec.Capacity, spec.Power, spec.Chemistry, spec.Source
+	if options.confidence {
+		r.Confidence = spec.Confidence
+	}
+	return s.save()
+}
+
+// ListSpecs returns the stored specs matching the filter, ordered by make,
+// model and year unless WithSort is given
+func (s *JSONStore) ListSpecs(ctx context.Context, filter SpecFilter, opts ...ListOption) ([]models.EVSpec, error) {
+	var options listOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s.mu.RLock()
+	var specs []models.EVSpec
+	for i := range s.records {
+		if filter.matches(&s.records[i]) {
+			specs = append(specs, s.records[i].spec())
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(specs, options.sort.compare)
+
+	specs = specs[min(options.offset, len(specs)):]
+	if options.limit > 0 && len(specs) > options.limit {
+		specs = specs[:options.limit]
+	}
+	return specs, nil
+}
+
+// matches applies the filter to a JSON store record, mirroring where
+func (f SpecFilter) matches(r *jsonRecord) bool {
+	if f.Make != "" && !strings.EqualFold(r.Make, f.Make) {
+		return false
+	}
+	if len(f.Makes) > 0 && !slices.ContainsFunc(f.Makes, func(m string) bool { return strings.EqualFold(r.Make, m) }) {
+		return false
+	}
+	if f.Year != 0 && r.Year != f.Year {
+		return false
+	}
+	if f.Chemistry != "" && !strings.EqualFold(r.Chemistry, f.Chemistry) {
+		return false
+	}
+	if f.Source != "" && r.Source != f.Source {
+		return false
+	}
+	if f.BodyStyle != "" && !strings.EqualFold(r.BodyStyle, f.BodyStyle) {
+		return false
+	}
+	if len(f.Tags) > 0 {
+		has := func(tag string) bool { return slices.Contains(r.Tags, tag) }
+		if f.MatchAnyTag && !slices.ContainsFunc(f.Tags, has) {
+			return false
+		}
+		if !f.MatchAnyTag && slices.ContainsFunc(f.Tags, func(tag string) bool { return !has(tag) }) {
+			return false
+		}
+	}
+	if (f.MissingEmbedding == ColumnPrimary || f.MissingEmbedding == ColumnAlt) && len(r.embedding(f.MissingEmbedding)) > 0 {
+		return false
+	}
+	return true
+}
+
+// compare orders two specs like orderBy: by the sort field with missing
+// values last, then by make, model and year
+func (s Sort) compare(a, b models.EVSpec) int {
+	if c := s.compareField(a, b); c != 0 {
+		return c
+	}
+	return cmp.Or(
+		cmp.Compare(a.Make, b.Make),
+		cmp.Compare(a.Model, b.Model),
+		cmp.Compare(a.Year, b.Year),
+	)
+}
+
+// compareField compares the sort field alone, 0 for the zero Sort
+func (s Sort) compareField(a, b models.EVSpec) int {
+	var c int
+	switch s.Field {
+	case "make":
+		c = cmp.Compare(a.Make, b.Make)
+	case "model":
+		c = cmp.Compare(a.Model, b.Model)
+	case "year":
+		c = cmp.Compare(a.Year, b.Year)
+	case "capacity", "power":
+		x, y := a.Capacity, b.Capacity
+		if s.Field == "power" {
+			x, y = a.Power, b.Power
+		}
+		// Missing values sort last in either direction (NULLS LAST)
+		switch {
+		case x == 0 && y == 0:
+			return 0
+		case x == 0:
+			return 1
+		case y == 0:
+			return -1
+		}
+		c = cmp.Compare(x, y)
+	default:
+		return 0
+	}
+	if s.Desc {
+		c = -c
+	}
+	return c
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func BenchmarkJSONStoreSimilaritySearch(b *testing.B) {
+	ctx := context.Background()
+	store, err := OpenJSONStore(filepath.Join(b.TempDir(), "specs.json"))
+	if err != nil {
+		b.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	const rows, dimensions = 500, 768
+	for i := range rows {
+		spec := &models.EVSpec{Make: "Make", Model: fmt.Sprintf("Model %d", i), Year: 2020 + i%5, Capacity: 75}
+		embedding := benchmarkVector(dimensions)
+		embedding[i%dimensions] += 1
+		if err := store.InsertEVSpec(ctx, spec, embedding); err != nil {
+			b.Fatalf("failed to seed row %d: %v", i, err)
+		}
+	}
+	query := benchmarkVector(dimensions)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := store.SimilaritySearch(ctx, query, 5); err != nil {
+			b.Fatalf("SimilaritySearch: %v", err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PlannedMigration is one migration PlanMigrations would run
+type PlannedMigration struct {
+	Version   uint   `json:"version"`
+	Name      string `json:"name"` // e.g. "add_settings"
+	Direction string `json:"direction"`
+}
+
+// PlanMigrations returns the migrations MigrateSteps(ctx, n) would run, in
+// order, without applying any: positive n plans up to n migrations forward,
+// negative n rolls back up to -n, and 0 plans every pending migration as
+// MigrateUp does. The applied version is read from schema_migrations over the
+// pool, so planning also works with WithReadOnly. A dirty database returns a
+// *DirtyError, as migrating would.
+func (c *Client) PlanMigrations(ctx context.Context, n int) ([]PlannedMigration, error) {
+	current, applied, err := c.appliedMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceURL, err := migrationsSourceURL()
+	if err != nil {
+		return nil, err
+	}
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer src.Close()
+
+	var plan []PlannedMigration
+	if n < 0 {
+		// Roll back from the applied version towards the first migration
+		version := current
+		for ok := applied; ok && len(plan) < -n; {
+			plan = append(plan, PlannedMigration{Version: version, Name: migrationName(src, version), Direction: "down"})
+			version, err = src.Prev(version)
+			if ok, err = nextExists(err); err != nil {
+				return nil, err
+			}
+		}
+		return plan, nil
+	}
+
+	var version uint
+	if applied {
+		version, err = src.Next(current)
+	} else {
+		version, err = src.First()
+	}
+	for {
+		ok, err := nextExists(err)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || (n > 0 && len(plan) == n) {
+			return plan, nil
+		}
+		plan = append(plan, PlannedMigration{Version: version, Name: migrationName(src, version), Direction: "up"})
+		version, err = src.Next(version)
+	}
+}
+
+// appliedMigration reads the applied migration version, reporting false when
+// none is applied or the schema_migrations table does not exist yet
+func (c *Client) appliedMigration(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	})
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), errors.As(err, &pgErr) && pgErr.Code == "42P01":
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	case dirty:
+		return 0, false, &DirtyError{Version: int(version)}
+	case version < 0:
+		return 0, false, nil
+	}
+	return uint(version), true, nil
+}
+
+// nextExists converts the error of a source Next, Prev or First call into
+// whether a migration was found
+func nextExists(err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to read migrations: %w", err)
+}
+
+// migrationName returns the identifier of a migration, e.g. "add_settings"
+func migrationName(src source.Driver, version uint) string {
+	r, identifier, err := src.ReadUp(version)
+	if err != nil {
+		return ""
+	}
+	r.Close()
+	return identifier
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// WithRecencyBoost reorders the nearest matches to prefer specs close to
+// year. Each match is scored by its cosine distance plus weight per model
+// year away from year:
+//
+//	score = (1 - confidence) + weight*|spec year - year|
+//
+// Lower scores come first, and equal scores put the newer spec first. Only
+// the matches within the search limit are reordered, and confidence keeps
+// its plain similarity value. A weight of 0 leaves the order unchanged.
+func WithRecencyBoost(year int, weight float64) SearchOption {
+	return func(o *searchOptions) {
+		o.recencyYear = year
+		o.recencyWeight = weight
+	}
+}
+
+// boostRecency reorders specs as described by WithRecencyBoost
+func boostRecency(specs []models.EVSpec, o searchOptions) []models.EVSpec {
+	if o.recencyWeight <= 0 {
+		return specs
+	}
+
+	score := func(spec models.EVSpec) float64 {
+		years := spec.Year - o.recencyYear
+		if years < 0 {
+			years = -years
+		}
+		return 1 - spec.Confidence + o.recencyWeight*float64(years)
+	}
+	slices.SortStableFunc(specs, func(a, b models.EVSpec) int {
+		if c := cmp.Compare(score(a), score(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Year, a.Year)
+	})
+	return specs
+}
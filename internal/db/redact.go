@@ -0,0 +1,87 @@
+package db
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedPassword replaces passwords in redacted error messages
+const redactedPassword = "xxxxx"
+
+// minSecretLength is the shortest password replaced wherever it appears in a
+// message; shorter ones would match inside ordinary words, so they are only
+// removed where they appear in a connection string
+const minSecretLength = 4
+
+var (
+	// urlPasswordPattern matches the password of any user:password@ URL, up to
+	// the last @ since an unescaped @ may be part of the password
+	urlPasswordPattern = regexp.MustCompile(`(://[^:/@\s]*:)\S*@`)
+	// dsnPasswordPattern matches password=value in a keyword/value string,
+	// with the value optionally single-quoted
+	dsnPasswordPattern = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+)
+
+// redactedError is an error whose message has had credentials removed. It
+// still unwraps to the original so errors.Is and errors.As keep working.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactError returns err with the password of databaseURL, and any other
+// password embedded in a connection string, removed from its message. pgx and
+// golang-migrate may quote the connection string in their errors, and these
+// errors end up on the terminal and in logs.
+func redactError(err error, databaseURL string) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	redacted := urlPasswordPattern.ReplaceAllString(msg, "${1}"+redactedPassword+"@")
+	redacted = dsnPasswordPattern.ReplaceAllString(redacted, "${1}"+redactedPassword)
+	for _, secret := range connectionSecrets(databaseURL) {
+		redacted = strings.ReplaceAll(redacted, secret, redactedPassword)
+	}
+
+	if redacted == msg {
+		return err
+	}
+	return &redactedError{msg: redacted, err: err}
+}
+
+// connectionSecrets returns the password of a URL or keyword/value connection
+// string in every form it may be quoted in: raw, percent-decoded and quoted
+func connectionSecrets(databaseURL string) []string {
+	var secrets []string
+	add := func(s string) {
+		if len(s) >= minSecretLength && s != redactedPassword {
+			secrets = append(secrets, s)
+		}
+	}
+
+	if u, err := url.Parse(databaseURL); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			add(password)
+			add(url.QueryEscape(password))
+			add(url.PathEscape(password))
+		}
+	}
+	if match := urlPasswordPattern.FindStringSubmatch(databaseURL); match != nil {
+		password := strings.TrimSuffix(strings.TrimPrefix(match[0], match[1]), "@")
+		add(password)
+		if decoded, err := url.PathUnescape(password); err == nil {
+			add(decoded)
+		}
+	}
+	if match := dsnPasswordPattern.FindStringSubmatch(databaseURL); match != nil {
+		add(strings.Trim(match[2], "'"))
+	}
+	return secrets
+}
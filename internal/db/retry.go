@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// defaultQueryAttempts is how many times a read query is tried by default
+const defaultQueryAttempts = 3
+
+// queryRetryDelay is the wait before the first retry of a read, doubled for
+// each later one
+const queryRetryDelay = 100 * time.Millisecond
+
+// WithQueryAttempts sets how many times read queries are tried when they fail
+// with a transient error such as a dropped connection. The default is 3; 1
+// disables retries. Writes are never retried by the client.
+func WithQueryAttempts(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.queryAttempts = n
+		}
+	}
+}
+
+// retryRead runs read, retrying it while it fails with a transient error (see
+// IsTransient). Constraint violations and other errors are returned at once,
+// and so is the last error when ctx is done. read must have no side effects
+// beyond its result, since a retried read starts over.
+func retryRead[T any](ctx context.Context, c *Client, read func() (T, error)) (T, error) {
+	delay := queryRetryDelay
+	for attempt := 1; ; attempt++ {
+		result, err := read()
+		if err == nil || attempt >= c.queryAttempts || !IsTransient(err) {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryScan is retryRead for reads that only scan into variables
+func (c *Client) retryScan(ctx context.Context, scan func() error) error {
+	_, err := retryRead(ctx, c, func() (struct{}, error) {
+		return struct{}{}, scan()
+	})
+	return err
+}
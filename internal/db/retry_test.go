@@ -0,0 +1,341 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// fakePostgres is a minimal Postgres server answering every statement with
+// the same text-format result, by default a single bigint count of 7. It
+// drops the connection instead of answering the first drops statements, as a
+// server going away mid-query would.
+type fakePostgres struct {
+	listener net.Listener
+	drops    int
+	fields   []pgproto3.FieldDescription
+	rows     [][][]byte // nil values are NULL
+
+	mu         sync.Mutex
+	statements []string // every statement parsed, including dropped ones
+}
+
+func newFakePostgres(t *testing.T, drops int) *fakePostgres {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakePostgres{
+		listener: listener,
+		drops:    drops,
+		fields:   []pgproto3.FieldDescription{{Name: []byte("count"), DataTypeOID: 20, DataTypeSize: 8, TypeModifier: -1}},
+		rows:     [][][]byte{{[]byte("7")}},
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// client returns a Client connected to the server through a real pool
+func (s *fakePostgres) client(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(context.Background(), fmt.Sprintf("postgres://ev:secret@%s/ev?sslmode=disable", s.listener.Addr()), opts...)
+	if err != nil {
+		t.Fatalf("failed to connect to the fake server: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Statements returns the statements parsed so far
+func (s *fakePostgres) Statements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.statements...)
+}
+
+func (s *fakePostgres) serve(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+
+	startup, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+	if _, ok := startup.(*pgproto3.SSLRequest); ok {
+		conn.Write([]byte("N"))
+		if _, err := backend.ReceiveStartupMessage(); err != nil {
+			return
+		}
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: "16.0"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if backend.Flush() != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			// pgxpool's ping
+			backend.Send(&pgproto3.EmptyQueryResponse{})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		case *pgproto3.Parse:
+			s.mu.Lock()
+			s.statements = append(s.statements, msg.Query)
+			drop := len(s.statements) <= s.drops
+			s.mu.Unlock()
+			if drop {
+				return
+			}
+			backend.Send(&pgproto3.ParseComplete{})
+		case *pgproto3.Describe:
+			if msg.ObjectType == 'S' {
+				backend.Send(&pgproto3.ParameterDescription{})
+			}
+			// Results are described, and so sent, in text format
+			backend.Send(&pgproto3.RowDescription{Fields: s.fields})
+		case *pgproto3.Bind:
+			backend.Send(&pgproto3.BindComplete{})
+		case *pgproto3.Execute:
+			for _, row := range s.rows {
+				backend.Send(&pgproto3.DataRow{Values: row})
+			}
+			backend.Send(&pgproto3.CommandComplete{CommandTag: fmt.Appendf(nil, "SELECT %d", len(s.rows))})
+		case *pgproto3.Sync:
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		case *pgproto3.Terminate:
+			return
+		}
+		if backend.Flush() != nil {
+			return
+		}
+	}
+}
+
+func TestReadRetriesDroppedConnection(t *testing.T) {
+	server := newFakePostgres(t, 1)
+	c := server.client(t)
+
+	count, err := c.CountSpecs(context.Background(), SpecFilter{})
+	if err != nil {
+		t.Fatalf("CountSpecs failed after a dropped connection: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("count = %d, want 7", count)
+	}
+	if got := len(server.Statements()); got != 2 {
+		t.Errorf("statements sent = %d, want 2 (the dropped one and its retry)", got)
+	}
+}
+
+func TestReadGivesUpAfterQueryAttempts(t *testing.T) {
+	server := newFakePostgres(t, 100)
+	c := server.client(t, WithQueryAttempts(2))
+
+	_, err := c.CountSpecs(context.Background(), SpecFilter{})
+	if err == nil {
+		t.Fatal("CountSpecs succeeded although every attempt was dropped")
+	}
+	if !IsTransient(err) {
+		t.Errorf("error %v is not transient", err)
+	}
+	if got := len(server.Statements()); got != 2 {
+		t.Errorf("statements sent = %d, want 2", got)
+	}
+}
+
+func TestWriteIsNotRetried(t *testing.T) {
+	server := newFakePostgres(t, 1)
+	c := server.client(t)
+
+	if _, err := c.DeleteWhere(context.Background(), SpecFilter{Make: "Tesla"}); err == nil {
+		t.Fatal("DeleteWhere succeeded although its connection was dropped")
+	}
+	statements := server.Statements()
+	if len(statements) != 1 || !strings.HasPrefix(statements[0], "DELETE") {
+		t.Errorf("statements sent = %q, want the DELETE once", statements)
+	}
+}
+
+// retryClient returns a Client for retryRead tests; it has no pool
+func retryClient(attempts int) *Client {
+	return &Client{queryAttempts: attempts}
+}
+
+func TestRetryReadRetriesTransientErrors(t *testing.T) {
+	for _, code := range []string{"08006", "08003", "40001", "40P01", "57P01"} {
+		t.Run(code, func(t *testing.T) {
+			calls := 0
+			got, err := retryRead(context.Background(), retryClient(3), func() (int, error) {
+				calls++
+				if calls < 3 {
+					return 0, &pgconn.PgError{Code: code}
+				}
+				return 42, nil
+			})
+			if err != nil || got != 42 {
+				t.Errorf("retryRead = %d, %v, want 42", got, err)
+			}
+			if calls != 3 {
+				t.Errorf("read ran %d times, want 3", calls)
+			}
+		})
+	}
+}
+
+func TestRetryReadReturnsPermanentErrorsAtOnce(t *testing.T) {
+	for _, err := range []error{
+		&pgconn.PgError{Code: "23505"}, // unique_violation
+		&pgconn.PgError{Code: "23503"}, // foreign_key_violation
+		&pgconn.PgError{Code: "42P01"}, // undefined_table
+		errors.New("no rows in result set"),
+		context.Canceled,
+	} {
+		calls := 0
+		_, got := retryRead(context.Background(), retryClient(3), func() (int, error) {
+			calls++
+			return 0, err
+		})
+		if !errors.Is(got, err) {
+			t.Errorf("retryRead error = %v, want %v", got, err)
+		}
+		if calls != 1 {
+			t.Errorf("read failing with %v ran %d times, want 1", err, calls)
+		}
+	}
+}
+
+func TestRetryReadStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	start := time.Now()
+	_, err := retryRead(ctx, retryClient(5), func() (int, error) {
+		calls++
+		return 0, &pgconn.PgError{Code: "08006"}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("retryRead = %v after %d calls, want the first error without retrying", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed >= queryRetryDelay {
+		t.Errorf("retryRead waited %v with a cancelled context", elapsed)
+	}
+}
+
+func TestRetryReadSingleAttempt(t *testing.T) {
+	var calls atomic.Int32
+	_, err := retryRead(context.Background(), retryClient(1), func() (int, error) {
+		calls.Add(1)
+		return 0, &pgconn.PgError{Code: "08006"}
+	})
+	if err == nil || calls.Load() != 1 {
+		t.Errorf("retryRead with one attempt = %v after %d calls, want one failed call", err, calls.Load())
+	}
+}
+
+func TestWithQueryAttemptsIgnoresNonPositive(t *testing.T) {
+	c := &Client{queryAttempts: defaultQueryAttempts}
+	WithQueryAttempts(0)(c)
+	WithQueryAttempts(-1)(c)
+	if c.queryAttempts != defaultQueryAttempts {
+		t.Errorf("queryAttempts = %d, want the default %d", c.queryAttempts, defaultQueryAttempts)
+	}
+	WithQueryAttempts(5)(c)
+	if c.queryAttempts != 5 {
+		t.Errorf("queryAttempts = %d, want 5", c.queryAttempts)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: "08006"}, true}, // connection_failure
+		{&pgconn.PgError{Code: "40001"}, true}, // serialization_failure
+		{&pgconn.PgError{Code: "57P01"}, true}, // admin_shutdown
+		{fmt.Errorf("failed to count specs: %w", io.ErrUnexpectedEOF), true},
+		{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{fmt.Errorf("write: %w", syscall.EPIPE), true},
+		{context.DeadlineExceeded, true},
+		{&pgconn.PgError{Code: "23505"}, false}, // unique_violation
+		{&pgconn.PgError{Code: "22P02"}, false}, // invalid_text_representation
+		{context.Canceled, false},
+		{fmt.Errorf("query: %w", context.Canceled), false},
+		{errors.New("no rows in result set"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestEachSpecStopsWhenContextIsCancelled(t *testing.T) {
+	server := newFakePostgres(t, 0)
+	server.fields = nil
+	for _, col := range []struct {
+		name string
+		oid  uint32
+	}{
+		{"make", 25}, {"model", 25}, {"year", 23}, {"capacity_kwh", 701}, {"power_kw", 701}, {"chemistry", 25},
+		{"tags", 1009}, {"source", 25}, {"notes", 25}, {"body_style", 25}, {"confidence", 701}, {"authoritative", 16},
+	} {
+		server.fields = append(server.fields, pgproto3.FieldDescription{Name: []byte(col.name), DataTypeOID: col.oid, TypeModifier: -1})
+	}
+	server.rows = nil
+	for _, model := range []string{"Model 3", "Model S", "Model Y"} {
+		server.rows = append(server.rows, [][]byte{
+			[]byte("Tesla"), []byte(model), []byte("2023"), []byte("75"), []byte("283"), nil,
+			[]byte("{}"), []byte("database"), []byte(""), []byte(""), nil, []byte("f"),
+		})
+	}
+	c := server.client(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var seen []string
+	err := c.EachSpec(ctx, SpecFilter{}, func(spec models.EVSpec) error {
+		seen = append(seen, spec.Model)
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("EachSpec error = %v, want context.Canceled", err)
+	}
+	if len(seen) != 1 {
+		t.Errorf("EachSpec visited %q after the context was cancelled, want only the first row", seen)
+	}
+}
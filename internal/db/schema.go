@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// ensureSchemaSQL creates the schema produced by the migrations in
+// migrations/, using only idempotent statements. Keep it in sync with new
+// migrations. Columns added by later migrations are also added to an existing
+// table so that older schemas are brought up to date.
+var ensureSchemaSQL = fmt.Sprintf(`
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS ev_specs (
+    id SERIAL PRIMARY KEY,
+    make VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INTEGER NOT NULL,
+    capacity_kwh FLOAT NOT NULL,
+    power_kw FLOAT NOT NULL,
+    chemistry VARCHAR(100) NOT NULL,
+    embedding vector(%d),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(make, model, year)
+);
+
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'database';
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS raw_response TEXT;
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS authoritative BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS notes TEXT;
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS confidence FLOAT;
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS body_style VARCHAR(20);
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS embedding_alt vector;
+
+CREATE OR REPLACE FUNCTION ev_match_key(make TEXT, model TEXT, year INTEGER) RETURNS TEXT AS $$
+    SELECT regexp_replace(lower(make), '[^[:alnum:]]+', '', 'g') || '|' ||
+           regexp_replace(lower(model), '[^[:alnum:]]+', '', 'g') || '|' || year
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS match_key TEXT
+    GENERATED ALWAYS AS (ev_match_key(make, model, year)) STORED;
+
+CREATE INDEX IF NOT EXISTS ev_specs_embedding_idx ON ev_specs
+    USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100);
+CREATE INDEX IF NOT EXISTS ev_specs_tags_idx ON ev_specs USING GIN (tags);
+CREATE INDEX IF NOT EXISTS ev_specs_source_idx ON ev_specs (source);
+CREATE UNIQUE INDEX IF NOT EXISTS ev_specs_match_key_idx ON ev_specs (match_key);
+
+CREATE TABLE IF NOT EXISTS ev_specs_history (
+    id SERIAL PRIMARY KEY,
+    spec_id INTEGER NOT NULL,
+    make VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INTEGER NOT NULL,
+    capacity_kwh FLOAT NOT NULL,
+    power_kw FLOAT NOT NULL,
+    chemistry VARCHAR(100) NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    operation VARCHAR(10) NOT NULL,
+    valid_from TIMESTAMP,
+    superseded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE ev_specs_history ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+ALTER TABLE ev_specs_history ADD COLUMN IF NOT EXISTS raw_response TEXT;
+
+CREATE INDEX IF NOT EXISTS ev_specs_history_key_idx ON ev_specs_history (LOWER(make), LOWER(model), year);
+CREATE INDEX IF NOT EXISTS ev_specs_history_spec_idx ON ev_specs_history (spec_id, valid_from);
+
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    model VARCHAR(200) NOT NULL,
+    text_sha256 CHAR(64) NOT NULL,
+    embedding REAL[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model, text_sha256)
+);
+
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION ev_specs_record_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response,
+            OLD.notes, OLD.confidence, OLD.authoritative, OLD.body_style)
+            IS NOT DISTINCT FROM (NEW.capacity_kwh, NEW.power_kw, NEW.chemistry, NEW.tags, NEW.source, NEW.raw_response,
+            NEW.notes, NEW.confidence, NEW.authoritative, NEW.body_style) THEN
+            RETURN NEW;
+        END IF;
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM ev_specs_history h
+        WHERE h.spec_id = OLD.id
+          AND h.operation = TG_OP
+          AND h.valid_from IS NOT DISTINCT FROM COALESCE(OLD.updated_at, OLD.created_at)
+          AND (h.capacity_kwh, h.power_kw, h.chemistry, h.tags, h.source, h.raw_response)
+              IS NOT DISTINCT FROM (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response)
+    ) THEN
+        INSERT INTO ev_specs_history (spec_id, make, model, year, capacity_kwh, power_kw, chemistry, tags, source, raw_response, operation, valid_from)
+        VALUES (OLD.id, OLD.make, OLD.model, OLD.year, OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response, TG_OP,
+                COALESCE(OLD.updated_at, OLD.created_at));
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ev_specs_history_trigger ON ev_specs;
+CREATE TRIGGER ev_specs_history_trigger
+    BEFORE UPDATE OR DELETE ON ev_specs
+    FOR EACH ROW EXECUTE FUNCTION ev_specs_record_history();
+`, models.EmbeddingDimension)
+
+// EnsureSchema creates the vector extension, tables, indexes and triggers if
+// they are missing, without going through golang-migrate. It is safe to run
+// repeatedly and is meant for deployments that manage migrations with their
+// own tooling; MigrateUp remains the default. A newly created embedding
+// column uses models.EmbeddingDimension; an existing one is left as is.
+func (c *Client) EnsureSchema(ctx context.Context) error {
+	// Without arguments pgx uses the simple protocol, which runs the whole
+	// script as one implicit transaction
+	if _, err := c.pool.Exec(ctx, ensureSchemaSQL); err != nil {
+		return fmt.Errorf("failed to ensure schema: %w", err)
+	}
+	return nil
+}
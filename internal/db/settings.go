@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SettingDocumentTemplate records embedding.Service.DocumentTemplateID for
+// the texts the stored embeddings were built from
+const SettingDocumentTemplate = "document_template"
+
+// Setting returns the value stored under key, or "" if it was never set
+func (c *Client) Setting(ctx context.Context, key string) (string, error) {
+	var value string
+	err := c.retryScan(ctx, func() error {
+		return c.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	})
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetSetting stores value under key, replacing any previous value
+func (c *Client) SetSetting(ctx context.Context, key, value string) error {
+	_, err := c.pool.Exec(ctx, `
+		INSERT INTO settings (key, value)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to write setting %s: %w", key, err)
+	}
+	return nil
+}
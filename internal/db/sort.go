@@ -0,0 +1,62 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sortColumns maps the sortable field names to their columns. Only these
+// names are ever interpolated into an ORDER BY clause.
+var sortColumns = map[string]string{
+	"make":     "make",
+	"model":    "model",
+	"year":     "year",
+	"capacity": "capacity_kwh",
+	"power":    "power_kw",
+}
+
+// SortFields lists the field names accepted by ParseSort
+var SortFields = []string{"make", "model", "year", "capacity", "power"}
+
+// Sort is a result order chosen by the caller. The zero value keeps each
+// query's default order.
+type Sort struct {
+	Field string // One of SortFields
+	Desc  bool
+}
+
+// ParseSort parses "field" or "field:asc|desc", e.g. "capacity:desc". An
+// empty string returns the zero Sort.
+func ParseSort(s string) (Sort, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Sort{}, nil
+	}
+	field, direction, _ := strings.Cut(strings.ToLower(s), ":")
+	if _, ok := sortColumns[field]; !ok {
+		return Sort{}, fmt.Errorf("invalid sort field %q (use one of %s)", field, strings.Join(SortFields, ", "))
+	}
+	switch direction {
+	case "", "asc":
+		return Sort{Field: field}, nil
+	case "desc":
+		return Sort{Field: field, Desc: true}, nil
+	default:
+		return Sort{}, fmt.Errorf("invalid sort direction %q (use asc or desc)", direction)
+	}
+}
+
+// orderBy returns the ORDER BY expressions for the sort, or fallback for the
+// zero Sort. Ties are broken by make, model and year so that pages of a
+// sorted listing never overlap.
+func (s Sort) orderBy(fallback string) string {
+	column, ok := sortColumns[s.Field]
+	if !ok {
+		return fallback
+	}
+	direction := "ASC"
+	if s.Desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s NULLS LAST, make, model, year", column, direction)
+}
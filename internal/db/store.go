@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// SpecStore is the spec storage used by the resolver. *Client keeps specs in
+// Postgres with pgvector; *JSONStore keeps them in a local JSON file.
+type SpecStore interface {
+	GetByMakeModelYear(ctx context.Context, make, model string, year int) (*models.EVSpec, error)
+	SimilaritySearch(ctx context.Context, embedding []float32, limit int, opts ...SearchOption) ([]models.EVSpec, error)
+	InsertEVSpec(ctx context.Context, spec *models.EVSpec, embedding []float32, opts ...InsertOption) error
+	UpdateSpecFields(ctx context.Context, spec *models.EVSpec, opts ...UpdateOption) error
+	ListSpecs(ctx context.Context, filter SpecFilter, opts ...ListOption) ([]models.EVSpec, error)
+	Close()
+}
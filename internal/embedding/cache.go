@@ -0,0 +1,77 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// Cache persists embeddings across processes, keyed by the model that
+// produced them and the SHA-256 of the input text. *db.Client implements it
+// with the embedding_cache table.
+type Cache interface {
+	// CachedEmbedding returns the stored embedding, or nil on a miss
+	CachedEmbedding(ctx context.Context, model, textHash string) ([]float32, error)
+	// StoreCachedEmbedding stores an embedding, keeping any existing entry
+	StoreCachedEmbedding(ctx context.Context, model, textHash string, embedding []float32) error
+}
+
+// WithCache makes GetEmbedding look up every text in cache before calling a
+// provider and store fresh embeddings in it. Cache failures are reported on
+// stderr once and then the cache is no longer used, so a missing table never
+// fails a query. A nil cache disables caching.
+func WithCache(cache Cache) Option {
+	return func(s *Service) {
+		s.cache = cache
+	}
+}
+
+// cacheModel identifies the embedding space of provider in cache keys. A local
+// server that is not sent a model is identified by its URL.
+func (s *Service) cacheModel(provider ProviderType) string {
+	model := s.Model(provider)
+	if provider == ProviderLocalHTTP && model == "" {
+		model = s.localURL
+	}
+	return string(provider) + ":" + model
+}
+
+// embedCached is embed with the cache in front of it. cached reports whether
+// the embedding was read from the cache rather than requested from provider.
+func (s *Service) embedCached(ctx context.Context, provider ProviderType, text string) (embedding []float32, cached bool, err error) {
+	if s.cache == nil || s.cacheFailed.Load() {
+		embedding, err = s.embed(ctx, provider, text)
+		return embedding, false, err
+	}
+
+	model := s.cacheModel(provider)
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+
+	stored, err := s.cache.CachedEmbedding(ctx, model, hash)
+	if err != nil {
+		s.disableCache(err)
+	} else if stored != nil {
+		return stored, true, nil
+	}
+
+	embedding, err = s.embed(ctx, provider, text)
+	if err != nil {
+		return nil, false, err
+	}
+	if !s.cacheFailed.Load() {
+		if err := s.cache.StoreCachedEmbedding(ctx, model, hash, embedding); err != nil {
+			s.disableCache(err)
+		}
+	}
+	return embedding, false, nil
+}
+
+// disableCache stops using the cache after its first failure
+func (s *Service) disableCache(err error) {
+	if s.cacheFailed.CompareAndSwap(false, true) {
+		fmt.Fprintf(os.Stderr, "Warning: embedding cache disabled: %v\n", err)
+	}
+}
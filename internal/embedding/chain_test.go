@@ -0,0 +1,103 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeEmbedServers starts an Ollama and an OpenAI-compatible local server
+// answering with embeddings of the given dimensions
+func fakeEmbedServers(t *testing.T, ollamaDim, localDim int) (ollamaURL, localURL string) {
+	t.Helper()
+	vector := func(n int) string {
+		return "[" + strings.TrimSuffix(strings.Repeat("0.1,", n), ",") + "]"
+	}
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"embeddings": [%s]}`, vector(ollamaDim))
+	}))
+	t.Cleanup(ollama.Close)
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": [{"embedding": %s}]}`, vector(localDim))
+	}))
+	t.Cleanup(local.Close)
+	return ollama.URL, local.URL
+}
+
+func TestCheckDimensionsRefusesMismatchedChain(t *testing.T) {
+	ollamaURL, localURL := fakeEmbedServers(t, 3, 4)
+	svc := NewWithProvider(ProviderOllama, "", ollamaURL, "test",
+		WithLocalHTTP(localURL, ShapeOpenAI, ""), WithFallback(ProviderOllama, ProviderLocalHTTP))
+
+	_, err := svc.CheckDimensions(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "local embeddings have 4 dimensions but ollama embeddings have 3") {
+		t.Fatalf("CheckDimensions error = %v, want a dimension mismatch", err)
+	}
+}
+
+func TestCheckDimensionsPinsChain(t *testing.T) {
+	ollamaURL, localURL := fakeEmbedServers(t, 3, 3)
+	svc := NewWithProvider(ProviderOllama, "", ollamaURL, "test",
+		WithLocalHTTP(localURL, ShapeOpenAI, ""), WithFallback(ProviderOllama, ProviderLocalHTTP))
+
+	dimension, err := svc.CheckDimensions(context.Background())
+	if err != nil {
+		t.Fatalf("CheckDimensions: %v", err)
+	}
+	if dimension != 3 {
+		t.Errorf("dimension = %d, want 3", dimension)
+	}
+	if err := svc.checkDimension(make([]float32, 4), true); err == nil {
+		t.Error("a 4-dimension embedding was accepted after CheckDimensions pinned 3")
+	}
+}
+
+func TestCheckDimensionsAgainstColumn(t *testing.T) {
+	ollamaURL, localURL := fakeEmbedServers(t, 3, 3)
+	svc := NewWithProvider(ProviderOllama, "", ollamaURL, "test",
+		WithLocalHTTP(localURL, ShapeOpenAI, ""), WithFallback(ProviderOllama, ProviderLocalHTTP), WithDimension(1536))
+
+	if _, err := svc.CheckDimensions(context.Background()); err == nil {
+		t.Fatal("CheckDimensions accepted providers that do not fit the pinned dimension")
+	}
+}
+
+// mapCache is an in-memory Cache
+type mapCache map[string][]float32
+
+func (c mapCache) CachedEmbedding(ctx context.Context, model, textHash string) ([]float32, error) {
+	return c[model+"|"+textHash], nil
+}
+
+func (c mapCache) StoreCachedEmbedding(ctx context.Context, model, textHash string, embedding []float32) error {
+	c[model+"|"+textHash] = embedding
+	return nil
+}
+
+func TestWithServedByReportsServingProvider(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	t.Cleanup(down.Close)
+	_, localURL := fakeEmbedServers(t, 3, 3)
+	svc := NewWithProvider(ProviderOllama, "", down.URL, "test",
+		WithLocalHTTP(localURL, ShapeOpenAI, "bge-small"), WithFallback(ProviderOllama, ProviderLocalHTTP),
+		WithCache(mapCache{}))
+
+	var served []string
+	ctx := WithServedBy(context.Background(), func(provider ProviderType, model string) {
+		served = append(served, fmt.Sprintf("%s/%s", provider, model))
+	})
+	for range 2 {
+		if _, err := svc.GetEmbedding(ctx, "2023 Tesla Model 3"); err != nil {
+			t.Fatalf("GetEmbedding failed: %v", err)
+		}
+	}
+	// The second embedding is a cache hit, which calls no provider
+	if want := []string{"local/bge-small"}; fmt.Sprint(served) != fmt.Sprint(want) {
+		t.Errorf("served by %v, want %v", served, want)
+	}
+}
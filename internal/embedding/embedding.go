@@ -2,10 +2,22 @@ package embedding
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/ollama"
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/version"
 )
 
 const (
@@ -19,17 +31,146 @@ const (
 type ProviderType string
 
 const (
-	ProviderOpenAI ProviderType = "openai"
-	ProviderOllama ProviderType = "ollama"
+	ProviderOpenAI    ProviderType = "openai"
+	ProviderOllama    ProviderType = "ollama"
+	ProviderLocalHTTP ProviderType = "local" // Self-hosted embeddings server, see WithLocalHTTP
+)
+
+// ResponseShape selects the request and response format of a local embeddings server
+type ResponseShape string
+
+const (
+	// ShapeOpenAI is the OpenAI-compatible /v1/embeddings format
+	ShapeOpenAI ResponseShape = "openai"
+	// ShapeTEI is the text-embeddings-inference /embed format
+	ShapeTEI ResponseShape = "tei"
 )
 
 // Service handles text-to-vector embedding operations
 type Service struct {
 	provider    ProviderType
 	openAIKey   string
+	openAIModel string // OpenAI model; empty for text-embedding-3-small
 	ollamaURL   string
 	ollamaModel string
+	localURL    string
+	localShape  ResponseShape
+	localModel  string
 	client      *http.Client
+	userAgent   string
+	race        []ProviderType
+	chain       []ProviderType
+	dimension   atomic.Int64 // expected embedding dimension, 0 if not yet known
+	metrics     metrics.Recorder
+	resilience  resilience.Policy
+	pullMissing bool // pull a missing Ollama model and retry
+	// maxInputChars caps input length: 0 for the provider default, negative for none
+	maxInputChars int
+	cache         Cache       // nil disables the persistent cache
+	cacheFailed   atomic.Bool // set after the first cache error
+	// queryTemplate and documentTemplate replace the default texts when set
+	queryTemplate    *template.Template
+	documentTemplate *template.Template
+}
+
+// Option is a functional option for Service
+type Option func(*Service)
+
+// WithRacing makes GetEmbedding query all the given providers concurrently and
+// return whichever responds first, cancelling the others. This trades cost
+// (every provider is billed) for latency and resilience to a flaky provider.
+//
+// Vectors from different models live in different embedding spaces and must
+// not be mixed in the same column, so every raced provider must produce the
+// same dimension. The first successful response pins the dimension (unless
+// WithDimension already did) and later responses of any other size are
+// rejected rather than returned.
+func WithRacing(providers ...ProviderType) Option {
+	return func(s *Service) {
+		s.race = providers
+	}
+}
+
+// WithFallback makes GetEmbedding try the given providers in order, moving on
+// to the next only when one fails, and return the first success. Unlike
+// WithRacing, only the providers actually tried are billed.
+//
+// As with racing, every chained provider shares one embedding column and must
+// produce the same dimension: the first successful response pins it, and a
+// later provider answering with any other size counts as a failure. Use
+// WithDimension or CheckDimensions to refuse a mismatched chain before the
+// first fallback instead. The provider that served each embedding is logged
+// on stderr.
+func WithFallback(providers ...ProviderType) Option {
+	return func(s *Service) {
+		s.chain = providers
+	}
+}
+
+// WithLocalHTTP configures ProviderLocalHTTP. url is the full endpoint, e.g.
+// http://localhost:8080/v1/embeddings for ShapeOpenAI or
+// http://localhost:8080/embed for ShapeTEI. model is sent only with
+// ShapeOpenAI and may be empty if the server serves a single model.
+func WithLocalHTTP(url string, shape ResponseShape, model string) Option {
+	return func(s *Service) {
+		s.localURL = url
+		s.localShape = shape
+		s.localModel = model
+	}
+}
+
+// WithOpenAIModel sets the OpenAI embedding model. An empty model keeps the
+// default, text-embedding-3-small.
+func WithOpenAIModel(model string) Option {
+	return func(s *Service) {
+		s.openAIModel = model
+	}
+}
+
+// WithPullMissingModel makes Ollama requests that fail with an
+// *ollama.ModelNotFoundError pull the model and retry once
+func WithPullMissingModel() Option {
+	return func(s *Service) {
+		s.pullMissing = true
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with provider requests
+func WithUserAgent(userAgent string) Option {
+	return func(s *Service) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for provider requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithMetrics records request counts and latencies per provider
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(s *Service) {
+		s.metrics = recorder
+	}
+}
+
+// WithResilience retries, times out and breaks provider requests as policy
+// says. It wraps whichever HTTP client the service ends up with, so it may be
+// combined with WithHTTPClient in either order.
+func WithResilience(policy resilience.Policy) Option {
+	return func(s *Service) {
+		s.resilience = policy
+	}
+}
+
+// WithDimension pins the expected embedding dimension. Responses with any
+// other number of dimensions are rejected.
+func WithDimension(n int) Option {
+	return func(s *Service) {
+		s.dimension.Store(int64(n))
+	}
 }
 
 // New creates a new embedding service with OpenAI
@@ -38,18 +179,27 @@ func New(apiKey string) *Service {
 		provider:  ProviderOpenAI,
 		openAIKey: apiKey,
 		client:    &http.Client{},
+		userAgent: version.UserAgent(),
+		metrics:   metrics.Nop{},
 	}
 }
 
 // NewWithProvider creates a new embedding service with the specified provider
-func NewWithProvider(provider ProviderType, openAIKey, ollamaURL, ollamaModel string) *Service {
-	return &Service{
+func NewWithProvider(provider ProviderType, openAIKey, ollamaURL, ollamaModel string, opts ...Option) *Service {
+	s := &Service{
 		provider:    provider,
 		openAIKey:   openAIKey,
 		ollamaURL:   ollamaURL,
 		ollamaModel: ollamaModel,
 		client:      &http.Client{},
+		userAgent:   version.UserAgent(),
+		metrics:     metrics.Nop{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.client = s.resilience.Client(s.client)
+	return s
 }
 
 // openAIEmbeddingRequest represents the request to OpenAI's embedding API
@@ -66,22 +216,138 @@ type openAIEmbeddingResponse struct {
 }
 
 // GetEmbedding converts text to a vector embedding
-func (s *Service) GetEmbedding(text string) ([]float32, error) {
-	switch s.provider {
+func (s *Service) GetEmbedding(ctx context.Context, text string) ([]float32, error) {
+	if len(s.race) > 1 {
+		return s.raceEmbedding(ctx, text)
+	}
+	if len(s.chain) > 1 {
+		return s.chainEmbedding(ctx, text)
+	}
+
+	embedding, cached, err := s.embedCached(ctx, s.provider, text)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDimension(embedding, false); err != nil {
+		return nil, err
+	}
+	if !cached {
+		s.reportServed(ctx, s.provider)
+	}
+	return embedding, nil
+}
+
+// embed converts text to a vector embedding using the given provider
+func (s *Service) embed(ctx context.Context, provider ProviderType, text string) (embedding []float32, err error) {
+	start := time.Now()
+	defer func() {
+		s.metrics.IncCounter(metrics.EmbeddingRequestsTotal, map[string]string{"provider": string(provider), "status": metrics.Status(err)})
+		s.metrics.ObserveHistogram(metrics.EmbeddingDurationSeconds, time.Since(start).Seconds(), map[string]string{"provider": string(provider)})
+	}()
+
+	text = s.truncateInput(provider, text)
+	switch provider {
 	case ProviderOllama:
-		return s.getOllamaEmbedding(text)
+		return s.getOllamaEmbedding(ctx, text)
+	case ProviderLocalHTTP:
+		return s.getLocalEmbedding(ctx, text)
 	case ProviderOpenAI:
 		fallthrough
 	default:
-		return s.getOpenAIEmbedding(text)
+		return s.getOpenAIEmbedding(ctx, text)
+	}
+}
+
+// raceEmbedding queries every raced provider concurrently and returns the first
+// successful response of the expected dimension
+func (s *Service) raceEmbedding(ctx context.Context, text string) ([]float32, error) {
+	// Cancelling on return aborts the requests still in flight
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		provider  ProviderType
+		embedding []float32
+		cached    bool
+		err       error
+	}
+	results := make(chan result, len(s.race))
+	for _, provider := range s.race {
+		go func() {
+			embedding, cached, err := s.embedCached(ctx, provider, text)
+			results <- result{provider: provider, embedding: embedding, cached: cached, err: err}
+		}()
+	}
+
+	var errs []error
+	for range s.race {
+		r := <-results
+		if r.err == nil {
+			r.err = s.checkDimension(r.embedding, true)
+		}
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.provider, r.err))
+			continue
+		}
+		if !r.cached {
+			s.reportServed(ctx, r.provider)
+		}
+		return r.embedding, nil
+	}
+
+	return nil, fmt.Errorf("all raced embedding providers failed: %w", errors.Join(errs...))
+}
+
+// chainEmbedding tries each chained provider in turn and returns the first
+// successful response of the expected dimension
+func (s *Service) chainEmbedding(ctx context.Context, text string) ([]float32, error) {
+	var errs []error
+	for i, provider := range s.chain {
+		embedding, cached, err := s.embedCached(ctx, provider, text)
+		if err == nil {
+			err = s.checkDimension(embedding, true)
+		}
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Embedding served by %s\n", provider)
+			if !cached {
+				s.reportServed(ctx, provider)
+			}
+			return embedding, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(s.chain)-1 {
+			fmt.Fprintf(os.Stderr, "Warning: %s embedding failed: %v; trying %s\n", provider, err, s.chain[i+1])
+		}
 	}
+
+	return nil, fmt.Errorf("all chained embedding providers failed: %w", errors.Join(errs...))
+}
+
+// checkDimension rejects embeddings that don't match the expected dimension.
+// If no dimension is known yet and pin is true, the embedding's size becomes
+// the expected dimension.
+func (s *Service) checkDimension(embedding []float32, pin bool) error {
+	expected := s.dimension.Load()
+	if expected == 0 {
+		if !pin || s.dimension.CompareAndSwap(0, int64(len(embedding))) {
+			return nil
+		}
+		expected = s.dimension.Load()
+	}
+	if int64(len(embedding)) != expected {
+		return fmt.Errorf("embedding has %d dimensions, expected %d", len(embedding), expected)
+	}
+	return nil
 }
 
 // getOpenAIEmbedding converts text to a vector embedding using OpenAI
-func (s *Service) getOpenAIEmbedding(text string) ([]float32, error) {
+func (s *Service) getOpenAIEmbedding(ctx context.Context, text string) ([]float32, error) {
 	reqBody := openAIEmbeddingRequest{
 		Input: text,
-		Model: embeddingModel,
+		Model: s.Model(ProviderOpenAI),
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -89,12 +355,13 @@ func (s *Service) getOpenAIEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", openaiEmbeddingURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", openaiEmbeddingURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.openAIKey))
 
 	resp, err := s.client.Do(req)
@@ -132,8 +399,24 @@ type ollamaEmbeddingResponse struct {
 	Embeddings [][]float64 `json:"embeddings"`
 }
 
-// getOllamaEmbedding converts text to a vector embedding using Ollama
-func (s *Service) getOllamaEmbedding(text string) ([]float32, error) {
+// getOllamaEmbedding converts text to a vector embedding using Ollama, pulling
+// a missing model first if WithPullMissingModel is set
+func (s *Service) getOllamaEmbedding(ctx context.Context, text string) ([]float32, error) {
+	embedding, err := s.requestOllamaEmbedding(ctx, text)
+	var notFound *ollama.ModelNotFoundError
+	if !s.pullMissing || !errors.As(err, &notFound) {
+		return embedding, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Pulling Ollama model %s...\n", s.ollamaModel)
+	if err := ollama.Pull(ctx, s.client, s.ollamaURL, s.ollamaModel, s.userAgent); err != nil {
+		return nil, err
+	}
+	return s.requestOllamaEmbedding(ctx, text)
+}
+
+// requestOllamaEmbedding sends a single request to Ollama's embedding API
+func (s *Service) requestOllamaEmbedding(ctx context.Context, text string) ([]float32, error) {
 	reqBody := ollamaEmbeddingRequest{
 		Model: s.ollamaModel,
 		Input: text,
@@ -145,12 +428,13 @@ func (s *Service) getOllamaEmbedding(text string) ([]float32, error) {
 	}
 
 	url := fmt.Sprintf("%s/api/embed", s.ollamaURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -160,6 +444,9 @@ func (s *Service) getOllamaEmbedding(text string) ([]float32, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if err := ollama.CheckModel(resp.StatusCode, body, s.ollamaModel); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
@@ -182,6 +469,176 @@ func (s *Service) getOllamaEmbedding(text string) ([]float32, error) {
 	return embedding, nil
 }
 
+// teiEmbeddingRequest represents the request to text-embeddings-inference's /embed API
+type teiEmbeddingRequest struct {
+	Inputs string `json:"inputs"`
+}
+
+// getLocalEmbedding converts text to a vector embedding using a self-hosted
+// embeddings server in the configured response shape
+func (s *Service) getLocalEmbedding(ctx context.Context, text string) ([]float32, error) {
+	if s.localURL == "" {
+		return nil, fmt.Errorf("local embedding URL is not configured")
+	}
+
+	var reqBody any
+	switch s.localShape {
+	case ShapeTEI:
+		reqBody = teiEmbeddingRequest{Inputs: text}
+	case ShapeOpenAI, "":
+		reqBody = openAIEmbeddingRequest{Input: text, Model: s.localModel}
+	default:
+		return nil, fmt.Errorf("unsupported local embedding response shape: %s", s.localShape)
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.localURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("local embedding server error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var embedding []float32
+	if s.localShape == ShapeTEI {
+		// TEI returns one embedding per input
+		var embeddings [][]float32
+		if err := json.NewDecoder(resp.Body).Decode(&embeddings); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(embeddings) > 0 {
+			embedding = embeddings[0]
+		}
+	} else {
+		var embeddingResp openAIEmbeddingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(embeddingResp.Data) > 0 {
+			embedding = embeddingResp.Data[0].Embedding
+		}
+	}
+
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("no embedding data in response")
+	}
+	return embedding, nil
+}
+
+// Providers returns the providers GetEmbedding queries: every raced or
+// chained provider, or just the configured one
+func (s *Service) Providers() []ProviderType {
+	if len(s.race) > 1 {
+		return s.race
+	}
+	if len(s.chain) > 1 {
+		return s.chain
+	}
+	return []ProviderType{s.provider}
+}
+
+// Model returns the embedding model name used with provider. It is empty for
+// a local server that is not sent a model.
+func (s *Service) Model(provider ProviderType) string {
+	switch provider {
+	case ProviderOllama:
+		return s.ollamaModel
+	case ProviderLocalHTTP:
+		return s.localModel
+	default:
+		if s.openAIModel != "" {
+			return s.openAIModel
+		}
+		return embeddingModel
+	}
+}
+
+// URL returns the endpoint requested for provider
+func (s *Service) URL(provider ProviderType) string {
+	switch provider {
+	case ProviderOllama:
+		return s.ollamaURL + "/api/embed"
+	case ProviderLocalHTTP:
+		return s.localURL
+	default:
+		return openaiEmbeddingURL
+	}
+}
+
+// Probe embeds a short text with provider alone and returns the embedding
+// dimension. Unlike GetEmbedding it does not check or pin the dimension.
+func (s *Service) Probe(ctx context.Context, provider ProviderType) (int, error) {
+	embedding, err := s.embed(ctx, provider, BuildQueryText("Tesla", "Model 3", 2023))
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
+
+// CheckDimensions probes every provider GetEmbedding may use and returns their
+// common dimension, pinning it for later responses. It fails if the providers
+// disagree with each other or with a dimension set by WithDimension, so a
+// mismatched race or fallback chain is refused up front rather than when a
+// fallback first answers. Each probe is a billed request.
+func (s *Service) CheckDimensions(ctx context.Context) (int, error) {
+	dimension := int(s.dimension.Load())
+	var first ProviderType
+	for _, provider := range s.Providers() {
+		n, err := s.Probe(ctx, provider)
+		if err != nil {
+			return 0, fmt.Errorf("failed to probe %s embeddings: %w", provider, err)
+		}
+		switch {
+		case dimension == 0:
+			dimension, first = n, provider
+		case n == dimension:
+		case first == "":
+			return 0, fmt.Errorf("%s embeddings have %d dimensions, expected %d", provider, n, dimension)
+		default:
+			return 0, fmt.Errorf("%s embeddings have %d dimensions but %s embeddings have %d; raced and chained providers must share one dimension", provider, n, first, dimension)
+		}
+	}
+
+	s.dimension.CompareAndSwap(0, int64(dimension))
+	return dimension, nil
+}
+
+// BuildDocumentText creates the text embedded for a stored spec. It starts
+// with the query text so lookups match, followed by any body style and notes,
+// which can help retrieval. Neither is ever part of BuildQueryText.
+func BuildDocumentText(make, model string, year int, bodyStyle, notes string) string {
+	return BuildQueryText(make, model, year) + documentSuffix(bodyStyle, notes)
+}
+
+// documentSuffix returns the body style and notes lines appended to the
+// query text of a document
+func documentSuffix(bodyStyle, notes string) string {
+	var text string
+	if bodyStyle = strings.TrimSpace(bodyStyle); bodyStyle != "" {
+		text += "\nBody style: " + bodyStyle
+	}
+	if notes = strings.TrimSpace(notes); notes != "" {
+		text += "\n" + notes
+	}
+	return text
+}
+
 // BuildQueryText creates a search query text from make, model, and year
 func BuildQueryText(make, model string, year int) string {
 	return fmt.Sprintf("%s %s %d battery specifications", make, model, year)
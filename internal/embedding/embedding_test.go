@@ -0,0 +1,13 @@
+package embedding
+
+import "testing"
+
+func TestWithOpenAIModel(t *testing.T) {
+	if got := New("key").Model(ProviderOpenAI); got != "text-embedding-3-small" {
+		t.Errorf("default OpenAI model = %q, want text-embedding-3-small", got)
+	}
+	svc := NewWithProvider(ProviderOpenAI, "key", "", "", WithOpenAIModel("text-embedding-3-large"))
+	if got := svc.Model(ProviderOpenAI); got != "text-embedding-3-large" {
+		t.Errorf("OpenAI model = %q, want text-embedding-3-large", got)
+	}
+}
@@ -0,0 +1,102 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/resilience/resiliencetest"
+)
+
+const ollamaEmbedding = `{"embeddings": [[0.1, 0.2, 0.3]]}`
+
+func ollamaService(url string, policy resilience.Policy) *Service {
+	return NewWithProvider(ProviderOllama, "", url, "nomic-embed-text", WithResilience(policy))
+}
+
+func TestGetEmbeddingRetriesFlakyProvider(t *testing.T) {
+	server := resiliencetest.NewServer(t, ollamaEmbedding,
+		resiliencetest.Throttled("0"),
+		resiliencetest.Status(http.StatusServiceUnavailable),
+		resiliencetest.Status(http.StatusBadGateway),
+		resiliencetest.OK())
+	svc := ollamaService(server.URL, resilience.Policy{MaxRetries: 3, Backoff: 20 * time.Millisecond})
+
+	embedding, err := svc.GetEmbedding(context.Background(), "Tesla Model 3 2023")
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	if len(embedding) != 3 {
+		t.Errorf("embedding has %d dimensions, want 3", len(embedding))
+	}
+	if got := server.Requests(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+	// Retry-After: 0 takes the place of the first 20ms backoff, and the 503
+	// and 502 then back off for the doubled 40ms and 80ms
+	gaps := server.Gaps()
+	for i, want := range []time.Duration{0, 40 * time.Millisecond, 80 * time.Millisecond} {
+		if gaps[i] < want {
+			t.Errorf("gap before request %d = %v, want at least %v", i+2, gaps[i], want)
+		}
+	}
+}
+
+func TestGetEmbeddingGivesUp(t *testing.T) {
+	server := resiliencetest.NewServer(t, ollamaEmbedding, resiliencetest.Status(http.StatusInternalServerError))
+	svc := ollamaService(server.URL, resilience.Policy{MaxRetries: 2, Backoff: time.Millisecond})
+
+	if _, err := svc.GetEmbedding(context.Background(), "Tesla Model 3 2023"); err == nil {
+		t.Fatal("GetEmbedding succeeded against a failing provider")
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestGetEmbeddingDoesNotRetryBadRequest(t *testing.T) {
+	server := resiliencetest.NewServer(t, ollamaEmbedding, resiliencetest.Status(http.StatusBadRequest))
+	svc := ollamaService(server.URL, resilience.Policy{MaxRetries: 3, Backoff: time.Millisecond})
+
+	if _, err := svc.GetEmbedding(context.Background(), "Tesla Model 3 2023"); err == nil {
+		t.Fatal("GetEmbedding succeeded on a 400")
+	}
+	if got := server.Requests(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestGetEmbeddingTimesOutSlowProvider(t *testing.T) {
+	server := resiliencetest.NewServer(t, ollamaEmbedding, resiliencetest.Slow(time.Minute), resiliencetest.OK())
+	svc := ollamaService(server.URL, resilience.Policy{MaxRetries: 1, Timeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := svc.GetEmbedding(context.Background(), "Tesla Model 3 2023"); err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetEmbedding took %v, want the slow attempt cut off", elapsed)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestGetEmbeddingBreakerOpens(t *testing.T) {
+	server := resiliencetest.NewServer(t, ollamaEmbedding, resiliencetest.Status(http.StatusServiceUnavailable))
+	svc := ollamaService(server.URL, resilience.Policy{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+
+	for range 2 {
+		svc.GetEmbedding(context.Background(), "Tesla Model 3 2023")
+	}
+	_, err := svc.GetEmbedding(context.Background(), "Tesla Model 3 2023")
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("GetEmbedding error = %v, want ErrCircuitOpen", err)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
@@ -0,0 +1,22 @@
+package embedding
+
+import "context"
+
+// servedKey carries the function WithServedBy registers in a context
+type servedKey struct{}
+
+// WithServedBy returns a copy of ctx in which GetEmbedding calls fn with the
+// provider and model that produced each embedding it returns. Embeddings read
+// from the cache, and responses that lost a race or failed the dimension
+// check, are not reported. fn may be called concurrently.
+func WithServedBy(ctx context.Context, fn func(provider ProviderType, model string)) context.Context {
+	return context.WithValue(ctx, servedKey{}, fn)
+}
+
+// reportServed tells the function registered by WithServedBy, if any, that
+// provider produced an embedding returned by GetEmbedding
+func (s *Service) reportServed(ctx context.Context, provider ProviderType) {
+	if fn, ok := ctx.Value(servedKey{}).(func(ProviderType, string)); ok {
+		fn(provider, s.Model(provider))
+	}
+}
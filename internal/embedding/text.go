@@ -0,0 +1,90 @@
+package embedding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// TextFields are the fields available to query and document text templates,
+// e.g. {{.Make}} {{.Model}} {{.Year}}. The query template only sees Make,
+// Model and Year; BodyStyle and Notes are empty there.
+type TextFields = models.TextFields
+
+// DefaultQueryTemplate is the query text template matching BuildQueryText
+const DefaultQueryTemplate = `{{.Make}} {{.Model}} {{.Year}} battery specifications`
+
+// WithTextTemplates renders query and document texts with the given
+// templates instead of BuildQueryText and BuildDocumentText. Either may be
+// nil to keep the default. With only a query template, documents are the
+// rendered query text followed by the body style and notes, as by default.
+func WithTextTemplates(query, document *template.Template) Option {
+	return func(s *Service) {
+		s.queryTemplate = query
+		s.documentTemplate = document
+	}
+}
+
+// QueryText returns the text embedded to look up make, model and year
+func (s *Service) QueryText(make, model string, year int) (string, error) {
+	if s.queryTemplate == nil {
+		return BuildQueryText(make, model, year), nil
+	}
+	return render(s.queryTemplate, TextFields{Make: make, Model: model, Year: year})
+}
+
+// DocumentText returns the text embedded for a stored spec
+func (s *Service) DocumentText(make, model string, year int, bodyStyle, notes string) (string, error) {
+	if s.documentTemplate != nil {
+		return render(s.documentTemplate, TextFields{
+			Make:      make,
+			Model:     model,
+			Year:      year,
+			BodyStyle: strings.TrimSpace(bodyStyle),
+			Notes:     strings.TrimSpace(notes),
+		})
+	}
+	if s.queryTemplate == nil {
+		return BuildDocumentText(make, model, year, bodyStyle, notes), nil
+	}
+
+	text, err := s.QueryText(make, model, year)
+	if err != nil {
+		return "", err
+	}
+	return text + documentSuffix(bodyStyle, notes), nil
+}
+
+// DefaultDocumentTemplateID is DocumentTemplateID without text templates
+func DefaultDocumentTemplateID() string {
+	return (&Service{}).DocumentTemplateID()
+}
+
+// DocumentTemplateID identifies how document texts are built, so stored
+// embeddings can be matched against the current configuration: the SHA-256
+// of the document template, or of the query template that documents start
+// with when no document template is set
+func (s *Service) DocumentTemplateID() string {
+	source := "query:" + DefaultQueryTemplate
+	switch {
+	case s.documentTemplate != nil:
+		source = "document:" + s.documentTemplate.Root.String()
+	case s.queryTemplate != nil:
+		source = "query:" + s.queryTemplate.Root.String()
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// render executes tmpl with fields
+func render(tmpl *template.Template, fields TextFields) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, fields); err != nil {
+		return "", fmt.Errorf("failed to render %s text template: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,54 @@
+package embedding
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// openAIMaxInputChars keeps OpenAI input under the 8191-token limit of its
+// embedding models, assuming a conservative 3 characters per token
+const openAIMaxInputChars = 24000
+
+// WithMaxInputChars truncates embedding input to at most n characters before
+// it is sent to any provider. By default only OpenAI input is truncated, to
+// fit its token limit, since Ollama truncates to the model's context itself.
+// A negative n disables truncation.
+func WithMaxInputChars(n int) Option {
+	return func(s *Service) {
+		s.maxInputChars = n
+	}
+}
+
+// inputLimit returns the maximum input length in characters for provider, or
+// 0 for no limit
+func (s *Service) inputLimit(provider ProviderType) int {
+	switch {
+	case s.maxInputChars < 0:
+		return 0
+	case s.maxInputChars > 0:
+		return s.maxInputChars
+	case provider == ProviderOpenAI || provider == "":
+		return openAIMaxInputChars
+	default:
+		return 0
+	}
+}
+
+// truncateInput shortens text to the provider's input limit, preferring to
+// cut at whitespace, and warns on stderr when it does so
+func (s *Service) truncateInput(provider ProviderType, text string) string {
+	limit := s.inputLimit(provider)
+	length := utf8.RuneCountInString(text)
+	if limit == 0 || length <= limit {
+		return text
+	}
+
+	truncated := string([]rune(text)[:limit])
+	if i := strings.LastIndexAny(truncated, " \t\n"); i > len(truncated)/2 {
+		truncated = truncated[:i]
+	}
+	fmt.Fprintf(os.Stderr, "Warning: truncated %s embedding input from %d to %d characters\n", provider, length, utf8.RuneCountInString(truncated))
+	return truncated
+}
@@ -0,0 +1,50 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// shellQuote wraps s in single quotes, which the shell never expands inside,
+// closing and reopening the quotes around any embedded single quote
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// writeEnv writes one spec as NAME=value shell assignments suitable for eval
+// or source. Every variable is written, empty or not, so scripts never see a
+// value left over from an earlier run.
+func writeEnv(w io.Writer, spec models.EVSpec) error {
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{"EV_MAKE", shellQuote(spec.Make)},
+		{"EV_MODEL", shellQuote(spec.Model)},
+		{"EV_YEAR", strconv.Itoa(spec.Year)},
+		{"EV_CAPACITY_KWH", strconv.FormatFloat(spec.Capacity, 'f', 1, 64)},
+		{"EV_POWER_KW", strconv.FormatFloat(spec.Power, 'f', 1, 64)},
+		{"EV_CHEMISTRY", shellQuote(spec.Chemistry)},
+		{"EV_CONFIDENCE", strconv.FormatFloat(spec.Confidence, 'f', 2, 64)},
+		{"EV_CONFIDENCE_BAND", shellQuote(spec.ConfidenceBand)},
+		{"EV_SOURCE", shellQuote(spec.Source)},
+		{"EV_TAGS", shellQuote(strings.Join(spec.Tags, ","))},
+		{"EV_CHEMISTRY_SOURCE", shellQuote(spec.ChemistrySource)},
+		{"EV_CAPACITY_SOURCE", shellQuote(spec.CapacitySource)},
+		{"EV_NOTES", shellQuote(spec.Notes)},
+		{"EV_BODY_STYLE", shellQuote(spec.BodyStyle)},
+	}
+
+	var sb strings.Builder
+	for _, v := range vars {
+		sb.WriteString(v.name + "=" + v.value + "\n")
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write env: %w", err)
+	}
+	return nil
+}
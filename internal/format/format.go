@@ -0,0 +1,377 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Format represents an output format for EV specs
+type Format string
+
+const (
+	Text     Format = "text"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Table    Format = "table"
+	Markdown Format = "markdown"
+	Env      Format = "env" // Shell variable assignments; single specs only
+)
+
+// ConfidenceDisplay is how text, table and markdown output show confidence
+type ConfidenceDisplay string
+
+const (
+	ConfidenceScore ConfidenceDisplay = "score" // The raw number, e.g. "0.93"
+	ConfidenceBand  ConfidenceDisplay = "band"  // The band, e.g. "high"
+	ConfidenceBoth  ConfidenceDisplay = "both"  // The band and number, e.g. "high (0.93)"
+)
+
+// ParseConfidenceDisplay converts "score", "band" or "both" into a
+// ConfidenceDisplay
+func ParseConfidenceDisplay(name string) (ConfidenceDisplay, error) {
+	switch d := ConfidenceDisplay(strings.ToLower(name)); d {
+	case ConfidenceScore, ConfidenceBand, ConfidenceBoth:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid confidence display: %s (use score, band or both)", name)
+	}
+}
+
+// options holds the optional settings for Write and WriteSpec
+type options struct {
+	color      bool
+	verbose    bool
+	compact    bool
+	confidence ConfidenceDisplay
+	bands      models.ConfidenceBands
+	meta       *Meta // wraps JSON output in an Envelope when set
+}
+
+// Option is a functional option for Write and WriteSpec
+type Option func(*options)
+
+// WithColor enables ANSI colors in formats that support them (table)
+func WithColor(enabled bool) Option {
+	return func(o *options) {
+		o.color = enabled
+	}
+}
+
+// WithVerbose adds detail such as chemistry alternatives to text output
+func WithVerbose(enabled bool) Option {
+	return func(o *options) {
+		o.verbose = enabled
+	}
+}
+
+// WithCompact writes JSON on a single line instead of indenting it
+func WithCompact(enabled bool) Option {
+	return func(o *options) {
+		o.compact = enabled
+	}
+}
+
+// WithConfidenceBands shows confidence as a band from bands in text, table
+// and markdown output, alone or next to the number as display says;
+// ConfidenceScore shows only the number there. JSON and env output always
+// carry the band, from models.DefaultConfidenceBands without this option.
+func WithConfidenceBands(display ConfidenceDisplay, bands models.ConfidenceBands) Option {
+	return func(o *options) {
+		o.confidence = display
+		o.bands = bands
+	}
+}
+
+// csvHeader is the header row written by the CSV format
+var csvHeader = []string{"make", "model", "year", "capacity_kwh", "power_kw", "chemistry", "confidence", "source", "tags", "chemistry_source", "notes", "body_style", "authoritative"}
+
+// Formats lists every format Parse accepts
+var Formats = []Format{Text, JSON, CSV, Table, Markdown, Env}
+
+// Parse converts a format name into a Format
+func Parse(name string) (Format, error) {
+	if f := Format(name); slices.Contains(Formats, f) {
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format: %s", name)
+}
+
+// ContentType returns the HTTP media type for the format
+func ContentType(f Format) string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case Text, Table, Env:
+		return "text/plain; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Write writes the specs to w in the given format. JSON output is always an
+// array. The Env format is rejected, since a second spec would overwrite the
+// variables of the first.
+func Write(w io.Writer, f Format, specs []models.EVSpec, opts ...Option) error {
+	if f == Env {
+		return fmt.Errorf("the env format supports only single-spec output")
+	}
+	if specs == nil {
+		specs = []models.EVSpec{}
+	}
+	return write(w, f, specs, specs, opts)
+}
+
+// WriteSpec writes a single spec to w in the given format. JSON output is a
+// bare object rather than a one-element array.
+func WriteSpec(w io.Writer, f Format, spec *models.EVSpec, opts ...Option) error {
+	return write(w, f, []models.EVSpec{*spec}, spec, opts)
+}
+
+// write dispatches to the format writer; jsonValue is what the JSON format encodes
+func write(w io.Writer, f Format, specs []models.EVSpec, jsonValue any, opts []Option) error {
+	o := options{confidence: ConfidenceScore}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// JSON and env consumers get confidence_band whatever the display
+	if o.confidence != ConfidenceScore || f == JSON || f == Env {
+		bands := o.bands
+		if bands == (models.ConfidenceBands{}) {
+			bands = models.DefaultConfidenceBands
+		}
+		specs, jsonValue = withBands(specs, jsonValue, bands)
+	}
+
+	switch f {
+	case JSON:
+		if o.meta != nil {
+			jsonValue = Envelope{Meta: *o.meta, Results: specs}
+		}
+		return writeJSON(w, jsonValue, o.compact)
+	case CSV:
+		return writeCSV(w, specs)
+	case Text:
+		return writeText(w, specs, o)
+	case Table:
+		return writeTable(w, specs, o)
+	case Markdown:
+		return writeMarkdown(w, specs, o)
+	case Env:
+		return writeEnv(w, specs[0])
+	default:
+		return fmt.Errorf("unsupported format: %s", f)
+	}
+}
+
+// withBands returns copies of specs and jsonValue with ConfidenceBand set,
+// leaving the caller's specs untouched
+func withBands(specs []models.EVSpec, jsonValue any, bands models.ConfidenceBands) ([]models.EVSpec, any) {
+	banded := make([]models.EVSpec, len(specs))
+	for i, spec := range specs {
+		spec.ConfidenceBand = bands.Band(spec.Confidence)
+		banded[i] = spec
+	}
+	if _, single := jsonValue.(*models.EVSpec); single {
+		return banded, &banded[0]
+	}
+	return banded, banded
+}
+
+// confidenceLabel returns the confidence as display says, e.g. "high (0.93)"
+func confidenceLabel(spec models.EVSpec, display ConfidenceDisplay) string {
+	score := strconv.FormatFloat(spec.Confidence, 'f', 2, 64)
+	switch {
+	case spec.ConfidenceBand == "" || display == ConfidenceScore:
+		return score
+	case display == ConfidenceBand:
+		return spec.ConfidenceBand
+	default:
+		return spec.ConfidenceBand + " (" + score + ")"
+	}
+}
+
+// writeJSON encodes v as indented JSON, or on one line if compact
+func writeJSON(w io.Writer, v any, compact bool) error {
+	encoder := json.NewEncoder(w)
+	if !compact {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// writeCSV writes a header row followed by one row per spec
+func writeCSV(w io.Writer, specs []models.EVSpec) error {
+	cw := NewCSVWriter(w)
+	for _, spec := range specs {
+		if err := cw.Write(spec); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// CSVWriter streams specs as CSV one row at a time, in the same layout as the
+// CSV format, for output too large to collect into a slice first
+type CSVWriter struct {
+	cw     *csv.Writer
+	header bool // header row written
+}
+
+// NewCSVWriter returns a CSVWriter that writes to w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{cw: csv.NewWriter(w)}
+}
+
+// writeHeader writes the header row once
+func (c *CSVWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	if err := c.cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return nil
+}
+
+// Write writes one spec, preceded by the header row on the first call
+func (c *CSVWriter) Write(spec models.EVSpec) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	record := []string{
+		spec.Make,
+		spec.Model,
+		strconv.Itoa(spec.Year),
+		strconv.FormatFloat(spec.Capacity, 'f', 1, 64),
+		strconv.FormatFloat(spec.Power, 'f', 1, 64),
+		spec.Chemistry,
+		strconv.FormatFloat(spec.Confidence, 'f', 2, 64),
+		spec.Source,
+		strings.Join(spec.Tags, ";"),
+		spec.ChemistrySource,
+		spec.Notes,
+		spec.BodyStyle,
+		strconv.FormatBool(spec.Authoritative),
+	}
+	if err := c.cw.Write(record); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered rows, and the header row if no spec was written
+func (c *CSVWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+// chemistryLabel returns the chemistry, suffixed with "(inferred)" if it was
+// guessed or "(prior)" if it came from a prior
+func chemistryLabel(spec models.EVSpec) string {
+	return spec.Chemistry + sourceSuffix(spec.ChemistrySource)
+}
+
+// capacityLabel returns the capacity, suffixed with "(prior)" if it came from
+// a prior
+func capacityLabel(spec models.EVSpec) string {
+	return strconv.FormatFloat(spec.Capacity, 'f', 1, 64) + sourceSuffix(spec.CapacitySource)
+}
+
+// sourceSuffix labels a field that was not reported, e.g. " (prior)"
+func sourceSuffix(source string) string {
+	switch source {
+	case models.ChemistryInferred, models.SourcePrior:
+		return " (" + source + ")"
+	}
+	return ""
+}
+
+// candidateList formats ranked chemistry candidates, e.g. "NMC 70%, LFP 30%"
+func candidateList(candidates []models.ChemistryCandidate) string {
+	parts := make([]string, len(candidates))
+	for i, c := range candidates {
+		parts[i] = fmt.Sprintf("%s %.0f%%", c.Name, c.Prob*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeText writes each spec as an aligned block, separated by blank lines
+func writeText(w io.Writer, specs []models.EVSpec, o options) error {
+	for i, spec := range specs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Make:       %s\n", spec.Make)
+		fmt.Fprintf(w, "Model:      %s\n", spec.Model)
+		fmt.Fprintf(w, "Year:       %d\n", spec.Year)
+		fmt.Fprintf(w, "Capacity:   %.1f kWh%s\n", spec.Capacity, sourceSuffix(spec.CapacitySource))
+		fmt.Fprintf(w, "Power:      %.1f kW\n", spec.Power)
+		fmt.Fprintf(w, "Chemistry:  %s\n", chemistryLabel(spec))
+		if o.verbose && len(spec.ChemistryAlternatives) > 0 {
+			fmt.Fprintf(w, "Candidates: %s\n", candidateList(spec.ChemistryAlternatives))
+		}
+		fmt.Fprintf(w, "Confidence: %s\n", confidenceLabel(spec, o.confidence))
+		if len(spec.Tags) > 0 {
+			fmt.Fprintf(w, "Tags:       %s\n", strings.Join(spec.Tags, ", "))
+		}
+		if spec.BodyStyle != "" {
+			fmt.Fprintf(w, "Body style: %s\n", spec.BodyStyle)
+		}
+		if spec.Notes != "" {
+			fmt.Fprintf(w, "Notes:      %s\n", spec.Notes)
+		}
+		if _, err := fmt.Fprintf(w, "Source:     %s\n", spec.Source); err != nil {
+			return fmt.Errorf("failed to write text: %w", err)
+		}
+	}
+	return nil
+}
+
+// ParseTemplate parses a user-supplied text/template for rendering EV specs.
+// The template is executed once against an empty spec so that references to
+// unknown fields are reported at parse time rather than mid-output.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, models.EVSpec{}); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplate renders each spec with tmpl, one result per line
+func WriteTemplate(w io.Writer, tmpl *template.Template, specs []models.EVSpec) error {
+	for _, spec := range specs {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, spec); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return nil
+}
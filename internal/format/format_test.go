@@ -0,0 +1,48 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestJSONAlwaysHasConfidenceBand(t *testing.T) {
+	spec := &models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Confidence: 0.5}
+
+	for name, opts := range map[string][]Option{
+		"no options":    nil,
+		"score display": {WithConfidenceBands(ConfidenceScore, models.ConfidenceBands{High: 0.9, Medium: 0.4})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSpec(&buf, JSON, spec, opts...); err != nil {
+				t.Fatalf("WriteSpec: %v", err)
+			}
+			want := `"confidence_band": "low"`
+			if opts != nil {
+				want = `"confidence_band": "medium"`
+			}
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("JSON output is missing %s:\n%s", want, buf.String())
+			}
+		})
+	}
+
+	if spec.ConfidenceBand != "" {
+		t.Errorf("WriteSpec set the caller's band to %q", spec.ConfidenceBand)
+	}
+}
+
+func TestTextShowsBandOnlyWhenDisplayed(t *testing.T) {
+	spec := &models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Confidence: 0.5}
+
+	var buf bytes.Buffer
+	if err := WriteSpec(&buf, Text, spec, WithConfidenceBands(ConfidenceScore, models.DefaultConfidenceBands)); err != nil {
+		t.Fatalf("WriteSpec: %v", err)
+	}
+	if strings.Contains(buf.String(), "low") {
+		t.Errorf("text output shows the band with the score display:\n%s", buf.String())
+	}
+}
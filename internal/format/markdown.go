@@ -0,0 +1,41 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// markdownEscaper keeps cell values from breaking the table: pipes would
+// start a new column and newlines a new row
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\n", " ")
+
+// writeMarkdown writes the specs as a GitHub-flavored markdown table using
+// the same columns as the ASCII table
+func writeMarkdown(w io.Writer, specs []models.EVSpec, o options) error {
+	columns := tableColumns(o.confidence)
+	var sb strings.Builder
+	writeMarkdownRow(&sb, len(columns), func(i int) string { return columns[i].header })
+	writeMarkdownRow(&sb, len(columns), func(int) string { return "---" })
+	for _, spec := range specs {
+		writeMarkdownRow(&sb, len(columns), func(i int) string {
+			return markdownEscaper.Replace(columns[i].value(spec))
+		})
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write markdown: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownRow writes one "| a | b |" row of n cells
+func writeMarkdownRow(sb *strings.Builder, n int, cell func(i int) string) {
+	sb.WriteString("|")
+	for i := range n {
+		sb.WriteString(" " + cell(i) + " |")
+	}
+	sb.WriteByte('\n')
+}
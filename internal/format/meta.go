@@ -0,0 +1,36 @@
+package format
+
+import (
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Meta records when and how a JSON result was produced
+type Meta struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Version     string     `json:"version"`
+	Providers   []Provider `json:"providers"`
+}
+
+// Provider is one embedding or LLM provider that served the results
+type Provider struct {
+	Kind     string `json:"kind"` // "embedding" or "llm"
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+}
+
+// Envelope is the JSON output written with WithMeta. Results is always an
+// array, even for single-spec output, so every command has the same shape.
+type Envelope struct {
+	Meta
+	Results []models.EVSpec `json:"results"`
+}
+
+// WithMeta wraps JSON output in an Envelope carrying meta. Other formats are
+// unaffected.
+func WithMeta(meta Meta) Option {
+	return func(o *options) {
+		o.meta = &meta
+	}
+}
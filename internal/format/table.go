@@ -0,0 +1,121 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// ANSI escape sequences used to highlight table cells
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// column describes one column of tabular output
+type column struct {
+	header string
+	value  func(spec models.EVSpec) string
+	color  func(spec models.EVSpec) string // ANSI color for the cell, or ""
+}
+
+// tableColumns returns the column layout for tabular formats, showing
+// confidence as display says
+func tableColumns(display ConfidenceDisplay) []column {
+	return []column{
+		{header: "MAKE", value: func(s models.EVSpec) string { return s.Make }},
+		{header: "MODEL", value: func(s models.EVSpec) string { return s.Model }},
+		{header: "YEAR", value: func(s models.EVSpec) string { return strconv.Itoa(s.Year) }},
+		{header: "CAPACITY (kWh)", value: capacityLabel},
+		{header: "POWER (kW)", value: func(s models.EVSpec) string { return strconv.FormatFloat(s.Power, 'f', 1, 64) }},
+		{header: "CHEMISTRY", value: chemistryLabel},
+		{
+			header: "CONFIDENCE",
+			value:  func(s models.EVSpec) string { return confidenceLabel(s, display) },
+			color:  confidenceColor,
+		},
+		{header: "SOURCE", value: func(s models.EVSpec) string { return s.Source }, color: sourceColor},
+	}
+}
+
+// sourceColor highlights database results in green and LLM results in yellow
+func sourceColor(spec models.EVSpec) string {
+	switch spec.Source {
+	case "database":
+		return ansiGreen
+	case "llm":
+		return ansiYellow
+	default:
+		return ""
+	}
+}
+
+// confidenceColor highlights confidence below the database threshold in red
+func confidenceColor(spec models.EVSpec) string {
+	if spec.Confidence < models.ConfidenceThreshold {
+		return ansiRed
+	}
+	return ""
+}
+
+// writeTable writes the specs as an aligned ASCII table
+func writeTable(w io.Writer, specs []models.EVSpec, o options) error {
+	columns := tableColumns(o.confidence)
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = utf8.RuneCountInString(col.header)
+		for _, spec := range specs {
+			widths[i] = max(widths[i], utf8.RuneCountInString(col.value(spec)))
+		}
+	}
+
+	var sb strings.Builder
+	for i, col := range columns {
+		writeCell(&sb, col.header, widths[i], i == len(columns)-1, "")
+	}
+	for i := range columns {
+		if i > 0 {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(strings.Repeat("-", widths[i]))
+	}
+	sb.WriteByte('\n')
+
+	for _, spec := range specs {
+		for i, col := range columns {
+			cellColor := ""
+			if o.color && col.color != nil {
+				cellColor = col.color(spec)
+			}
+			writeCell(&sb, col.value(spec), widths[i], i == len(columns)-1, cellColor)
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
+
+// writeCell pads value to width before coloring it, so escape codes never
+// throw off the alignment
+func writeCell(sb *strings.Builder, value string, width int, last bool, color string) {
+	padded := value
+	if !last {
+		padded += strings.Repeat(" ", width-utf8.RuneCountInString(value)+2)
+	}
+	if color != "" {
+		sb.WriteString(color + value + ansiReset + padded[len(value):])
+	} else {
+		sb.WriteString(padded)
+	}
+	if last {
+		sb.WriteByte('\n')
+	}
+}
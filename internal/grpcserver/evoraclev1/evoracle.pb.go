@@ -0,0 +1,700 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        (unknown)
+// source: evoracle/v1/evoracle.proto
+
+package evoraclev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// OnConflict is what AddSpec does when the spec is already stored
+type OnConflict int32
+
+const (
+	// Fail with ALREADY_EXISTS
+	OnConflict_ON_CONFLICT_ERROR OnConflict = 0
+	// Leave the stored spec alone and report it as skipped
+	OnConflict_ON_CONFLICT_SKIP OnConflict = 1
+	// Overwrite the stored spec, unless it is authoritative and the new one is not
+	OnConflict_ON_CONFLICT_UPDATE OnConflict = 2
+)
+
+// Enum value maps for OnConflict.
+var (
+	OnConflict_name = map[int32]string{
+		0: "ON_CONFLICT_ERROR",
+		1: "ON_CONFLICT_SKIP",
+		2: "ON_CONFLICT_UPDATE",
+	}
+	OnConflict_value = map[string]int32{
+		"ON_CONFLICT_ERROR":  0,
+		"ON_CONFLICT_SKIP":   1,
+		"ON_CONFLICT_UPDATE": 2,
+	}
+)
+
+func (x OnConflict) Enum() *OnConflict {
+	p := new(OnConflict)
+	*p = x
+	return p
+}
+
+func (x OnConflict) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OnConflict) Descriptor() protoreflect.EnumDescriptor {
+	return file_evoracle_v1_evoracle_proto_enumTypes[0].Descriptor()
+}
+
+func (OnConflict) Type() protoreflect.EnumType {
+	return &file_evoracle_v1_evoracle_proto_enumTypes[0]
+}
+
+func (x OnConflict) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OnConflict.Descriptor instead.
+func (OnConflict) EnumDescriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{0}
+}
+
+// Spec holds the battery specifications of one vehicle
+type Spec struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Make  string                 `protobuf:"bytes,1,opt,name=make,proto3" json:"make,omitempty"`
+	Model string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Year  int32                  `protobuf:"varint,3,opt,name=year,proto3" json:"year,omitempty"`
+	// Battery capacity in kWh
+	CapacityKwh float64 `protobuf:"fixed64,4,opt,name=capacity_kwh,json=capacityKwh,proto3" json:"capacity_kwh,omitempty"`
+	// Power output in kW
+	PowerKw float64 `protobuf:"fixed64,5,opt,name=power_kw,json=powerKw,proto3" json:"power_kw,omitempty"`
+	// Battery chemistry, e.g. NMC or LFP
+	Chemistry string `protobuf:"bytes,6,opt,name=chemistry,proto3" json:"chemistry,omitempty"`
+	// Confidence between 0 and 1; on AddSpec, 0 derives it from the source
+	Confidence float64 `protobuf:"fixed64,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// Where the spec came from, e.g. database, llm or manufacturer; AddSpec
+	// defaults to database
+	Source string   `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	Tags   []string `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Notes  string   `protobuf:"bytes,10,opt,name=notes,proto3" json:"notes,omitempty"`
+	// Normalized body style such as SUV or Sedan; aliases are normalized on AddSpec
+	BodyStyle string `protobuf:"bytes,11,opt,name=body_style,json=bodyStyle,proto3" json:"body_style,omitempty"`
+	// "inferred" or "prior" when the chemistry was not reported; ignored on AddSpec
+	ChemistrySource string `protobuf:"bytes,12,opt,name=chemistry_source,json=chemistrySource,proto3" json:"chemistry_source,omitempty"`
+	// "prior" when the capacity came from a configured prior; ignored on AddSpec
+	CapacitySource string `protobuf:"bytes,13,opt,name=capacity_source,json=capacitySource,proto3" json:"capacity_source,omitempty"`
+	// Confidence as "high", "medium" or "low"; ignored on AddSpec
+	ConfidenceBand string `protobuf:"bytes,14,opt,name=confidence_band,json=confidenceBand,proto3" json:"confidence_band,omitempty"`
+	// Hand-verified spec that saved LLM answers never overwrite
+	Authoritative bool `protobuf:"varint,15,opt,name=authoritative,proto3" json:"authoritative,omitempty"`
+	// Chemistries the LLM considered, most likely first, when requested;
+	// ignored on AddSpec
+	ChemistryAlternatives []*ChemistryCandidate `protobuf:"bytes,16,rep,name=chemistry_alternatives,json=chemistryAlternatives,proto3" json:"chemistry_alternatives,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Spec) Reset() {
+	*x = Spec{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Spec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Spec) ProtoMessage() {}
+
+func (x *Spec) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Spec.ProtoReflect.Descriptor instead.
+func (*Spec) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Spec) GetMake() string {
+	if x != nil {
+		return x.Make
+	}
+	return ""
+}
+
+func (x *Spec) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Spec) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Spec) GetCapacityKwh() float64 {
+	if x != nil {
+		return x.CapacityKwh
+	}
+	return 0
+}
+
+func (x *Spec) GetPowerKw() float64 {
+	if x != nil {
+		return x.PowerKw
+	}
+	return 0
+}
+
+func (x *Spec) GetChemistry() string {
+	if x != nil {
+		return x.Chemistry
+	}
+	return ""
+}
+
+func (x *Spec) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Spec) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Spec) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Spec) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Spec) GetBodyStyle() string {
+	if x != nil {
+		return x.BodyStyle
+	}
+	return ""
+}
+
+func (x *Spec) GetChemistrySource() string {
+	if x != nil {
+		return x.ChemistrySource
+	}
+	return ""
+}
+
+func (x *Spec) GetCapacitySource() string {
+	if x != nil {
+		return x.CapacitySource
+	}
+	return ""
+}
+
+func (x *Spec) GetConfidenceBand() string {
+	if x != nil {
+		return x.ConfidenceBand
+	}
+	return ""
+}
+
+func (x *Spec) GetAuthoritative() bool {
+	if x != nil {
+		return x.Authoritative
+	}
+	return false
+}
+
+func (x *Spec) GetChemistryAlternatives() []*ChemistryCandidate {
+	if x != nil {
+		return x.ChemistryAlternatives
+	}
+	return nil
+}
+
+// ChemistryCandidate is one possible chemistry with the LLM's probability
+type ChemistryCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Probability   float64                `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChemistryCandidate) Reset() {
+	*x = ChemistryCandidate{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChemistryCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChemistryCandidate) ProtoMessage() {}
+
+func (x *ChemistryCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChemistryCandidate.ProtoReflect.Descriptor instead.
+func (*ChemistryCandidate) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChemistryCandidate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChemistryCandidate) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+type GetSpecRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Make          string                 `protobuf:"bytes,1,opt,name=make,proto3" json:"make,omitempty"`
+	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Year          int32                  `protobuf:"varint,3,opt,name=year,proto3" json:"year,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSpecRequest) Reset() {
+	*x = GetSpecRequest{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpecRequest) ProtoMessage() {}
+
+func (x *GetSpecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpecRequest.ProtoReflect.Descriptor instead.
+func (*GetSpecRequest) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSpecRequest) GetMake() string {
+	if x != nil {
+		return x.Make
+	}
+	return ""
+}
+
+func (x *GetSpecRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GetSpecRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Make  string                 `protobuf:"bytes,1,opt,name=make,proto3" json:"make,omitempty"`
+	Model string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Year  int32                  `protobuf:"varint,3,opt,name=year,proto3" json:"year,omitempty"`
+	// Number of matches to return: 0 for the default of 5, at most 100
+	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchRequest) GetMake() string {
+	if x != nil {
+		return x.Make
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Matches ordered by similarity, most similar first
+	Specs         []*Spec `protobuf:"bytes,1,rep,name=specs,proto3" json:"specs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResponse) GetSpecs() []*Spec {
+	if x != nil {
+		return x.Specs
+	}
+	return nil
+}
+
+type AddSpecRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Spec          *Spec                  `protobuf:"bytes,1,opt,name=spec,proto3" json:"spec,omitempty"`
+	OnConflict    OnConflict             `protobuf:"varint,2,opt,name=on_conflict,json=onConflict,proto3,enum=evoracle.v1.OnConflict" json:"on_conflict,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSpecRequest) Reset() {
+	*x = AddSpecRequest{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSpecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSpecRequest) ProtoMessage() {}
+
+func (x *AddSpecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSpecRequest.ProtoReflect.Descriptor instead.
+func (*AddSpecRequest) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AddSpecRequest) GetSpec() *Spec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *AddSpecRequest) GetOnConflict() OnConflict {
+	if x != nil {
+		return x.OnConflict
+	}
+	return OnConflict_ON_CONFLICT_ERROR
+}
+
+type AddSpecResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// True when on_conflict is ON_CONFLICT_SKIP and the spec was already stored
+	Skipped       bool `protobuf:"varint,1,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSpecResponse) Reset() {
+	*x = AddSpecResponse{}
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSpecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSpecResponse) ProtoMessage() {}
+
+func (x *AddSpecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_evoracle_v1_evoracle_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSpecResponse.ProtoReflect.Descriptor instead.
+func (*AddSpecResponse) Descriptor() ([]byte, []int) {
+	return file_evoracle_v1_evoracle_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddSpecResponse) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+var File_evoracle_v1_evoracle_proto protoreflect.FileDescriptor
+
+const file_evoracle_v1_evoracle_proto_rawDesc = "" +
+	"\n" +
+	"\x1aevoracle/v1/evoracle.proto\x12\vevoracle.v1\"\x9c\x04\n" +
+	"\x04Spec\x12\x12\n" +
+	"\x04make\x18\x01 \x01(\tR\x04make\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x12\n" +
+	"\x04year\x18\x03 \x01(\x05R\x04year\x12!\n" +
+	"\fcapacity_kwh\x18\x04 \x01(\x01R\vcapacityKwh\x12\x19\n" +
+	"\bpower_kw\x18\x05 \x01(\x01R\apowerKw\x12\x1c\n" +
+	"\tchemistry\x18\x06 \x01(\tR\tchemistry\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\a \x01(\x01R\n" +
+	"confidence\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12\x14\n" +
+	"\x05notes\x18\n" +
+	" \x01(\tR\x05notes\x12\x1d\n" +
+	"\n" +
+	"body_style\x18\v \x01(\tR\tbodyStyle\x12)\n" +
+	"\x10chemistry_source\x18\f \x01(\tR\x0fchemistrySource\x12'\n" +
+	"\x0fcapacity_source\x18\r \x01(\tR\x0ecapacitySource\x12'\n" +
+	"\x0fconfidence_band\x18\x0e \x01(\tR\x0econfidenceBand\x12$\n" +
+	"\rauthoritative\x18\x0f \x01(\bR\rauthoritative\x12V\n" +
+	"\x16chemistry_alternatives\x18\x10 \x03(\v2\x1f.evoracle.v1.ChemistryCandidateR\x15chemistryAlternatives\"J\n" +
+	"\x12ChemistryCandidate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"N\n" +
+	"\x0eGetSpecRequest\x12\x12\n" +
+	"\x04make\x18\x01 \x01(\tR\x04make\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x12\n" +
+	"\x04year\x18\x03 \x01(\x05R\x04year\"c\n" +
+	"\rSearchRequest\x12\x12\n" +
+	"\x04make\x18\x01 \x01(\tR\x04make\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x12\n" +
+	"\x04year\x18\x03 \x01(\x05R\x04year\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"9\n" +
+	"\x0eSearchResponse\x12'\n" +
+	"\x05specs\x18\x01 \x03(\v2\x11.evoracle.v1.SpecR\x05specs\"q\n" +
+	"\x0eAddSpecRequest\x12%\n" +
+	"\x04spec\x18\x01 \x01(\v2\x11.evoracle.v1.SpecR\x04spec\x128\n" +
+	"\von_conflict\x18\x02 \x01(\x0e2\x17.evoracle.v1.OnConflictR\n" +
+	"onConflict\"+\n" +
+	"\x0fAddSpecResponse\x12\x18\n" +
+	"\askipped\x18\x01 \x01(\bR\askipped*Q\n" +
+	"\n" +
+	"OnConflict\x12\x15\n" +
+	"\x11ON_CONFLICT_ERROR\x10\x00\x12\x14\n" +
+	"\x10ON_CONFLICT_SKIP\x10\x01\x12\x16\n" +
+	"\x12ON_CONFLICT_UPDATE\x10\x022\xce\x01\n" +
+	"\bEVOracle\x129\n" +
+	"\aGetSpec\x12\x1b.evoracle.v1.GetSpecRequest\x1a\x11.evoracle.v1.Spec\x12A\n" +
+	"\x06Search\x12\x1a.evoracle.v1.SearchRequest\x1a\x1b.evoracle.v1.SearchResponse\x12D\n" +
+	"\aAddSpec\x12\x1b.evoracle.v1.AddSpecRequest\x1a\x1c.evoracle.v1.AddSpecResponseBLZJgithub.com/scaryPonens/ev-oracle/internal/grpcserver/evoraclev1;evoraclev1b\x06proto3"
+
+var (
+	file_evoracle_v1_evoracle_proto_rawDescOnce sync.Once
+	file_evoracle_v1_evoracle_proto_rawDescData []byte
+)
+
+func file_evoracle_v1_evoracle_proto_rawDescGZIP() []byte {
+	file_evoracle_v1_evoracle_proto_rawDescOnce.Do(func() {
+		file_evoracle_v1_evoracle_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_evoracle_v1_evoracle_proto_rawDesc), len(file_evoracle_v1_evoracle_proto_rawDesc)))
+	})
+	return file_evoracle_v1_evoracle_proto_rawDescData
+}
+
+var file_evoracle_v1_evoracle_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_evoracle_v1_evoracle_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_evoracle_v1_evoracle_proto_goTypes = []any{
+	(OnConflict)(0),            // 0: evoracle.v1.OnConflict
+	(*Spec)(nil),               // 1: evoracle.v1.Spec
+	(*ChemistryCandidate)(nil), // 2: evoracle.v1.ChemistryCandidate
+	(*GetSpecRequest)(nil),     // 3: evoracle.v1.GetSpecRequest
+	(*SearchRequest)(nil),      // 4: evoracle.v1.SearchRequest
+	(*SearchResponse)(nil),     // 5: evoracle.v1.SearchResponse
+	(*AddSpecRequest)(nil),     // 6: evoracle.v1.AddSpecRequest
+	(*AddSpecResponse)(nil),    // 7: evoracle.v1.AddSpecResponse
+}
+var file_evoracle_v1_evoracle_proto_depIdxs = []int32{
+	2, // 0: evoracle.v1.Spec.chemistry_alternatives:type_name -> evoracle.v1.ChemistryCandidate
+	1, // 1: evoracle.v1.SearchResponse.specs:type_name -> evoracle.v1.Spec
+	1, // 2: evoracle.v1.AddSpecRequest.spec:type_name -> evoracle.v1.Spec
+	0, // 3: evoracle.v1.AddSpecRequest.on_conflict:type_name -> evoracle.v1.OnConflict
+	3, // 4: evoracle.v1.EVOracle.GetSpec:input_type -> evoracle.v1.GetSpecRequest
+	4, // 5: evoracle.v1.EVOracle.Search:input_type -> evoracle.v1.SearchRequest
+	6, // 6: evoracle.v1.EVOracle.AddSpec:input_type -> evoracle.v1.AddSpecRequest
+	1, // 7: evoracle.v1.EVOracle.GetSpec:output_type -> evoracle.v1.Spec
+	5, // 8: evoracle.v1.EVOracle.Search:output_type -> evoracle.v1.SearchResponse
+	7, // 9: evoracle.v1.EVOracle.AddSpec:output_type -> evoracle.v1.AddSpecResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_evoracle_v1_evoracle_proto_init() }
+func file_evoracle_v1_evoracle_proto_init() {
+	if File_evoracle_v1_evoracle_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_evoracle_v1_evoracle_proto_rawDesc), len(file_evoracle_v1_evoracle_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_evoracle_v1_evoracle_proto_goTypes,
+		DependencyIndexes: file_evoracle_v1_evoracle_proto_depIdxs,
+		EnumInfos:         file_evoracle_v1_evoracle_proto_enumTypes,
+		MessageInfos:      file_evoracle_v1_evoracle_proto_msgTypes,
+	}.Build()
+	File_evoracle_v1_evoracle_proto = out.File
+	file_evoracle_v1_evoracle_proto_goTypes = nil
+	file_evoracle_v1_evoracle_proto_depIdxs = nil
+}
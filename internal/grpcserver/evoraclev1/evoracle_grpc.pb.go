@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: evoracle/v1/evoracle.proto
+
+package evoraclev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	EVOracle_GetSpec_FullMethodName = "/evoracle.v1.EVOracle/GetSpec"
+	EVOracle_Search_FullMethodName  = "/evoracle.v1.EVOracle/Search"
+	EVOracle_AddSpec_FullMethodName = "/evoracle.v1.EVOracle/AddSpec"
+)
+
+// EVOracleClient is the client API for EVOracle service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// EVOracle resolves and stores EV battery specifications, like the HTTP
+// server started by `ev-oracle serve`.
+type EVOracleClient interface {
+	// GetSpec resolves a vehicle's spec through the same pipeline as the query
+	// command: exact match, vector search, then the LLM.
+	GetSpec(ctx context.Context, in *GetSpecRequest, opts ...grpc.CallOption) (*Spec, error)
+	// Search returns the stored specs most similar to a vehicle, without
+	// consulting the LLM.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// AddSpec embeds and stores a spec, like the add command.
+	AddSpec(ctx context.Context, in *AddSpecRequest, opts ...grpc.CallOption) (*AddSpecResponse, error)
+}
+
+type eVOracleClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEVOracleClient(cc grpc.ClientConnInterface) EVOracleClient {
+	return &eVOracleClient{cc}
+}
+
+func (c *eVOracleClient) GetSpec(ctx context.Context, in *GetSpecRequest, opts ...grpc.CallOption) (*Spec, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Spec)
+	err := c.cc.Invoke(ctx, EVOracle_GetSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eVOracleClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, EVOracle_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eVOracleClient) AddSpec(ctx context.Context, in *AddSpecRequest, opts ...grpc.CallOption) (*AddSpecResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddSpecResponse)
+	err := c.cc.Invoke(ctx, EVOracle_AddSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EVOracleServer is the server API for EVOracle service.
+// All implementations must embed UnimplementedEVOracleServer
+// for forward compatibility.
+//
+// EVOracle resolves and stores EV battery specifications, like the HTTP
+// server started by `ev-oracle serve`.
+type EVOracleServer interface {
+	// GetSpec resolves a vehicle's spec through the same pipeline as the query
+	// command: exact match, vector search, then the LLM.
+	GetSpec(context.Context, *GetSpecRequest) (*Spec, error)
+	// Search returns the stored specs most similar to a vehicle, without
+	// consulting the LLM.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// AddSpec embeds and stores a spec, like the add command.
+	AddSpec(context.Context, *AddSpecRequest) (*AddSpecResponse, error)
+	mustEmbedUnimplementedEVOracleServer()
+}
+
+// UnimplementedEVOracleServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEVOracleServer struct{}
+
+func (UnimplementedEVOracleServer) GetSpec(context.Context, *GetSpecRequest) (*Spec, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpec not implemented")
+}
+func (UnimplementedEVOracleServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedEVOracleServer) AddSpec(context.Context, *AddSpecRequest) (*AddSpecResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSpec not implemented")
+}
+func (UnimplementedEVOracleServer) mustEmbedUnimplementedEVOracleServer() {}
+func (UnimplementedEVOracleServer) testEmbeddedByValue()                  {}
+
+// UnsafeEVOracleServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EVOracleServer will
+// result in compilation errors.
+type UnsafeEVOracleServer interface {
+	mustEmbedUnimplementedEVOracleServer()
+}
+
+func RegisterEVOracleServer(s grpc.ServiceRegistrar, srv EVOracleServer) {
+	// If the following call pancis, it indicates UnimplementedEVOracleServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EVOracle_ServiceDesc, srv)
+}
+
+func _EVOracle_GetSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EVOracleServer).GetSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EVOracle_GetSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EVOracleServer).GetSpec(ctx, req.(*GetSpecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EVOracle_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EVOracleServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EVOracle_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EVOracleServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EVOracle_AddSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSpecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EVOracleServer).AddSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EVOracle_AddSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EVOracleServer).AddSpec(ctx, req.(*AddSpecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EVOracle_ServiceDesc is the grpc.ServiceDesc for EVOracle service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EVOracle_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "evoracle.v1.EVOracle",
+	HandlerType: (*EVOracleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSpec",
+			Handler:    _EVOracle_GetSpec_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _EVOracle_Search_Handler,
+		},
+		{
+			MethodName: "AddSpec",
+			Handler:    _EVOracle_AddSpec_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "evoracle/v1/evoracle.proto",
+}
@@ -0,0 +1,210 @@
+// Package grpcserver exposes the resolver as the EVOracle gRPC service
+// defined in proto/evoracle/v1/evoracle.proto.
+package grpcserver
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/scaryPonens/ev-oracle --go-grpc_out=../.. --go-grpc_opt=module=github.com/scaryPonens/ev-oracle evoracle/v1/evoracle.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/grpcserver/evoraclev1"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 100
+)
+
+// Server implements the EVOracle service on top of a resolver
+type Server struct {
+	evoraclev1.UnimplementedEVOracleServer
+
+	resolver *resolver.Resolver
+	bands    models.ConfidenceBands
+}
+
+// Option is a functional option for configuring the server
+type Option func(*Server)
+
+// WithConfidenceBands sets the thresholds used for the confidence_band of
+// returned specs (default: models.DefaultConfidenceBands)
+func WithConfidenceBands(bands models.ConfidenceBands) Option {
+	return func(s *Server) {
+		s.bands = bands
+	}
+}
+
+// New creates a new gRPC service that resolves lookups with res and stores
+// added specs in its store
+func New(res *resolver.Resolver, opts ...Option) *Server {
+	s := &Server{
+		resolver: res,
+		bands:    models.DefaultConfidenceBands,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register registers the EVOracle service on gs, along with the reflection
+// service so tools such as grpcurl can discover it
+func (s *Server) Register(gs *grpc.Server) {
+	evoraclev1.RegisterEVOracleServer(gs, s)
+	reflection.Register(gs)
+}
+
+// GetSpec resolves a single spec through the exact, vector and LLM stages
+func (s *Server) GetSpec(ctx context.Context, req *evoraclev1.GetSpecRequest) (*evoraclev1.Spec, error) {
+	spec, err := s.resolver.Resolve(ctx, req.GetMake(), req.GetModel(), int(req.GetYear()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.toProto(spec), nil
+}
+
+// Search returns the stored specs most similar to the requested vehicle
+func (s *Server) Search(ctx context.Context, req *evoraclev1.SearchRequest) (*evoraclev1.SearchResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(max(limit, 1), maxSearchLimit)
+
+	specs, err := s.resolver.Search(ctx, req.GetMake(), req.GetModel(), int(req.GetYear()), limit)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &evoraclev1.SearchResponse{Specs: make([]*evoraclev1.Spec, len(specs))}
+	for i := range specs {
+		resp.Specs[i] = s.toProto(&specs[i])
+	}
+	return resp, nil
+}
+
+// AddSpec validates, embeds and stores a spec, as the add command does
+func (s *Server) AddSpec(ctx context.Context, req *evoraclev1.AddSpecRequest) (*evoraclev1.AddSpecResponse, error) {
+	if req.GetSpec() == nil {
+		return nil, status.Error(codes.InvalidArgument, "spec is required")
+	}
+	spec := fromProto(req.GetSpec())
+
+	// Reject malformed specs before any embedding call
+	if err := models.ValidateQuery(spec.Make, spec.Model, spec.Year); err != nil {
+		return nil, statusError(err)
+	}
+	bodyStyle, err := models.NormalizeBodyStyle(spec.BodyStyle)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid body_style: %v", err)
+	}
+	spec.BodyStyle = bodyStyle
+	if err := models.ValidateSpec(spec); err != nil {
+		return nil, statusError(err)
+	}
+
+	policy := db.ConflictError
+	switch req.GetOnConflict() {
+	case evoraclev1.OnConflict_ON_CONFLICT_SKIP:
+		policy = db.ConflictSkip
+	case evoraclev1.OnConflict_ON_CONFLICT_UPDATE:
+		policy = db.ConflictUpdate
+	}
+
+	documentText, err := s.resolver.Embedding().DocumentText(spec.Make, spec.Model, spec.Year, spec.BodyStyle, spec.Notes)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	embeddingVector, err := s.resolver.Embedding().GetEmbedding(ctx, documentText)
+	if err != nil {
+		return nil, statusError(fmt.Errorf("failed to generate embedding: %w", err))
+	}
+
+	err = s.resolver.Store().InsertEVSpec(ctx, spec, embeddingVector, db.WithConflictPolicy(policy))
+	switch {
+	case errors.Is(err, db.ErrSpecExists) && policy == db.ConflictSkip:
+		return &evoraclev1.AddSpecResponse{Skipped: true}, nil
+	case err != nil:
+		return nil, statusError(err)
+	}
+	return &evoraclev1.AddSpecResponse{}, nil
+}
+
+// statusError converts a resolver or store error into a gRPC status
+func statusError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		code = codes.InvalidArgument
+	case errors.Is(err, resolver.ErrNotElectric), errors.Is(err, resolver.ErrBeforeProduction),
+		errors.Is(err, db.ErrAuthoritative), errors.Is(err, db.ErrReadOnly):
+		code = codes.FailedPrecondition
+	case errors.Is(err, db.ErrSpecExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// toProto converts a resolved or stored spec into its message, setting its
+// confidence band
+func (s *Server) toProto(spec *models.EVSpec) *evoraclev1.Spec {
+	msg := &evoraclev1.Spec{
+		Make:            spec.Make,
+		Model:           spec.Model,
+		Year:            int32(spec.Year),
+		CapacityKwh:     spec.Capacity,
+		PowerKw:         spec.Power,
+		Chemistry:       spec.Chemistry,
+		Confidence:      spec.Confidence,
+		Source:          spec.Source,
+		Tags:            spec.Tags,
+		Notes:           spec.Notes,
+		BodyStyle:       spec.BodyStyle,
+		ChemistrySource: spec.ChemistrySource,
+		CapacitySource:  spec.CapacitySource,
+		ConfidenceBand:  s.bands.Band(spec.Confidence),
+		Authoritative:   spec.Authoritative,
+	}
+	for _, c := range spec.ChemistryAlternatives {
+		msg.ChemistryAlternatives = append(msg.ChemistryAlternatives, &evoraclev1.ChemistryCandidate{Name: c.Name, Probability: c.Prob})
+	}
+	return msg
+}
+
+// fromProto converts an AddSpec message into a spec to store, keeping only
+// the fields the add command accepts
+func fromProto(msg *evoraclev1.Spec) *models.EVSpec {
+	source := strings.TrimSpace(msg.GetSource())
+	if source == "" {
+		source = "database"
+	}
+	return &models.EVSpec{
+		Make:          msg.GetMake(),
+		Model:         msg.GetModel(),
+		Year:          int(msg.GetYear()),
+		Capacity:      msg.GetCapacityKwh(),
+		Power:         msg.GetPowerKw(),
+		Chemistry:     strings.TrimSpace(msg.GetChemistry()),
+		Tags:          msg.GetTags(),
+		Notes:         strings.TrimSpace(msg.GetNotes()),
+		BodyStyle:     msg.GetBodyStyle(),
+		Source:        source,
+		Confidence:    msg.GetConfidence(),
+		Authoritative: msg.GetAuthoritative(),
+	}
+}
@@ -0,0 +1,256 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/grpcserver/evoraclev1"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// providerCalls counts the requests a fake Ollama server receives
+type providerCalls struct {
+	embed, generate atomic.Int32
+}
+
+// newClient starts the service over an in-memory connection, backed by a
+// json store and a fake Ollama server for embeddings and LLM answers
+func newClient(t *testing.T) (evoraclev1.EVOracleClient, *grpc.ClientConn, *providerCalls) {
+	t.Helper()
+	calls := &providerCalls{}
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch r.URL.Path {
+		case "/api/embed":
+			calls.embed.Add(1)
+			fmt.Fprint(w, `{"embeddings": [[0.1, 0.2, 0.3]]}`)
+		case "/api/generate":
+			calls.generate.Add(1)
+			fmt.Fprint(w, `{"response": "Capacity: 82 kWh\nPower: 340 kW\nChemistry: NMC"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ollama.Close)
+
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	res := resolver.New(store,
+		embedding.NewWithProvider(embedding.ProviderOllama, "", ollama.URL, "test"),
+		llm.NewWithProvider(llm.ProviderOllama, "", ollama.URL, "test"))
+
+	listener := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	New(res).Register(gs)
+	go gs.Serve(listener)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return evoraclev1.NewEVOracleClient(conn), conn, calls
+}
+
+func model3(capacity float64) *evoraclev1.Spec {
+	return &evoraclev1.Spec{Make: "Tesla", Model: "Model 3", Year: 2023, CapacityKwh: capacity, PowerKw: 283, Chemistry: "NMC", BodyStyle: "sedan"}
+}
+
+func TestAddSpecThenGetSpecAndSearch(t *testing.T) {
+	client, _, calls := newClient(t)
+	ctx := context.Background()
+
+	if _, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(75)}); err != nil {
+		t.Fatalf("AddSpec failed: %v", err)
+	}
+
+	spec, err := client.GetSpec(ctx, &evoraclev1.GetSpecRequest{Make: "Tesla", Model: "Model 3", Year: 2023})
+	if err != nil {
+		t.Fatalf("GetSpec failed: %v", err)
+	}
+	if spec.GetCapacityKwh() != 75 || spec.GetSource() != "database" || spec.GetBodyStyle() != "Sedan" {
+		t.Errorf("GetSpec = %v, want the added spec with source database and body style Sedan", spec)
+	}
+	if spec.GetConfidenceBand() != "high" {
+		t.Errorf("confidence band = %q for confidence %v, want high", spec.GetConfidenceBand(), spec.GetConfidence())
+	}
+	if n := calls.generate.Load(); n != 0 {
+		t.Errorf("LLM was called %d times for a stored spec", n)
+	}
+
+	resp, err := client.Search(ctx, &evoraclev1.SearchRequest{Make: "Tesla", Model: "Model Y", Year: 2023})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(resp.GetSpecs()) != 1 || resp.GetSpecs()[0].GetModel() != "Model 3" {
+		t.Errorf("Search = %v, want the stored Model 3", resp.GetSpecs())
+	}
+}
+
+func TestGetSpecFallsBackToLLM(t *testing.T) {
+	client, _, calls := newClient(t)
+
+	spec, err := client.GetSpec(context.Background(), &evoraclev1.GetSpecRequest{Make: "Rivian", Model: "R1T", Year: 2023})
+	if err != nil {
+		t.Fatalf("GetSpec failed: %v", err)
+	}
+	if spec.GetSource() != "llm" || spec.GetCapacityKwh() != 82 || spec.GetChemistry() != "NMC" {
+		t.Errorf("GetSpec = %v, want the LLM's answer", spec)
+	}
+	if n := calls.generate.Load(); n != 1 {
+		t.Errorf("LLM was called %d times, want 1", n)
+	}
+}
+
+func TestAddSpecConflicts(t *testing.T) {
+	client, _, _ := newClient(t)
+	ctx := context.Background()
+	if _, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(75)}); err != nil {
+		t.Fatalf("AddSpec failed: %v", err)
+	}
+
+	_, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(78)})
+	if code := status.Code(err); code != codes.AlreadyExists {
+		t.Errorf("second AddSpec code = %v (%v), want AlreadyExists", code, err)
+	}
+
+	resp, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(78), OnConflict: evoraclev1.OnConflict_ON_CONFLICT_SKIP})
+	if err != nil || !resp.GetSkipped() {
+		t.Errorf("AddSpec with ON_CONFLICT_SKIP = %v, %v, want skipped", resp, err)
+	}
+
+	resp, err = client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(78), OnConflict: evoraclev1.OnConflict_ON_CONFLICT_UPDATE})
+	if err != nil || resp.GetSkipped() {
+		t.Fatalf("AddSpec with ON_CONFLICT_UPDATE = %v, %v, want stored", resp, err)
+	}
+	spec, err := client.GetSpec(ctx, &evoraclev1.GetSpecRequest{Make: "Tesla", Model: "Model 3", Year: 2023})
+	if err != nil || spec.GetCapacityKwh() != 78 {
+		t.Errorf("GetSpec after the update = %v, %v, want capacity 78", spec, err)
+	}
+}
+
+func TestInvalidRequestsAreRejectedBeforeProviderCalls(t *testing.T) {
+	client, _, calls := newClient(t)
+	ctx := context.Background()
+	long := strings.Repeat("x", models.MaxNameLength+1)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GetSpec overlong make", func() error {
+			_, err := client.GetSpec(ctx, &evoraclev1.GetSpecRequest{Make: long, Model: "Model 3", Year: 2023})
+			return err
+		}},
+		{"GetSpec control character", func() error {
+			_, err := client.GetSpec(ctx, &evoraclev1.GetSpecRequest{Make: "Tesla", Model: "Model\x003", Year: 2023})
+			return err
+		}},
+		{"Search year out of range", func() error {
+			_, err := client.Search(ctx, &evoraclev1.SearchRequest{Make: "Tesla", Model: "Model 3", Year: 1800})
+			return err
+		}},
+		{"AddSpec without spec", func() error {
+			_, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{})
+			return err
+		}},
+		{"AddSpec implausible capacity", func() error {
+			_, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: model3(5000)})
+			return err
+		}},
+		{"AddSpec unknown body style", func() error {
+			spec := model3(75)
+			spec.BodyStyle = "spaceship"
+			_, err := client.AddSpec(ctx, &evoraclev1.AddSpecRequest{Spec: spec})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call()); code != codes.InvalidArgument {
+				t.Errorf("code = %v, want InvalidArgument", code)
+			}
+		})
+	}
+	if embed, generate := calls.embed.Load(), calls.generate.Load(); embed != 0 || generate != 0 {
+		t.Errorf("providers were called (%d embeddings, %d LLM queries) for invalid requests", embed, generate)
+	}
+}
+
+func TestReflectionListsService(t *testing.T) {
+	_, conn, _ := newClient(t)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("failed to open reflection stream: %v", err)
+	}
+	req := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}
+	if err := stream.Send(req); err != nil {
+		t.Fatalf("failed to send reflection request: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive reflection response: %v", err)
+	}
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	if !strings.Contains(strings.Join(names, " "), "evoracle.v1.EVOracle") {
+		t.Errorf("reflection lists %v, want evoracle.v1.EVOracle", names)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("query: %w", models.ErrInvalidInput), codes.InvalidArgument},
+		{fmt.Errorf("query: %w", resolver.ErrNotElectric), codes.FailedPrecondition},
+		{fmt.Errorf("query: %w", resolver.ErrBeforeProduction), codes.FailedPrecondition},
+		{fmt.Errorf("insert: %w", db.ErrAuthoritative), codes.FailedPrecondition},
+		{fmt.Errorf("insert: %w", db.ErrReadOnly), codes.FailedPrecondition},
+		{fmt.Errorf("insert: %w", db.ErrSpecExists), codes.AlreadyExists},
+		{fmt.Errorf("embed: %w", resilience.ErrCircuitOpen), codes.Unavailable},
+		{fmt.Errorf("embed: %w", context.Canceled), codes.Canceled},
+		{fmt.Errorf("embed: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{errors.New("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := status.Code(statusError(tt.err)); got != tt.want {
+			t.Errorf("statusError(%v) code = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package httplog provides an http.RoundTripper that dumps requests and
+// responses for debugging provider integrations.
+package httplog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedHeaders carry credentials and are never printed
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Api-Key":     true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// Transport logs the method, URL, headers and body of every request and
+// response to Out, with credential headers redacted
+type Transport struct {
+	Base http.RoundTripper // Underlying transport; http.DefaultTransport if nil
+	Out  io.Writer
+
+	mu sync.Mutex // serializes dumps from concurrent requests
+}
+
+// NewTransport creates a Transport wrapping base that logs to out
+func NewTransport(base http.RoundTripper, out io.Writer) *Transport {
+	return &Transport{Base: base, Out: out}
+}
+
+// RoundTrip implements http.RoundTripper. Since a RoundTripper must not modify
+// the request, the buffered body is sent on a clone of it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	out := req.Clone(req.Context())
+	reqBody, err := readBody(&out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if reqBody != nil {
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(reqBody)), nil
+		}
+	}
+	t.dump(fmt.Sprintf("> %s %s", req.Method, req.URL), req.Header, reqBody)
+
+	resp, err := base.RoundTrip(out)
+	if err != nil {
+		t.dump(fmt.Sprintf("< error: %v", err), nil, nil)
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	t.dump(fmt.Sprintf("< %s", resp.Status), resp.Header, respBody)
+
+	return resp, nil
+}
+
+// readBody drains *body and replaces it with an in-memory copy
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// dump writes one request or response block
+func (t *Transport) dump(first string, header http.Header, body []byte) {
+	var b strings.Builder
+	b.WriteString(first)
+	b.WriteByte('\n')
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, value)
+	}
+	if len(body) > 0 {
+		b.Write(body)
+		if body[len(body)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprint(t.Out, b.String())
+}
@@ -0,0 +1,59 @@
+package httplog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoundTripLeavesRequestUnmodified(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		received = string(body)
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	client := &http.Client{Transport: NewTransport(nil, &out)}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"prompt": "hi"}`))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-key")
+	body := req.Body
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+
+	if req.Body != body {
+		t.Error("RoundTrip replaced the caller's request body")
+	}
+	if received != `{"prompt": "hi"}` {
+		t.Errorf("server received %q, want the original body", received)
+	}
+	if string(respBody) != `{"ok": true}` {
+		t.Errorf("response body = %q, want it passed through after logging", respBody)
+	}
+	dump := out.String()
+	if !strings.Contains(dump, `{"prompt": "hi"}`) || !strings.Contains(dump, `{"ok": true}`) {
+		t.Errorf("dump is missing a body:\n%s", dump)
+	}
+	if strings.Contains(dump, "secret-key") {
+		t.Errorf("dump leaks the Authorization header:\n%s", dump)
+	}
+}
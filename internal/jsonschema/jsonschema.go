@@ -0,0 +1,147 @@
+// Package jsonschema generates JSON Schemas for Go types by reflection, so
+// the published schema of the JSON output follows the struct tags
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// timeType is encoded by encoding/json as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// For returns the schema of the JSON encoding of values of type T. Named
+// struct types are placed in $defs and referenced, and fields follow the
+// encoding/json rules: json tag names, "-" skipped, embedded structs
+// flattened, and fields required unless tagged omitempty.
+func For[T any]() *Schema {
+	g := &generator{defs: map[string]*Schema{}}
+	root := g.schema(reflect.TypeFor[T]())
+	root.Schema = Draft
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+// generator accumulates the named struct definitions of one document
+type generator struct {
+	defs map[string]*Schema
+}
+
+// schema returns the subschema for t
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // reserve the name so recursive types terminate
+			def := g.object(t)
+			def.Title = t.Name()
+			g.defs[t.Name()] = def
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	default:
+		// Interfaces and anything else may hold any JSON value
+		return &Schema{}
+	}
+}
+
+// object returns the object schema of a struct's exported fields
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the JSON fields of struct t to s, flattening embedded structs
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schema(field.Type)
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+		if !omitempty && (field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Map) {
+			// A nil slice or map without omitempty encodes as null
+			prop = nullable(prop)
+		}
+		s.Properties[name] = prop
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// nullable returns s extended to also accept null
+func nullable(s *Schema) *Schema {
+	if name, ok := s.Type.(string); ok && s.Ref == "" {
+		s.Type = []string{name, "null"}
+		return s
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
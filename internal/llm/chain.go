@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// WithFallback makes QueryEVSpecs and QueryField try the given providers in
+// order, moving on to the next only when one fails, and return the first
+// success. The provider passed to NewWithProvider is ignored when a chain of
+// two or more providers is set.
+func WithFallback(providers ...ProviderType) Option {
+	return func(s *Service) {
+		s.chain = providers
+	}
+}
+
+// Providers returns the providers queried: every chained provider in order,
+// or just the configured one
+func (s *Service) Providers() []ProviderType {
+	if len(s.chain) > 1 {
+		return s.chain
+	}
+	return []ProviderType{s.provider}
+}
+
+// UsingProvider returns a copy of the service that queries only provider,
+// with every other setting unchanged
+func (s *Service) UsingProvider(provider ProviderType) *Service {
+	alt := *s
+	alt.provider = provider
+	alt.chain = nil
+	return &alt
+}
+
+// queryChain runs query against each chained provider in turn and returns
+// the first success
+func (s *Service) queryChain(ctx context.Context, query func(*Service) (*models.EVSpec, error)) (*models.EVSpec, error) {
+	var errs []error
+	for i, provider := range s.chain {
+		spec, err := query(s.UsingProvider(provider))
+		if err == nil {
+			if i > 0 {
+				fmt.Fprintf(os.Stderr, "LLM query served by %s\n", provider)
+			}
+			return spec, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(s.chain)-1 {
+			fmt.Fprintf(os.Stderr, "Warning: %s LLM query failed: %v; trying %s\n", provider, err, s.chain[i+1])
+		}
+	}
+
+	return nil, fmt.Errorf("all chained LLM providers failed: %w", errors.Join(errs...))
+}
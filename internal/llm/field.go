@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Field names a single EV spec attribute that can be queried on its own
+type Field string
+
+const (
+	FieldCapacity  Field = "capacity"
+	FieldPower     Field = "power"
+	FieldChemistry Field = "chemistry"
+)
+
+// fieldPrompts asks for exactly one attribute in the format its parser expects
+var fieldPrompts = map[Field]string{
+	FieldCapacity: `What is the battery capacity of the %d %s %s electric vehicle?
+
+Return ONLY one line in this exact format:
+Capacity: [number] kWh`,
+	FieldPower: `What is the power rating of the %d %s %s electric vehicle?
+
+Return ONLY one line in this exact format:
+Power: [number] kW`,
+	FieldChemistry: `What battery chemistry does the %d %s %s electric vehicle use (e.g. NMC, NCA, LFP)?
+
+Return ONLY one line in this exact format:
+Chemistry: [chemistry type]`,
+}
+
+// QueryField asks the LLM for a single missing attribute. The returned spec
+// has only that field set (plus make, model, year and the raw response), so
+// callers can merge it into an existing partial spec.
+func (s *Service) QueryField(ctx context.Context, make, model string, year int, field Field) (spec *models.EVSpec, err error) {
+	template, ok := fieldPrompts[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field: %s", field)
+	}
+	if len(s.chain) > 1 {
+		return s.queryChain(ctx, func(alt *Service) (*models.EVSpec, error) {
+			return alt.QueryField(ctx, make, model, year, field)
+		})
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.IncCounter(metrics.LLMRequestsTotal, map[string]string{"provider": string(s.provider), "status": metrics.Status(err)})
+		s.metrics.ObserveHistogram(metrics.LLMDurationSeconds, time.Since(start).Seconds(), map[string]string{"provider": string(s.provider)})
+		if err == nil {
+			s.reportServed(ctx)
+		}
+	}()
+
+	prompt := fmt.Sprintf(template, year, make, model)
+	var text string
+	switch s.provider {
+	case ProviderOllama:
+		text, err = s.completeOllama(ctx, prompt)
+	default:
+		text, err = s.completeClaude(ctx, prompt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err = parseField(text, field, make, model, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+	}
+	return spec, nil
+}
+
+// parseField extracts a single attribute from a field-specific response
+func parseField(text string, field Field, make, model string, year int) (*models.EVSpec, error) {
+	spec := &models.EVSpec{
+		Make:        make,
+		Model:       model,
+		Year:        year,
+		Confidence:  models.LLMConfidenceScore,
+		Source:      "llm",
+		RawResponse: text,
+	}
+
+	switch field {
+	case FieldCapacity:
+		value, err := parseNumber(capacityRe, text, models.CapacityInRange)
+		if err != nil {
+			return nil, err
+		}
+		spec.Capacity = value
+	case FieldPower:
+		value, err := parseNumber(powerRe, text, models.PowerInRange)
+		if err != nil {
+			return nil, err
+		}
+		spec.Power = value
+	case FieldChemistry:
+		matches := chemistryRe.FindStringSubmatch(text)
+		if len(matches) < 2 || strings.TrimSpace(matches[1]) == "" {
+			return nil, fmt.Errorf("no chemistry in response")
+		}
+		spec.Chemistry = strings.TrimSpace(matches[1])
+	}
+	return spec, nil
+}
+
+// parseNumber extracts the number captured by re, rejecting values that
+// fail the plausibility check
+func parseNumber(re *regexp.Regexp, text string, plausible func(float64) bool) (float64, error) {
+	matches := re.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("no value in response")
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || !plausible(value) {
+		return 0, fmt.Errorf("implausible value: %s", matches[1])
+	}
+	return value, nil
+}
@@ -2,20 +2,34 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
 	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/ollama"
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/version"
 )
 
 const (
 	anthropicAPIURL = "https://api.anthropic.com/v1/messages"
-	claudeModel     = "claude-3-5-sonnet-20241022"
+
+	// DefaultClaudeModel is the Claude model used unless WithClaudeModel is given
+	DefaultClaudeModel = models.DefaultClaudeModel
+	// DefaultAnthropicVersion is the anthropic-version header sent unless
+	// WithAnthropicVersion is given
+	DefaultAnthropicVersion = models.DefaultAnthropicVersion
 )
 
 // ProviderType represents the LLM provider
@@ -31,15 +45,118 @@ var (
 	capacityRe  = regexp.MustCompile(`(?i)capacity:\s*([0-9.]+)\s*kWh`)
 	powerRe     = regexp.MustCompile(`(?i)power:\s*([0-9.]+)\s*kW`)
 	chemistryRe = regexp.MustCompile(`(?i)chemistry:\s*([^\n]+)`)
+	candidateRe = regexp.MustCompile(`(?i)chemistry candidates:\s*(\[[^\n]*\])`)
 )
 
+// candidatesInstruction is appended to spec prompts when chemistry candidates
+// are requested
+const candidatesInstruction = `
+
+Also add this line, listing every plausible chemistry with your estimated probability (summing to 1), most likely first:
+Chemistry candidates: [{"name": "[chemistry type]", "probability": [number]}, ...]`
+
 // Service handles LLM operations for fallback queries
 type Service struct {
 	provider     ProviderType
+	chain        []ProviderType // providers tried in order, see WithFallback
 	anthropicKey string
+	claudeModel  string
+	apiVersion   string // anthropic-version header
 	ollamaURL    string
 	ollamaModel  string
 	client       *http.Client
+	userAgent    string
+	metrics      metrics.Recorder
+	resilience   resilience.Policy
+	candidates   bool // ask for ranked chemistry candidates
+	extraParams  map[string]any
+	extraErr     error // set if extraParams cannot be marshaled
+	pullMissing  bool  // pull a missing Ollama model and retry
+	prompts      Prompts
+	priors       models.Priors // hints added to spec prompts
+}
+
+// Option is a functional option for Service
+type Option func(*Service)
+
+// WithChemistryCandidates asks the LLM for ranked chemistry candidates with
+// probabilities, returned in EVSpec.ChemistryAlternatives
+func WithChemistryCandidates() Option {
+	return func(s *Service) {
+		s.candidates = true
+	}
+}
+
+// WithExtraParams merges params into the top level of every provider request
+// body, overriding fields of the same name, so provider options such as
+// top_p, stop_sequences or Ollama's "options" object can be set without a
+// dedicated option. params must marshal to JSON; otherwise every request
+// fails with the marshal error.
+func WithExtraParams(params map[string]any) Option {
+	return func(s *Service) {
+		s.extraParams = params
+		if _, err := json.Marshal(params); err != nil {
+			s.extraErr = fmt.Errorf("invalid extra params: %w", err)
+		}
+	}
+}
+
+// WithClaudeModel sets the Claude model, e.g. "claude-sonnet-4-5". An empty
+// model keeps DefaultClaudeModel.
+func WithClaudeModel(model string) Option {
+	return func(s *Service) {
+		if model != "" {
+			s.claudeModel = model
+		}
+	}
+}
+
+// WithAnthropicVersion sets the anthropic-version header sent to the Claude
+// API. An empty version keeps DefaultAnthropicVersion.
+func WithAnthropicVersion(version string) Option {
+	return func(s *Service) {
+		if version != "" {
+			s.apiVersion = version
+		}
+	}
+}
+
+// WithPullMissingModel makes Ollama requests that fail with an
+// *ollama.ModelNotFoundError pull the model and retry once
+func WithPullMissingModel() Option {
+	return func(s *Service) {
+		s.pullMissing = true
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with provider requests
+func WithUserAgent(userAgent string) Option {
+	return func(s *Service) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for provider requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithMetrics records request counts and latencies per provider
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(s *Service) {
+		s.metrics = recorder
+	}
+}
+
+// WithResilience retries, times out and breaks provider requests as policy
+// says. It wraps whichever HTTP client the service ends up with, so it may be
+// combined with WithHTTPClient in either order.
+func WithResilience(policy resilience.Policy) Option {
+	return func(s *Service) {
+		s.resilience = policy
+	}
 }
 
 // New creates a new LLM service with Claude (legacy)
@@ -47,19 +164,66 @@ func New(apiKey string) *Service {
 	return &Service{
 		provider:     ProviderClaude,
 		anthropicKey: apiKey,
+		claudeModel:  DefaultClaudeModel,
+		apiVersion:   DefaultAnthropicVersion,
 		client:       &http.Client{},
+		userAgent:    version.UserAgent(),
+		metrics:      metrics.Nop{},
 	}
 }
 
 // NewWithProvider creates a new LLM service with the specified provider
-func NewWithProvider(provider ProviderType, anthropicKey, ollamaURL, ollamaModel string) *Service {
-	return &Service{
+func NewWithProvider(provider ProviderType, anthropicKey, ollamaURL, ollamaModel string, opts ...Option) *Service {
+	s := &Service{
 		provider:     provider,
 		anthropicKey: anthropicKey,
+		claudeModel:  DefaultClaudeModel,
+		apiVersion:   DefaultAnthropicVersion,
 		ollamaURL:    ollamaURL,
 		ollamaModel:  ollamaModel,
 		client:       &http.Client{},
+		userAgent:    version.UserAgent(),
+		metrics:      metrics.Nop{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.client = s.resilience.Client(s.client)
+	return s
+}
+
+// Provider returns the configured LLM provider
+func (s *Service) Provider() ProviderType {
+	return s.provider
+}
+
+// Model returns the LLM model name
+func (s *Service) Model() string {
+	if s.provider == ProviderOllama {
+		return s.ollamaModel
+	}
+	return s.claudeModel
+}
+
+// URL returns the endpoint requested for completions
+func (s *Service) URL() string {
+	if s.provider == ProviderOllama {
+		return s.ollamaURL + "/api/generate"
 	}
+	return anthropicAPIURL
+}
+
+// Probe sends a minimal prompt to check that the provider is reachable and
+// accepts our credentials
+func (s *Service) Probe(ctx context.Context) error {
+	const prompt = "Reply with the single word OK."
+	var err error
+	if s.provider == ProviderOllama {
+		_, err = s.completeOllama(ctx, prompt)
+	} else {
+		_, err = s.completeClaude(ctx, prompt)
+	}
+	return err
 }
 
 // claudeRequest represents the request to Claude API
@@ -82,31 +246,59 @@ type claudeResponse struct {
 	} `json:"content"`
 }
 
-// QueryEVSpecs queries the LLM API for EV battery specifications
-func (s *Service) QueryEVSpecs(make, model string, year int) (*models.EVSpec, error) {
+// QueryEVSpecs queries the LLM API for EV battery specifications. Cancelling
+// ctx aborts the request in flight.
+func (s *Service) QueryEVSpecs(ctx context.Context, make, model string, year int) (spec *models.EVSpec, err error) {
+	if len(s.chain) > 1 {
+		return s.queryChain(ctx, func(alt *Service) (*models.EVSpec, error) {
+			return alt.QueryEVSpecs(ctx, make, model, year)
+		})
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.IncCounter(metrics.LLMRequestsTotal, map[string]string{"provider": string(s.provider), "status": metrics.Status(err)})
+		s.metrics.ObserveHistogram(metrics.LLMDurationSeconds, time.Since(start).Seconds(), map[string]string{"provider": string(s.provider)})
+		if err == nil {
+			s.reportServed(ctx)
+		}
+	}()
+
 	switch s.provider {
 	case ProviderOllama:
-		return s.queryOllama(make, model, year)
+		return s.queryOllama(ctx, make, model, year)
 	case ProviderClaude:
 		fallthrough
 	default:
-		return s.queryClaude(make, model, year)
+		return s.queryClaude(ctx, make, model, year)
 	}
 }
 
 // queryClaude queries Claude API for EV battery specifications
-func (s *Service) queryClaude(make, model string, year int) (*models.EVSpec, error) {
-	prompt := fmt.Sprintf(`Please provide the battery specifications for the %d %s %s electric vehicle.
+func (s *Service) queryClaude(ctx context.Context, make, model string, year int) (*models.EVSpec, error) {
+	prompt, err := s.buildPrompt(make, model, year)
+	if err != nil {
+		return nil, err
+	}
 
-Return ONLY the following information in this exact format:
-Capacity: [number] kWh
-Power: [number] kW
-Chemistry: [chemistry type]
+	text, err := s.completeClaude(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
 
-If you don't have exact information, provide your best estimate based on similar models and clearly indicate it's an estimate.`, year, make, model)
+	// Parse the response text
+	spec, err := parseEVSpecs(text, make, model, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return spec, nil
+}
 
+// completeClaude sends a single-turn prompt to Claude and returns the response text
+func (s *Service) completeClaude(ctx context.Context, prompt string) (string, error) {
 	reqBody := claudeRequest{
-		Model:     claudeModel,
+		Model:     s.claudeModel,
 		MaxTokens: 1024,
 		Messages: []claudeMessage{
 			{
@@ -116,47 +308,42 @@ If you don't have exact information, provide your best estimate based on similar
 		},
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	jsonData, err := s.marshalRequest(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	req, err := http.NewRequest("POST", anthropicAPIURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", anthropicAPIURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
 	req.Header.Set("x-api-key", s.anthropicKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("anthropic-version", s.apiVersion)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("claude API error (status %d): %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("claude API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var claudeResp claudeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if len(claudeResp.Content) == 0 {
-		return nil, fmt.Errorf("no content in response")
+		return "", fmt.Errorf("no content in response")
 	}
 
-	// Parse the response text
-	spec, err := parseEVSpecs(claudeResp.Content[0].Text, make, model, year)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return spec, nil
+	return claudeResp.Content[0].Text, nil
 }
 
 // ollamaRequest represents the request to Ollama API
@@ -172,86 +359,142 @@ type ollamaResponse struct {
 }
 
 // queryOllama queries Ollama API for EV battery specifications
-func (s *Service) queryOllama(make, model string, year int) (*models.EVSpec, error) {
-	fmt.Println("Querying Ollama for", year, make, model)
-	prompt := fmt.Sprintf(`Please provide the DC fast charging capabilities of the %d %s %s. 
-Where "Power" is the peak rate at which the vehicle can DC fast charge.  
+func (s *Service) queryOllama(ctx context.Context, make, model string, year int) (*models.EVSpec, error) {
+	fmt.Fprintln(os.Stderr, "Querying Ollama for", year, make, model)
+	prompt, err := s.buildPrompt(make, model, year)
+	if err != nil {
+		return nil, err
+	}
 
-Return ONLY the following information in this exact format: 
-Capacity: [number] 
-kWh Power: [number] kW 
-Chemistry: [chemistry type]
+	text, err := s.completeOllama(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the response text
+	spec, err := parseEVSpecs(text, make, model, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return spec, nil
+}
 
-If you don't have exact information, provide your best estimate based on similar models.`, year, make, model)
+// completeOllama sends a prompt to Ollama's generate API and returns the
+// response text, pulling a missing model first if WithPullMissingModel is set
+func (s *Service) completeOllama(ctx context.Context, prompt string) (string, error) {
+	text, err := s.generateOllama(ctx, prompt)
+	var notFound *ollama.ModelNotFoundError
+	if !s.pullMissing || !errors.As(err, &notFound) {
+		return text, err
+	}
 
+	fmt.Fprintf(os.Stderr, "Pulling Ollama model %s...\n", s.ollamaModel)
+	if err := ollama.Pull(ctx, s.client, s.ollamaURL, s.ollamaModel, s.userAgent); err != nil {
+		return "", err
+	}
+	return s.generateOllama(ctx, prompt)
+}
+
+// generateOllama sends a single request to Ollama's generate API
+func (s *Service) generateOllama(ctx context.Context, prompt string) (string, error) {
 	reqBody := ollamaRequest{
 		Model:  s.ollamaModel,
 		Prompt: prompt,
 		Stream: false,
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	jsonData, err := s.marshalRequest(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
 	url := fmt.Sprintf("%s/api/generate", s.ollamaURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
+		if err := ollama.CheckModel(resp.StatusCode, body, s.ollamaModel); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var ollamaResp ollamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if ollamaResp.Response == "" {
-		return nil, fmt.Errorf("no response from ollama")
+		return "", fmt.Errorf("no response from ollama")
 	}
-	fmt.Println("Ollama response:", ollamaResp.Response)
-	// Parse the response text
-	spec, err := parseEVSpecs(ollamaResp.Response, make, model, year)
+	fmt.Fprintln(os.Stderr, "Ollama response:", ollamaResp.Response)
+
+	return ollamaResp.Response, nil
+}
+
+// marshalRequest encodes a provider request body, merging in any extra params
+func (s *Service) marshalRequest(reqBody any) ([]byte, error) {
+	if s.extraErr != nil {
+		return nil, s.extraErr
+	}
+
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if len(s.extraParams) == 0 {
+		return jsonData, nil
 	}
 
-	return spec, nil
+	var merged map[string]any
+	if err := json.Unmarshal(jsonData, &merged); err != nil {
+		return nil, fmt.Errorf("failed to merge extra params: %w", err)
+	}
+	for key, value := range s.extraParams {
+		merged[key] = value
+	}
+	jsonData, err = json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return jsonData, nil
 }
 
 // parseEVSpecs parses the Claude response text into an EVSpec
 func parseEVSpecs(text, make, model string, year int) (*models.EVSpec, error) {
 	spec := &models.EVSpec{
-		Make:       make,
-		Model:      model,
-		Year:       year,
-		Confidence: models.LLMConfidenceScore,
-		Source:     "llm",
+		Make:        make,
+		Model:       model,
+		Year:        year,
+		Confidence:  models.LLMConfidenceScore,
+		Source:      "llm",
+		RawResponse: text,
 	}
 
-	// Extract capacity using pre-compiled regex
+	// Extract capacity using pre-compiled regex; implausible values are
+	// treated as missing rather than trusted
 	if matches := capacityRe.FindStringSubmatch(text); len(matches) > 1 {
-		if capacity, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if capacity, err := strconv.ParseFloat(matches[1], 64); err == nil && models.CapacityInRange(capacity) {
 			spec.Capacity = capacity
 		}
 	}
 
 	// Extract power using pre-compiled regex
 	if matches := powerRe.FindStringSubmatch(text); len(matches) > 1 {
-		if power, err := strconv.ParseFloat(matches[1], 64); err == nil {
+		if power, err := strconv.ParseFloat(matches[1], 64); err == nil && models.PowerInRange(power) {
 			spec.Power = power
 		}
 	}
@@ -261,6 +504,12 @@ func parseEVSpecs(text, make, model string, year int) (*models.EVSpec, error) {
 		spec.Chemistry = strings.TrimSpace(matches[1])
 	}
 
+	// The top-ranked candidate, if any, is the primary chemistry
+	if candidates := parseCandidates(text); len(candidates) > 0 {
+		spec.ChemistryAlternatives = candidates
+		spec.Chemistry = candidates[0].Name
+	}
+
 	// Validate that we got at least some data
 	if spec.Capacity == 0 && spec.Power == 0 && spec.Chemistry == "" {
 		return nil, fmt.Errorf("failed to extract any specifications from response")
@@ -268,3 +517,30 @@ func parseEVSpecs(text, make, model string, year int) (*models.EVSpec, error) {
 
 	return spec, nil
 }
+
+// parseCandidates extracts the ranked chemistry candidates line, dropping
+// unnamed entries and sorting by descending probability. A missing or
+// malformed line yields nil.
+func parseCandidates(text string) []models.ChemistryCandidate {
+	matches := candidateRe.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return nil
+	}
+
+	var raw []models.ChemistryCandidate
+	if err := json.Unmarshal([]byte(matches[1]), &raw); err != nil {
+		return nil
+	}
+
+	var candidates []models.ChemistryCandidate
+	for _, c := range raw {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name != "" && c.Prob >= 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Prob > candidates[j].Prob
+	})
+	return candidates
+}
@@ -0,0 +1,14 @@
+package llm
+
+import "testing"
+
+func BenchmarkParseEVSpecs(b *testing.B) {
+	text := "Capacity: 77.4 kWh\nPower: 239 kW\nChemistry: NMC\n" +
+		`Chemistry candidates: [{"name": "NMC", "probability": 0.8}, {"name": "LFP", "probability": 0.2}]`
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := parseEVSpecs(text, "Hyundai", "Ioniq 5", 2023); err != nil {
+			b.Fatalf("parseEVSpecs: %v", err)
+		}
+	}
+}
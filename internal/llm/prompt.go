@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// DefaultPrompt is the spec prompt used by every provider unless overridden.
+// It asks for the three fields in the line format parseEVSpecs reads. Prompt
+// templates are Go text/templates executed with .Make, .Model and .Year.
+const DefaultPrompt = `Please provide the battery specifications for the {{.Year}} {{.Make}} {{.Model}} electric vehicle.
+Capacity is the usable battery capacity and Power is the peak rate at which the vehicle can DC fast charge.
+
+Return ONLY the following information in this exact format:
+Capacity: [number] kWh
+Power: [number] kW
+Chemistry: [chemistry type]
+
+If you don't have exact information, provide your best estimate based on similar models and clearly indicate it's an estimate.`
+
+// Prompts holds the spec prompt templates. Default applies to every provider
+// without an entry in Overrides; an empty Default means DefaultPrompt.
+type Prompts struct {
+	Default   string
+	Overrides map[ProviderType]string
+}
+
+// For returns the prompt template used for provider
+func (p Prompts) For(provider ProviderType) string {
+	if tmpl, ok := p.Overrides[provider]; ok {
+		return tmpl
+	}
+	if p.Default != "" {
+		return p.Default
+	}
+	return DefaultPrompt
+}
+
+// WithPrompt replaces the spec prompt template for every provider that has no
+// override of its own
+func WithPrompt(tmpl string) Option {
+	return func(s *Service) {
+		s.prompts.Default = tmpl
+	}
+}
+
+// WithProviderPrompt overrides the spec prompt template for one provider
+func WithProviderPrompt(provider ProviderType, tmpl string) Option {
+	return func(s *Service) {
+		if s.prompts.Overrides == nil {
+			s.prompts.Overrides = make(map[ProviderType]string)
+		}
+		s.prompts.Overrides[provider] = tmpl
+	}
+}
+
+// WithPriors adds the matching prior's hint, e.g. "For reference, Tesla
+// vehicles typically use NMC or NCA batteries.", to spec prompts
+func WithPriors(priors models.Priors) Option {
+	return func(s *Service) {
+		s.priors = priors
+	}
+}
+
+// Prompts returns the prompt templates the service resolves prompts from
+func (s *Service) Prompts() Prompts {
+	return s.prompts
+}
+
+// EstimatedResponseTokens is the rough size of a spec answer in tokens, used
+// for cost estimates
+const EstimatedResponseTokens = 150
+
+// PromptTokens roughly estimates the tokens in the prompt for make, model and
+// year, at about four characters per token
+func (s *Service) PromptTokens(make, model string, year int) (int, error) {
+	prompt, err := s.buildPrompt(make, model, year)
+	if err != nil {
+		return 0, err
+	}
+	return (len(prompt) + 3) / 4, nil
+}
+
+// promptVehicle is the data prompt templates are executed with
+type promptVehicle struct {
+	Make  string
+	Model string
+	Year  int
+}
+
+// buildPrompt renders the spec prompt for the service's provider, adding any
+// prior hint and the chemistry candidates instruction when requested
+func (s *Service) buildPrompt(make, model string, year int) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(s.prompts.For(s.provider))
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, promptVehicle{Make: make, Model: model, Year: year}); err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	prompt := sb.String()
+	if prior, ok := s.priors.Lookup(make, model); ok {
+		if hint := prior.Hint(); hint != "" {
+			prompt += "\n\n" + hint
+		}
+	}
+	if s.candidates {
+		prompt += candidatesInstruction
+	}
+	return prompt, nil
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// redirectTransport sends every request to target, so the fixed Anthropic
+// URL reaches a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// promptRecorder starts a server answering as both Claude and Ollama that
+// records the prompts it is sent, and returns a service for provider using it
+func promptRecorder(t *testing.T, provider ProviderType, opts ...Option) (*Service, *[]string) {
+	t.Helper()
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Prompt   string `json:"prompt"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		const answer = "Capacity: 75 kWh\nPower: 250 kW\nChemistry: NMC"
+		if len(body.Messages) > 0 {
+			prompts = append(prompts, body.Messages[0].Content)
+			fmt.Fprintf(w, `{"content": [{"text": %q}]}`, answer)
+			return
+		}
+		prompts = append(prompts, body.Prompt)
+		fmt.Fprintf(w, `{"response": %q}`, answer)
+	}))
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client := &http.Client{Transport: redirectTransport{target: target}}
+	opts = append([]Option{WithHTTPClient(client)}, opts...)
+	return NewWithProvider(provider, "test-key", server.URL, "test", opts...), &prompts
+}
+
+func TestProvidersSendResolvedPrompt(t *testing.T) {
+	defaultPrompt := strings.NewReplacer("{{.Year}}", "2023", "{{.Make}}", "Tesla", "{{.Model}}", "Model 3").Replace(DefaultPrompt)
+	for _, provider := range []ProviderType{ProviderClaude, ProviderOllama} {
+		other := ProviderOllama
+		if provider == ProviderOllama {
+			other = ProviderClaude
+		}
+		tests := []struct {
+			name string
+			opts []Option
+			want string
+		}{
+			{"default", nil, defaultPrompt},
+			{"shared", []Option{WithPrompt("Specs for the {{.Year}} {{.Make}} {{.Model}}")}, "Specs for the 2023 Tesla Model 3"},
+			{"own override", []Option{
+				WithPrompt("Specs for the {{.Year}} {{.Make}} {{.Model}}"),
+				WithProviderPrompt(provider, "{{.Make}} {{.Model}} {{.Year}}: capacity, power, chemistry?"),
+			}, "Tesla Model 3 2023: capacity, power, chemistry?"},
+			{"other provider's override", []Option{
+				WithPrompt("Specs for the {{.Year}} {{.Make}} {{.Model}}"),
+				WithProviderPrompt(other, "not this one"),
+			}, "Specs for the 2023 Tesla Model 3"},
+			{"prior hint", []Option{
+				WithPrompt("Specs for the {{.Year}} {{.Make}} {{.Model}}"),
+				WithPriors(models.Priors{{Make: "Tesla", Chemistries: []string{"NMC", "NCA"}}}),
+			}, "Specs for the 2023 Tesla Model 3\n\nFor reference, Tesla vehicles typically use NMC or NCA batteries."},
+		}
+		for _, tt := range tests {
+			t.Run(string(provider)+"/"+tt.name, func(t *testing.T) {
+				svc, prompts := promptRecorder(t, provider, tt.opts...)
+				if _, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023); err != nil {
+					t.Fatalf("QueryEVSpecs: %v", err)
+				}
+				if len(*prompts) != 1 || (*prompts)[0] != tt.want {
+					t.Errorf("prompts sent = %q, want [%q]", *prompts, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestInvalidPromptTemplateFailsBeforeRequest(t *testing.T) {
+	for _, provider := range []ProviderType{ProviderClaude, ProviderOllama} {
+		t.Run(string(provider), func(t *testing.T) {
+			svc, prompts := promptRecorder(t, provider, WithProviderPrompt(provider, "Specs for {{.Trim}}"))
+			_, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023)
+			if err == nil || !strings.Contains(err.Error(), "invalid prompt template") {
+				t.Errorf("QueryEVSpecs error = %v, want an invalid template error", err)
+			}
+			if len(*prompts) != 0 {
+				t.Errorf("%d requests were sent with an invalid template", len(*prompts))
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+	"github.com/scaryPonens/ev-oracle/internal/resilience/resiliencetest"
+)
+
+// flakyService returns a service for provider sending its requests to
+// server under policy
+func flakyService(t *testing.T, provider ProviderType, server *resiliencetest.Server, policy resilience.Policy) *Service {
+	t.Helper()
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client := &http.Client{Transport: redirectTransport{target: target}}
+	return NewWithProvider(provider, "test-key", server.URL, "test", WithResilience(policy), WithHTTPClient(client))
+}
+
+// successBody is a provider's answer for the 2023 Tesla Model 3
+func successBody(provider ProviderType) string {
+	if provider == ProviderClaude {
+		return `{"content": [{"text": "Capacity: 75 kWh\nPower: 250 kW\nChemistry: NMC"}]}`
+	}
+	return `{"response": "Capacity: 75 kWh\nPower: 250 kW\nChemistry: NMC"}`
+}
+
+func TestQueryEVSpecsRetriesFlakyProvider(t *testing.T) {
+	for _, provider := range []ProviderType{ProviderClaude, ProviderOllama} {
+		t.Run(string(provider), func(t *testing.T) {
+			server := resiliencetest.NewServer(t, successBody(provider),
+				resiliencetest.Status(http.StatusInternalServerError),
+				resiliencetest.Throttled("0"),
+				resiliencetest.OK())
+			svc := flakyService(t, provider, server, resilience.Policy{MaxRetries: 2, Backoff: 30 * time.Millisecond})
+
+			spec, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023)
+			if err != nil {
+				t.Fatalf("QueryEVSpecs failed: %v", err)
+			}
+			if spec.Capacity != 75 {
+				t.Errorf("capacity = %v, want 75", spec.Capacity)
+			}
+			if got := server.Requests(); got != 3 {
+				t.Errorf("requests = %d, want 3", got)
+			}
+			if gaps := server.Gaps(); gaps[0] < 30*time.Millisecond {
+				t.Errorf("gap after the 500 = %v, want at least the 30ms backoff", gaps[0])
+			}
+		})
+	}
+}
+
+func TestQueryEVSpecsGivesUp(t *testing.T) {
+	server := resiliencetest.NewServer(t, successBody(ProviderClaude), resiliencetest.Status(http.StatusServiceUnavailable))
+	svc := flakyService(t, ProviderClaude, server, resilience.Policy{MaxRetries: 2, Backoff: time.Millisecond})
+
+	if _, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023); err == nil {
+		t.Fatal("QueryEVSpecs succeeded against a failing provider")
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestQueryEVSpecsDoesNotRetryUnauthorized(t *testing.T) {
+	server := resiliencetest.NewServer(t, successBody(ProviderClaude), resiliencetest.Status(http.StatusUnauthorized))
+	svc := flakyService(t, ProviderClaude, server, resilience.Policy{MaxRetries: 3, Backoff: time.Millisecond})
+
+	if _, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023); err == nil {
+		t.Fatal("QueryEVSpecs succeeded on a 401")
+	}
+	if got := server.Requests(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestQueryEVSpecsTimesOutSlowProvider(t *testing.T) {
+	server := resiliencetest.NewServer(t, successBody(ProviderOllama), resiliencetest.Slow(time.Minute), resiliencetest.OK())
+	svc := flakyService(t, ProviderOllama, server, resilience.Policy{MaxRetries: 1, Timeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023); err != nil {
+		t.Fatalf("QueryEVSpecs failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("QueryEVSpecs took %v, want the slow attempt cut off", elapsed)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestQueryEVSpecsBreakerOpens(t *testing.T) {
+	server := resiliencetest.NewServer(t, successBody(ProviderClaude), resiliencetest.Status(http.StatusBadGateway))
+	svc := flakyService(t, ProviderClaude, server, resilience.Policy{MaxRetries: 5, Backoff: time.Millisecond, BreakerThreshold: 3, BreakerCooldown: time.Minute})
+
+	_, err := svc.QueryEVSpecs(context.Background(), "Tesla", "Model 3", 2023)
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("QueryEVSpecs error = %v, want ErrCircuitOpen", err)
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("requests = %d, want 3 (retries stop once the breaker opens)", got)
+	}
+}
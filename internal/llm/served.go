@@ -0,0 +1,22 @@
+package llm
+
+import "context"
+
+// servedKey carries the function WithServedBy registers in a context
+type servedKey struct{}
+
+// WithServedBy returns a copy of ctx in which QueryEVSpecs and QueryField call
+// fn with the provider and model that answered each successful query. With a
+// fallback chain only the provider that answered is reported. fn may be
+// called concurrently.
+func WithServedBy(ctx context.Context, fn func(provider ProviderType, model string)) context.Context {
+	return context.WithValue(ctx, servedKey{}, fn)
+}
+
+// reportServed tells the function registered by WithServedBy, if any, that
+// the service's provider answered a query
+func (s *Service) reportServed(ctx context.Context) {
+	if fn, ok := ctx.Value(servedKey{}).(func(ProviderType, string)); ok {
+		fn(s.provider, s.Model())
+	}
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// servedProviders returns a context recording the providers WithServedBy
+// reports into the returned slice
+func servedProviders() (context.Context, *[]string) {
+	var served []string
+	ctx := WithServedBy(context.Background(), func(provider ProviderType, model string) {
+		served = append(served, fmt.Sprintf("%s/%s", provider, model))
+	})
+	return ctx, &served
+}
+
+func TestWithServedByReportsAnsweringProvider(t *testing.T) {
+	// Claude is down, so the chain falls back to Ollama
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/generate" {
+			fmt.Fprint(w, successBody(ProviderOllama))
+			return
+		}
+		http.Error(w, "overloaded", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	svc := NewWithProvider(ProviderClaude, "test-key", server.URL, "gemma3",
+		WithHTTPClient(&http.Client{Transport: redirectTransport{target: target}}),
+		WithFallback(ProviderClaude, ProviderOllama))
+
+	ctx, served := servedProviders()
+	if _, err := svc.QueryEVSpecs(ctx, "Tesla", "Model 3", 2023); err != nil {
+		t.Fatalf("QueryEVSpecs failed: %v", err)
+	}
+	if _, err := svc.QueryField(ctx, "Tesla", "Model 3", 2023, FieldChemistry); err != nil {
+		t.Fatalf("QueryField failed: %v", err)
+	}
+	if want := []string{"ollama/gemma3", "ollama/gemma3"}; fmt.Sprint(*served) != fmt.Sprint(want) {
+		t.Errorf("served by %v, want %v", *served, want)
+	}
+}
+
+func TestWithServedByIgnoresFailedQueries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	svc := NewWithProvider(ProviderOllama, "", server.URL, "gemma3")
+
+	ctx, served := servedProviders()
+	if _, err := svc.QueryEVSpecs(ctx, "Tesla", "Model 3", 2023); err == nil {
+		t.Fatal("QueryEVSpecs succeeded against a failing provider")
+	}
+	if len(*served) != 0 {
+		t.Errorf("served by %v after a failed query, want none", *served)
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+// Metric names recorded by the services and resolver
+const (
+	ResolveTotal             = "ev_oracle_resolve_total"
+	ResolveDurationSeconds   = "ev_oracle_resolve_duration_seconds"
+	EmbeddingRequestsTotal   = "ev_oracle_embedding_requests_total"
+	EmbeddingDurationSeconds = "ev_oracle_embedding_request_duration_seconds"
+	LLMRequestsTotal         = "ev_oracle_llm_requests_total"
+	LLMDurationSeconds       = "ev_oracle_llm_request_duration_seconds"
+)
+
+// Recorder receives counter and histogram observations. It is a small
+// interface so the core packages carry no metrics dependency; see the
+// metrics/prometheus package for an adapter. Implementations must be safe for
+// concurrent use, and every observation of a given metric name must use the
+// same set of label keys.
+type Recorder interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
+
+// Nop is a Recorder that discards every observation. It is the default for
+// all services.
+type Nop struct{}
+
+// IncCounter implements Recorder
+func (Nop) IncCounter(string, map[string]string) {}
+
+// ObserveHistogram implements Recorder
+func (Nop) ObserveHistogram(string, float64, map[string]string) {}
+
+// Status returns the "status" label value for an operation's error
+func Status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
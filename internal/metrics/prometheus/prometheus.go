@@ -0,0 +1,80 @@
+// Package prometheus adapts metrics.Recorder to the Prometheus client library.
+// Only import it where Prometheus metrics are wanted; the core packages depend
+// on the metrics.Recorder interface alone.
+package prometheus
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Recorder is a metrics.Recorder backed by Prometheus counters and histograms.
+// Metric vectors are created and registered on first use, with label names
+// taken from that first observation.
+type Recorder struct {
+	registry   *prometheus.Registry
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+}
+
+// New creates a recorder with its own registry
+func New() *Recorder {
+	return &Recorder{
+		registry:   prometheus.NewRegistry(),
+		counters:   make(map[string]*prometheus.CounterVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+	}
+}
+
+// Handler returns an HTTP handler serving the recorded metrics in the
+// Prometheus exposition format, suitable for mounting at /metrics
+func (r *Recorder) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+// IncCounter implements metrics.Recorder
+func (r *Recorder) IncCounter(name string, labels map[string]string) {
+	r.mu.Lock()
+	vec, ok := r.counters[name]
+	if !ok {
+		vec = prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: name}, labelNames(labels))
+		r.registry.MustRegister(vec)
+		r.counters[name] = vec
+	}
+	r.mu.Unlock()
+
+	vec.With(labels).Inc()
+}
+
+// ObserveHistogram implements metrics.Recorder
+func (r *Recorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	r.mu.Lock()
+	vec, ok := r.histograms[name]
+	if !ok {
+		vec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Help:    name,
+			Buckets: prometheus.DefBuckets,
+		}, labelNames(labels))
+		r.registry.MustRegister(vec)
+		r.histograms[name] = vec
+	}
+	r.mu.Unlock()
+
+	vec.With(labels).Observe(value)
+}
+
+// labelNames returns the sorted keys of labels
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BodyStyles are the canonical body style names, in display order
+var BodyStyles = []string{"SUV", "Sedan", "Hatchback", "Wagon", "Coupe", "Van", "Truck"}
+
+// bodyStyleAliases maps lowercased spellings to their canonical body style
+var bodyStyleAliases = map[string]string{
+	"suv":       "SUV",
+	"crossover": "SUV",
+	"cuv":       "SUV",
+	"sedan":     "Sedan",
+	"saloon":    "Sedan",
+	"hatchback": "Hatchback",
+	"hatch":     "Hatchback",
+	"wagon":     "Wagon",
+	"estate":    "Wagon",
+	"coupe":     "Coupe",
+	"coupé":     "Coupe",
+	"van":       "Van",
+	"minivan":   "Van",
+	"mpv":       "Van",
+	"truck":     "Truck",
+	"pickup":    "Truck",
+}
+
+// NormalizeBodyStyle returns the canonical spelling of a body style, so that
+// "suv", "Crossover" and "SUV" are stored and filtered alike. An empty value
+// is returned as is.
+func NormalizeBodyStyle(style string) (string, error) {
+	style = strings.TrimSpace(style)
+	if style == "" {
+		return "", nil
+	}
+	key := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(style))
+	if canonical, ok := bodyStyleAliases[key]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("%w: unknown body style %q (use one of %s)", ErrInvalidInput, style, strings.Join(BodyStyles, ", "))
+}
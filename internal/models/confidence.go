@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Confidence bands, from most to least confident
+const (
+	BandHigh   = "high"
+	BandMedium = "medium"
+	BandLow    = "low"
+)
+
+// ConfidenceBands are the lower bounds of the high and medium bands: a
+// confidence of at least High is "high", at least Medium "medium", and
+// anything less "low"
+type ConfidenceBands struct {
+	High   float64
+	Medium float64
+}
+
+// DefaultConfidenceBands puts exact matches in the high band, similarity
+// matches above the database threshold in medium or high, and LLM answers
+// in the low band
+var DefaultConfidenceBands = ConfidenceBands{High: 0.9, Medium: 0.7}
+
+// ParseConfidenceBands parses "high,medium" thresholds, e.g. "0.9,0.7"
+func ParseConfidenceBands(s string) (ConfidenceBands, error) {
+	high, medium, ok := strings.Cut(s, ",")
+	if !ok {
+		return ConfidenceBands{}, fmt.Errorf("expected high,medium thresholds, e.g. 0.9,0.7, got %q", s)
+	}
+
+	var bands ConfidenceBands
+	var err error
+	if bands.High, err = strconv.ParseFloat(strings.TrimSpace(high), 64); err != nil {
+		return ConfidenceBands{}, fmt.Errorf("invalid high confidence threshold: %s", high)
+	}
+	if bands.Medium, err = strconv.ParseFloat(strings.TrimSpace(medium), 64); err != nil {
+		return ConfidenceBands{}, fmt.Errorf("invalid medium confidence threshold: %s", medium)
+	}
+	if err := bands.Validate(); err != nil {
+		return ConfidenceBands{}, err
+	}
+	return bands, nil
+}
+
+// Validate checks that both thresholds are between 0 and 1 and the high
+// threshold is above the medium one
+func (b ConfidenceBands) Validate() error {
+	if b.Medium <= 0 || b.High > 1 || b.Medium >= b.High {
+		return fmt.Errorf("confidence bands need 0 < medium < high <= 1, got %g,%g", b.High, b.Medium)
+	}
+	return nil
+}
+
+// Band returns the band holding confidence
+func (b ConfidenceBands) Band(confidence float64) string {
+	switch {
+	case confidence >= b.High:
+		return BandHigh
+	case confidence >= b.Medium:
+		return BandMedium
+	default:
+		return BandLow
+	}
+}
@@ -1,8 +1,13 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,11 +17,81 @@ type Config struct {
 	DatabaseURL       string
 	OpenAIAPIKey      string
 	AnthropicAPIKey   string
-	EmbeddingProvider string // "openai" or "ollama"
-	LLMProvider       string // "claude" or "ollama"
-	OllamaURL         string // Ollama API URL (default: http://localhost:11434)
-	OllamaModel       string // Ollama embedding model (default: nomic-embed-text)
-	OllamaLLMModel    string // Ollama LLM model (default: llama3.2)
+	EmbeddingProvider string   // "openai", "ollama" or "local"
+	LLMProvider       string   // "claude" or "ollama"
+	OllamaURL         string   // Ollama API URL (default: http://localhost:11434)
+	OllamaModel       string   // Ollama embedding model (default: nomic-embed-text)
+	OllamaLLMModel    string   // Ollama LLM model (default: gemma3)
+	ClaudeModel       string   // Claude model (default: claude-3-5-sonnet-20241022)
+	AnthropicVersion  string   // anthropic-version header sent to Claude (default: 2023-06-01)
+	LocalEmbedURL     string   // Endpoint of a self-hosted embeddings server
+	LocalEmbedShape   string   // "openai" or "tei" (default: openai)
+	LocalEmbedModel   string   // Model name sent to an OpenAI-compatible local server
+	LocalEmbedDim     int      // Expected local embedding dimension, 0 to accept any
+	EmbeddingRace     []string // Embedding providers to race concurrently, e.g. ["openai", "ollama"]
+	EmbeddingChain    []string // Embedding providers to try in order until one succeeds
+	LLMChain          []string // LLM providers to try in order until one succeeds
+	SimilarityPool    int      // Similarity candidates considered before thresholding (default: 5)
+	RecencyWeight     float64  // Similarity penalty per model year from the queried year, 0 to disable
+	NonEVGuard        bool     // Reject known non-EV make/models before embedding/LLM calls (default: true)
+	NonEVModels       []string // Extra "Make:Model" entries for the non-EV guard
+	ProductionCheck   bool     // Warn when the queried year precedes the model's first year (default: true)
+	ProductionYears   []string // Extra "Make:Model:Year" first model year entries
+	ChemistryInfer    bool     // Infer a missing chemistry from make, model and capacity
+	ChemistryRules    []string // Extra "Make[/ModelPrefix]:Chemistry[:MaxKWh]" inference rules
+	ChemistryCands    bool     // Ask the LLM for ranked chemistry candidates with probabilities
+	Priors            Priors   // Per make/model priors loaded from PRIORS_FILE
+	SaveLLMResults    bool     // Store LLM fallback answers in the database with source "llm"
+	StoreRawResponse  bool     // Also store the raw LLM response text with saved answers
+	UserAgent         string   // User-Agent for provider requests (default: ev-oracle/<version>)
+	// LLMExtraParams are extra fields merged into every LLM request body
+	LLMExtraParams map[string]any
+	// EmbedMaxInput caps embedding input characters: 0 for the provider default, -1 for none
+	EmbedMaxInput int
+	// AltEmbedProvider fills and searches the secondary embedding column
+	AltEmbedProvider string
+	// AltEmbedModel overrides the Ollama or local model for AltEmbedProvider
+	AltEmbedModel string
+	// StoreBackend is where the resolver keeps specs: "postgres" or "json"
+	StoreBackend string
+	// StorePath is the spec file used by the json store backend
+	StorePath string
+	// DBQueryAttempts is how often Postgres reads are tried on transient
+	// errors, 0 for the client default
+	DBQueryAttempts int
+	// LLMCallThreshold is the number of estimated LLM calls above which batch
+	// jobs require confirmation: 0 for the default of 100, -1 for no limit
+	LLMCallThreshold int
+	// LLMInputPrice and LLMOutputPrice are USD per million tokens, used to
+	// estimate the cost of batch jobs; 0 when unknown
+	LLMInputPrice  float64
+	LLMOutputPrice float64
+	// ProviderRetries is how often a throttled or failed embedding or LLM
+	// request is retried: 0 for the default of 2, -1 for none
+	ProviderRetries int
+	// ProviderBackoff is the delay before the first retry, doubled for each
+	// further one (default: 500ms)
+	ProviderBackoff time.Duration
+	// ProviderTimeout bounds each embedding or LLM request attempt, 0 for none
+	ProviderTimeout time.Duration
+	// BreakerThreshold is the number of consecutive failed requests to a
+	// provider host after which it is not called for BreakerCooldown: 0 for
+	// the default of 5, -1 to disable the breaker
+	BreakerThreshold int
+	// BreakerCooldown is how long a tripped breaker refuses calls (default: 30s)
+	BreakerCooldown time.Duration
+	// ConfidenceBands are the thresholds of the high and medium confidence bands
+	ConfidenceBands ConfidenceBands
+	// ConfidenceDisplay is how text, table and markdown output show
+	// confidence: "score", "band" or "both" (default: score)
+	ConfidenceDisplay string
+	// QueryTemplate and DocumentTemplate replace the default embedding texts
+	// when set; see embedding.TextFields for their fields
+	QueryTemplate    *template.Template
+	DocumentTemplate *template.Template
+
+	skipDotEnv    bool // Read only the process environment, never a .env file
+	skipProviders bool // Don't require embedding or LLM provider settings
 }
 
 // ConfigOption is a functional option for Config
@@ -26,19 +101,27 @@ type ConfigOption func(*Config) error
 func NewConfig(opts ...ConfigOption) (*Config, error) {
 	cfg := &Config{}
 
-	// Apply default options (load from environment)
-	if err := WithEnvDefaults()(cfg); err != nil {
-		return nil, err
-	}
-
-	// Apply user-provided options
+	// Apply user-provided options first, so loader settings such as
+	// WithoutDotEnv are known before the environment is read
 	for _, opt := range opts {
 		if err := opt(cfg); err != nil {
 			return nil, err
 		}
 	}
 
+	// Apply default options (load from environment); explicit values are
+	// kept, since only the fields the options left unset are filled
+	if err := WithEnvDefaults()(cfg); err != nil {
+		return nil, err
+	}
+
 	// Set defaults
+	if len(cfg.EmbeddingChain) > 0 {
+		cfg.EmbeddingProvider = cfg.EmbeddingChain[0]
+	}
+	if len(cfg.LLMChain) > 0 {
+		cfg.LLMProvider = cfg.LLMChain[0]
+	}
 	if cfg.EmbeddingProvider == "" {
 		cfg.EmbeddingProvider = "openai" // Default to OpenAI
 	}
@@ -46,44 +129,357 @@ func NewConfig(opts ...ConfigOption) (*Config, error) {
 		cfg.LLMProvider = "ollama" // Default to Ollama
 	}
 	if cfg.OllamaURL == "" {
-		cfg.OllamaURL = "http://localhost:11434"
+		cfg.OllamaURL = DefaultOllamaURL
 	}
 	if cfg.OllamaModel == "" {
-		cfg.OllamaModel = "nomic-embed-text"
+		cfg.OllamaModel = DefaultOllamaEmbedModel
 	}
 	if cfg.OllamaLLMModel == "" {
-		cfg.OllamaLLMModel = "gemma3"
+		cfg.OllamaLLMModel = DefaultOllamaLLMModel
+	}
+	if cfg.ClaudeModel == "" {
+		cfg.ClaudeModel = DefaultClaudeModel
+	}
+	if cfg.AnthropicVersion == "" {
+		cfg.AnthropicVersion = DefaultAnthropicVersion
+	}
+	if cfg.SimilarityPool == 0 {
+		cfg.SimilarityPool = 5
+	}
+	if cfg.LocalEmbedShape == "" {
+		cfg.LocalEmbedShape = "openai"
+	}
+	if cfg.StoreBackend == "" {
+		cfg.StoreBackend = "postgres"
+	}
+	if cfg.StorePath == "" {
+		cfg.StorePath = "ev-specs.json"
+	}
+	if cfg.LLMCallThreshold == 0 {
+		cfg.LLMCallThreshold = 100
+	}
+	if cfg.ProviderRetries == 0 {
+		cfg.ProviderRetries = DefaultProviderRetries
+	}
+	if cfg.ProviderBackoff == 0 {
+		cfg.ProviderBackoff = DefaultProviderBackoff
+	}
+	if cfg.BreakerThreshold == 0 {
+		cfg.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown == 0 {
+		cfg.BreakerCooldown = DefaultBreakerCooldown
+	}
+	if cfg.ConfidenceBands == (ConfidenceBands{}) {
+		cfg.ConfidenceBands = DefaultConfidenceBands
+	}
+	if cfg.ConfidenceDisplay == "" {
+		cfg.ConfidenceDisplay = "score"
 	}
 
 	// Validate required fields
-	if cfg.DatabaseURL == "" {
+	if err := cfg.ConfidenceBands.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.ConfidenceDisplay {
+	case "score", "band", "both":
+	default:
+		return nil, fmt.Errorf("invalid CONFIDENCE_DISPLAY: %s (use score, band or both)", cfg.ConfidenceDisplay)
+	}
+	if cfg.StoreBackend != "postgres" && cfg.StoreBackend != "json" {
+		return nil, fmt.Errorf("invalid STORE_BACKEND: %s (use postgres or json)", cfg.StoreBackend)
+	}
+	if cfg.DatabaseURL == "" && cfg.StoreBackend == "postgres" {
 		return nil, fmt.Errorf("NEON_DATABASE_URL is required")
 	}
+	if cfg.skipProviders {
+		return cfg, nil
+	}
 	if cfg.EmbeddingProvider == "openai" && cfg.OpenAIAPIKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY is required when using OpenAI embeddings")
 	}
+	if cfg.LocalEmbedShape != "openai" && cfg.LocalEmbedShape != "tei" {
+		return nil, fmt.Errorf("invalid LOCAL_EMBEDDING_SHAPE: %s (use openai or tei)", cfg.LocalEmbedShape)
+	}
+	if cfg.EmbeddingProvider == "local" && cfg.LocalEmbedURL == "" {
+		return nil, fmt.Errorf("LOCAL_EMBEDDING_URL is required when using local embeddings")
+	}
+	for _, provider := range cfg.EmbeddingRace {
+		if provider != "openai" && provider != "ollama" && provider != "local" {
+			return nil, fmt.Errorf("invalid EMBEDDING_RACE provider: %s", provider)
+		}
+		if provider == "openai" && cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required when racing OpenAI embeddings")
+		}
+		if provider == "local" && cfg.LocalEmbedURL == "" {
+			return nil, fmt.Errorf("LOCAL_EMBEDDING_URL is required when racing local embeddings")
+		}
+	}
+	if len(cfg.EmbeddingChain) > 0 && len(cfg.EmbeddingRace) > 0 {
+		return nil, fmt.Errorf("EMBEDDING_CHAIN and EMBEDDING_RACE cannot both be set")
+	}
+	for _, provider := range cfg.EmbeddingChain {
+		if provider != "openai" && provider != "ollama" && provider != "local" {
+			return nil, fmt.Errorf("invalid EMBEDDING_CHAIN provider: %s", provider)
+		}
+		if provider == "openai" && cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required when chaining OpenAI embeddings")
+		}
+		if provider == "local" && cfg.LocalEmbedURL == "" {
+			return nil, fmt.Errorf("LOCAL_EMBEDDING_URL is required when chaining local embeddings")
+		}
+	}
+	switch cfg.AltEmbedProvider {
+	case "", "ollama":
+	case "openai":
+		if cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required when using OpenAI alt embeddings")
+		}
+	case "local":
+		if cfg.LocalEmbedURL == "" {
+			return nil, fmt.Errorf("LOCAL_EMBEDDING_URL is required when using local alt embeddings")
+		}
+	default:
+		return nil, fmt.Errorf("invalid EMBEDDING_ALT_PROVIDER: %s", cfg.AltEmbedProvider)
+	}
 	if cfg.LLMProvider == "claude" && cfg.AnthropicAPIKey == "" {
 		return nil, fmt.Errorf("ANTHROPIC_API_KEY is required when using Claude LLM")
 	}
+	for _, provider := range cfg.LLMChain {
+		if provider != "claude" && provider != "ollama" {
+			return nil, fmt.Errorf("invalid LLM_CHAIN provider: %s", provider)
+		}
+		if provider == "claude" && cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY is required when chaining Claude LLM")
+		}
+	}
 
 	return cfg, nil
 }
 
-// WithEnvDefaults loads configuration from environment variables
-// It automatically loads .env file if it exists (errors are ignored if file doesn't exist)
+// WithEnvDefaults loads configuration from environment variables into the
+// fields that are still unset, so values set by earlier options win. Switches
+// can only be turned on this way; NON_EV_GUARD and PRODUCTION_YEAR_CHECK,
+// which default to on, are always read from the environment.
+// It automatically loads a .env file from the current working directory if it
+// exists (errors are ignored if the file doesn't exist). Variables already set
+// in the process environment take precedence over the file. The file is skipped
+// when WithoutDotEnv is given or EV_ORACLE_NO_DOTENV=1 is set.
 func WithEnvDefaults() ConfigOption {
 	return func(cfg *Config) error {
 		// Try to load .env file (ignore error if file doesn't exist)
-		_ = godotenv.Load()
-
-		cfg.DatabaseURL = os.Getenv("NEON_DATABASE_URL")
-		cfg.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
-		cfg.AnthropicAPIKey = os.Getenv("ANTHROPIC_API_KEY")
-		cfg.EmbeddingProvider = os.Getenv("EMBEDDING_PROVIDER")
-		cfg.LLMProvider = os.Getenv("LLM_PROVIDER")
-		cfg.OllamaURL = os.Getenv("OLLAMA_URL")
-		cfg.OllamaModel = os.Getenv("OLLAMA_MODEL")
-		cfg.OllamaLLMModel = os.Getenv("OLLAMA_LLM_MODEL")
+		if !cfg.skipDotEnv && os.Getenv("EV_ORACLE_NO_DOTENV") != "1" {
+			_ = godotenv.Load()
+		}
+
+		envString(&cfg.DatabaseURL, "NEON_DATABASE_URL")
+		envString(&cfg.OpenAIAPIKey, "OPENAI_API_KEY")
+		envString(&cfg.AnthropicAPIKey, "ANTHROPIC_API_KEY")
+		envString(&cfg.EmbeddingProvider, "EMBEDDING_PROVIDER")
+		envString(&cfg.LLMProvider, "LLM_PROVIDER")
+		envString(&cfg.OllamaURL, "OLLAMA_URL")
+		envString(&cfg.OllamaModel, "OLLAMA_MODEL")
+		envString(&cfg.OllamaLLMModel, "OLLAMA_LLM_MODEL")
+		if model, ok := os.LookupEnv("CLAUDE_MODEL"); ok && cfg.ClaudeModel == "" {
+			if cfg.ClaudeModel = strings.TrimSpace(model); cfg.ClaudeModel == "" {
+				return fmt.Errorf("invalid CLAUDE_MODEL: must not be empty")
+			}
+		}
+		if version, ok := os.LookupEnv("ANTHROPIC_VERSION"); ok && cfg.AnthropicVersion == "" {
+			if cfg.AnthropicVersion = strings.TrimSpace(version); cfg.AnthropicVersion == "" {
+				return fmt.Errorf("invalid ANTHROPIC_VERSION: must not be empty")
+			}
+		}
+		envString(&cfg.LocalEmbedURL, "LOCAL_EMBEDDING_URL")
+		envString(&cfg.LocalEmbedShape, "LOCAL_EMBEDDING_SHAPE")
+		envString(&cfg.LocalEmbedModel, "LOCAL_EMBEDDING_MODEL")
+		if dim := os.Getenv("LOCAL_EMBEDDING_DIMENSION"); dim != "" && cfg.LocalEmbedDim == 0 {
+			n, err := strconv.Atoi(dim)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid LOCAL_EMBEDDING_DIMENSION: %s", dim)
+			}
+			cfg.LocalEmbedDim = n
+		}
+		envList(&cfg.EmbeddingRace, "EMBEDDING_RACE")
+		envList(&cfg.EmbeddingChain, "EMBEDDING_CHAIN")
+		envList(&cfg.LLMChain, "LLM_CHAIN")
+		if pool := os.Getenv("SIMILARITY_POOL"); pool != "" && cfg.SimilarityPool == 0 {
+			n, err := strconv.Atoi(pool)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid SIMILARITY_POOL: %s", pool)
+			}
+			cfg.SimilarityPool = n
+		}
+		if weight := os.Getenv("RECENCY_WEIGHT"); weight != "" && cfg.RecencyWeight == 0 {
+			w, err := strconv.ParseFloat(weight, 64)
+			if err != nil || w < 0 {
+				return fmt.Errorf("invalid RECENCY_WEIGHT: %s", weight)
+			}
+			cfg.RecencyWeight = w
+		}
+		cfg.NonEVGuard = os.Getenv("NON_EV_GUARD") != "false"
+		envList(&cfg.NonEVModels, "NON_EV_MODELS")
+		cfg.ProductionCheck = os.Getenv("PRODUCTION_YEAR_CHECK") != "false"
+		envList(&cfg.ProductionYears, "PRODUCTION_YEARS")
+		cfg.ChemistryInfer = cfg.ChemistryInfer || os.Getenv("CHEMISTRY_INFERENCE") == "true"
+		envList(&cfg.ChemistryRules, "CHEMISTRY_RULES")
+		cfg.ChemistryCands = cfg.ChemistryCands || os.Getenv("CHEMISTRY_CANDIDATES") == "true"
+		if path := os.Getenv("PRIORS_FILE"); path != "" && cfg.Priors == nil {
+			priors, err := LoadPriors(path)
+			if err != nil {
+				return fmt.Errorf("invalid PRIORS_FILE: %w", err)
+			}
+			cfg.Priors = priors
+		}
+		cfg.SaveLLMResults = cfg.SaveLLMResults || os.Getenv("SAVE_LLM_RESULTS") == "true"
+		cfg.StoreRawResponse = cfg.StoreRawResponse || os.Getenv("STORE_LLM_RAW_RESPONSE") == "true"
+		envString(&cfg.UserAgent, "USER_AGENT")
+		if limit := os.Getenv("EMBEDDING_MAX_INPUT_CHARS"); limit != "" && cfg.EmbedMaxInput == 0 {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < -1 {
+				return fmt.Errorf("invalid EMBEDDING_MAX_INPUT_CHARS: %s", limit)
+			}
+			cfg.EmbedMaxInput = n
+		}
+		envString(&cfg.AltEmbedProvider, "EMBEDDING_ALT_PROVIDER")
+		envString(&cfg.AltEmbedModel, "EMBEDDING_ALT_MODEL")
+		envString(&cfg.StoreBackend, "STORE_BACKEND")
+		envString(&cfg.StorePath, "STORE_PATH")
+		if attempts := os.Getenv("DB_QUERY_ATTEMPTS"); attempts != "" && cfg.DBQueryAttempts == 0 {
+			n, err := strconv.Atoi(attempts)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid DB_QUERY_ATTEMPTS: %s", attempts)
+			}
+			cfg.DBQueryAttempts = n
+		}
+		if threshold := os.Getenv("LLM_CALL_THRESHOLD"); threshold != "" && cfg.LLMCallThreshold == 0 {
+			n, err := strconv.Atoi(threshold)
+			if err != nil || n < -1 {
+				return fmt.Errorf("invalid LLM_CALL_THRESHOLD: %s", threshold)
+			}
+			cfg.LLMCallThreshold = n
+		}
+		if retries := os.Getenv("PROVIDER_RETRIES"); retries != "" && cfg.ProviderRetries == 0 {
+			n, err := strconv.Atoi(retries)
+			if err != nil || n < -1 {
+				return fmt.Errorf("invalid PROVIDER_RETRIES: %s", retries)
+			}
+			cfg.ProviderRetries = n
+		}
+		if err := envDuration(&cfg.ProviderBackoff, "PROVIDER_RETRY_BACKOFF"); err != nil {
+			return err
+		}
+		if err := envDuration(&cfg.ProviderTimeout, "PROVIDER_TIMEOUT"); err != nil {
+			return err
+		}
+		if threshold := os.Getenv("PROVIDER_BREAKER_THRESHOLD"); threshold != "" && cfg.BreakerThreshold == 0 {
+			n, err := strconv.Atoi(threshold)
+			if err != nil || n < -1 {
+				return fmt.Errorf("invalid PROVIDER_BREAKER_THRESHOLD: %s", threshold)
+			}
+			cfg.BreakerThreshold = n
+		}
+		if err := envDuration(&cfg.BreakerCooldown, "PROVIDER_BREAKER_COOLDOWN"); err != nil {
+			return err
+		}
+		if prices := os.Getenv("LLM_PRICE_PER_MTOK"); prices != "" && cfg.LLMInputPrice == 0 && cfg.LLMOutputPrice == 0 {
+			input, output, ok := strings.Cut(prices, ",")
+			in, inErr := strconv.ParseFloat(strings.TrimSpace(input), 64)
+			out, outErr := strconv.ParseFloat(strings.TrimSpace(output), 64)
+			if !ok || inErr != nil || outErr != nil || in < 0 || out < 0 {
+				return fmt.Errorf("invalid LLM_PRICE_PER_MTOK: %s (use input,output, e.g. 3,15)", prices)
+			}
+			cfg.LLMInputPrice, cfg.LLMOutputPrice = in, out
+		}
+		if bands := os.Getenv("CONFIDENCE_BANDS"); bands != "" && cfg.ConfidenceBands == (ConfidenceBands{}) {
+			parsed, err := ParseConfidenceBands(bands)
+			if err != nil {
+				return fmt.Errorf("invalid CONFIDENCE_BANDS: %w", err)
+			}
+			cfg.ConfidenceBands = parsed
+		}
+		if cfg.ConfidenceDisplay == "" {
+			cfg.ConfidenceDisplay = strings.ToLower(os.Getenv("CONFIDENCE_DISPLAY"))
+		}
+		if text := os.Getenv("EMBEDDING_QUERY_TEMPLATE"); text != "" && cfg.QueryTemplate == nil {
+			tmpl, err := parseTextTemplate("query", text)
+			if err != nil {
+				return fmt.Errorf("invalid EMBEDDING_QUERY_TEMPLATE: %w", err)
+			}
+			cfg.QueryTemplate = tmpl
+		}
+		if text := os.Getenv("EMBEDDING_DOCUMENT_TEMPLATE"); text != "" && cfg.DocumentTemplate == nil {
+			tmpl, err := parseTextTemplate("document", text)
+			if err != nil {
+				return fmt.Errorf("invalid EMBEDDING_DOCUMENT_TEMPLATE: %w", err)
+			}
+			cfg.DocumentTemplate = tmpl
+		}
+		if extra := os.Getenv("LLM_EXTRA_PARAMS"); extra != "" && cfg.LLMExtraParams == nil {
+			if err := json.Unmarshal([]byte(extra), &cfg.LLMExtraParams); err != nil {
+				return fmt.Errorf("invalid LLM_EXTRA_PARAMS: must be a JSON object: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// envString sets *field from the environment variable name unless it is
+// already set
+func envString(field *string, name string) {
+	if *field == "" {
+		*field = os.Getenv(name)
+	}
+}
+
+// envList sets *field from the comma-separated environment variable name
+// unless it is already set
+func envList(field *[]string, name string) {
+	if *field == nil {
+		*field = splitList(os.Getenv(name))
+	}
+}
+
+// envDuration sets *field from the environment variable name, a Go duration
+// such as 500ms or 2m, unless it is already set
+func envDuration(field *time.Duration, name string) error {
+	value := os.Getenv(name)
+	if value == "" || *field != 0 {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid %s: %s (use a duration such as 500ms or 30s)", name, value)
+	}
+	*field = d
+	return nil
+}
+
+// splitList splits a comma-separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// WithoutDotEnv disables loading the .env file, so configuration is read only
+// from the process environment and explicit options
+func WithoutDotEnv() ConfigOption {
+	return func(cfg *Config) error {
+		cfg.skipDotEnv = true
+		return nil
+	}
+}
+
+// WithDatabaseOnly skips validation of the embedding and LLM provider
+// settings, for commands that only talk to the database
+func WithDatabaseOnly() ConfigOption {
+	return func(cfg *Config) error {
+		cfg.skipProviders = true
 		return nil
 	}
 }
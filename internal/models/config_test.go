@@ -0,0 +1,110 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewConfigAppliesOptionsOnce(t *testing.T) {
+	t.Setenv("EV_ORACLE_NO_DOTENV", "1")
+	t.Setenv("NEON_DATABASE_URL", "postgres://env/db")
+
+	calls := 0
+	count := func(cfg *Config) error {
+		calls++
+		return nil
+	}
+	if _, err := NewConfig(WithDatabaseOnly(), count); err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("option ran %d times, want 1", calls)
+	}
+}
+
+func TestNewConfigOptionsOverrideEnvironment(t *testing.T) {
+	t.Setenv("EV_ORACLE_NO_DOTENV", "1")
+	t.Setenv("NEON_DATABASE_URL", "postgres://env/db")
+	t.Setenv("OPENAI_API_KEY", "env-key")
+	t.Setenv("SIMILARITY_POOL", "9")
+
+	cfg, err := NewConfig(WithDatabaseURL("postgres://option/db"))
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if cfg.DatabaseURL != "postgres://option/db" {
+		t.Errorf("DatabaseURL = %q, want the option's value", cfg.DatabaseURL)
+	}
+	if cfg.OpenAIAPIKey != "env-key" || cfg.SimilarityPool != 9 {
+		t.Errorf("OpenAIAPIKey = %q, SimilarityPool = %d, want the environment's values", cfg.OpenAIAPIKey, cfg.SimilarityPool)
+	}
+}
+
+func TestNewConfigWithoutDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("STORE_PATH=from-dotenv.json\n"), 0o600); err != nil {
+		t.Fatalf("failed to write .env: %v", err)
+	}
+	t.Chdir(dir)
+	t.Setenv("EV_ORACLE_NO_DOTENV", "")
+	t.Setenv("STORE_BACKEND", "json")
+	t.Setenv("STORE_PATH", "") // restored after the test
+	os.Unsetenv("STORE_PATH")
+
+	cfg, err := NewConfig(WithoutDotEnv(), WithDatabaseOnly())
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if cfg.StorePath != "ev-specs.json" {
+		t.Errorf("StorePath = %q, want the default since the .env file is skipped", cfg.StorePath)
+	}
+}
+
+func TestNewConfigProviderResilience(t *testing.T) {
+	t.Setenv("EV_ORACLE_NO_DOTENV", "1")
+	t.Setenv("NEON_DATABASE_URL", "postgres://env/db")
+
+	cfg, err := NewConfig(WithDatabaseOnly())
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if cfg.ProviderRetries != DefaultProviderRetries || cfg.ProviderBackoff != DefaultProviderBackoff || cfg.ProviderTimeout != 0 ||
+		cfg.BreakerThreshold != DefaultBreakerThreshold || cfg.BreakerCooldown != DefaultBreakerCooldown {
+		t.Errorf("defaults = %d %v %v %d %v", cfg.ProviderRetries, cfg.ProviderBackoff, cfg.ProviderTimeout, cfg.BreakerThreshold, cfg.BreakerCooldown)
+	}
+
+	t.Setenv("PROVIDER_RETRIES", "-1")
+	t.Setenv("PROVIDER_RETRY_BACKOFF", "2s")
+	t.Setenv("PROVIDER_TIMEOUT", "45s")
+	t.Setenv("PROVIDER_BREAKER_THRESHOLD", "10")
+	t.Setenv("PROVIDER_BREAKER_COOLDOWN", "1m")
+	cfg, err = NewConfig(WithDatabaseOnly())
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if cfg.ProviderRetries != -1 || cfg.ProviderBackoff != 2*time.Second || cfg.ProviderTimeout != 45*time.Second ||
+		cfg.BreakerThreshold != 10 || cfg.BreakerCooldown != time.Minute {
+		t.Errorf("from the environment = %d %v %v %d %v", cfg.ProviderRetries, cfg.ProviderBackoff, cfg.ProviderTimeout, cfg.BreakerThreshold, cfg.BreakerCooldown)
+	}
+}
+
+func TestNewConfigRejectsInvalidProviderResilience(t *testing.T) {
+	for name, value := range map[string]string{
+		"PROVIDER_RETRIES":           "-2",
+		"PROVIDER_RETRY_BACKOFF":     "500",
+		"PROVIDER_TIMEOUT":           "-1s",
+		"PROVIDER_BREAKER_THRESHOLD": "many",
+		"PROVIDER_BREAKER_COOLDOWN":  "0s",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("EV_ORACLE_NO_DOTENV", "1")
+			t.Setenv("NEON_DATABASE_URL", "postgres://env/db")
+			t.Setenv(name, value)
+			if _, err := NewConfig(WithDatabaseOnly()); err == nil {
+				t.Errorf("NewConfig accepted %s=%s", name, value)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ConfidenceThreshold is the minimum confidence score for database results
 // before falling back to LLM queries
 const ConfidenceThreshold = 0.8
@@ -7,5 +9,33 @@ const ConfidenceThreshold = 0.8
 // LLMConfidenceScore is the confidence score assigned to LLM-generated results
 const LLMConfidenceScore = 0.5
 
+// DefaultConfidence is the confidence of a stored, non-authoritative spec
+// without one of its own: LLMConfidenceScore for saved LLM answers and 1.0
+// for everything else
+func DefaultConfidence(source string) float64 {
+	if source == "llm" {
+		return LLMConfidenceScore
+	}
+	return 1.0
+}
+
 // EmbeddingDimension is the dimension of the OpenAI text-embedding-3-small model
 const EmbeddingDimension = 768
+
+// Provider defaults used by NewConfig. The llm package uses the same
+// constants when no model or version is given.
+const (
+	DefaultOllamaURL        = "http://localhost:11434"
+	DefaultOllamaEmbedModel = "nomic-embed-text"
+	DefaultOllamaLLMModel   = "gemma3"
+	DefaultClaudeModel      = "claude-3-5-sonnet-20241022"
+	DefaultAnthropicVersion = "2023-06-01"
+)
+
+// Provider request resilience defaults used by NewConfig
+const (
+	DefaultProviderRetries  = 2
+	DefaultProviderBackoff  = 500 * time.Millisecond
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
@@ -1,13 +1,60 @@
 package models
 
+// ChemistryInferred marks a chemistry filled in by heuristics rather than
+// reported by the database or LLM
+const ChemistryInferred = "inferred"
+
+// ChemistryCandidate is one possible battery chemistry with the LLM's
+// estimated probability
+type ChemistryCandidate struct {
+	Name string  `json:"name"`
+	Prob float64 `json:"probability"`
+}
+
 // EVSpec represents the battery specifications for an electric vehicle
 type EVSpec struct {
-	Make       string  `json:"make"`
-	Model      string  `json:"model"`
-	Year       int     `json:"year"`
-	Capacity   float64 `json:"capacity_kwh"` // Battery capacity in kWh
-	Power      float64 `json:"power_kw"`     // Power output in kW
-	Chemistry  string  `json:"chemistry"`    // Battery chemistry type
-	Confidence float64 `json:"confidence"`   // Confidence score from similarity search
-	Source     string  `json:"source"`       // Source of the data (e.g., "database", "llm")
+	Make       string   `json:"make"`
+	Model      string   `json:"model"`
+	Year       int      `json:"year"`
+	Capacity   float64  `json:"capacity_kwh"`   // Battery capacity in kWh
+	Power      float64  `json:"power_kw"`       // Power output in kW
+	Chemistry  string   `json:"chemistry"`      // Battery chemistry type
+	Confidence float64  `json:"confidence"`     // Confidence score from similarity search
+	Source     string   `json:"source"`         // Source of the data (e.g., "database", "llm")
+	Tags       []string `json:"tags,omitempty"` // Labels for organizing curated subsets (e.g., "verified")
+
+	// Notes is a free-text annotation, e.g. trim differences or where the
+	// data came from. It is folded into the stored embedding text.
+	Notes string `json:"notes,omitempty"`
+
+	// BodyStyle is the normalized body style, e.g. "SUV" or "Sedan", or
+	// empty when unknown. It is folded into the stored embedding text.
+	BodyStyle string `json:"body_style,omitempty"`
+
+	// ChemistrySource is ChemistryInferred when Chemistry was guessed by the
+	// inference rules, SourcePrior when it came from a configured prior, and
+	// empty when it was reported
+	ChemistrySource string `json:"chemistry_source,omitempty"`
+
+	// CapacitySource is SourcePrior when Capacity came from a configured
+	// prior, and empty when it was reported
+	CapacitySource string `json:"capacity_source,omitempty"`
+
+	// ConfidenceBand is the confidence as "high", "medium" or "low". It is
+	// set in all JSON and env output, and in other output when confidence
+	// bands are shown; stored specs never carry it.
+	ConfidenceBand string `json:"confidence_band,omitempty"`
+
+	// Authoritative marks a hand-verified spec that saved LLM answers and
+	// non-authoritative upserts never overwrite
+	Authoritative bool `json:"authoritative,omitempty"`
+
+	// ChemistryAlternatives ranks the chemistries the LLM considered, most
+	// likely first, when chemistry candidates are requested. Chemistry is the
+	// first candidate. Never stored in the database.
+	ChemistryAlternatives []ChemistryCandidate `json:"chemistry_alternatives,omitempty"`
+
+	// RawResponse is the unparsed LLM output for LLM-derived specs. It is only
+	// persisted when STORE_LLM_RAW_RESPONSE is enabled.
+	RawResponse string `json:"raw_response,omitempty"`
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SpecRevision is a superseded value of an EV spec from the history table
+type SpecRevision struct {
+	EVSpec
+	Operation    string     `json:"operation"`            // "UPDATE" or "DELETE"
+	ValidFrom    *time.Time `json:"valid_from,omitempty"` // When the superseded value was written
+	SupersededAt time.Time  `json:"superseded_at"`        // When it was replaced or deleted
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MatchKey returns the normalized key exact lookups compare: make and model
+// lowercased with everything but letters and digits removed, and the year,
+// e.g. "tesla|model3|2022" for Tesla "Model 3" or "model-3". It mirrors the
+// ev_match_key SQL function behind the match_key column.
+func MatchKey(make, model string, year int) string {
+	return normalizeKeyPart(make) + "|" + normalizeKeyPart(model) + "|" + strconv.Itoa(year)
+}
+
+// normalizeKeyPart lowercases s and keeps only its letters and digits
+func normalizeKeyPart(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
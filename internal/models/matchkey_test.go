@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		make, model string
+		year        int
+		want        string
+	}{
+		{"Tesla", "Model 3", 2022, "tesla|model3|2022"},
+		{"tesla", "model-3", 2022, "tesla|model3|2022"},
+		{" TESLA ", "Model3", 2022, "tesla|model3|2022"},
+		{"Mercedes-Benz", "EQS 450+", 2023, "mercedesbenz|eqs450|2023"},
+		{"Škoda", "Enyaq iV", 2021, "škoda|enyaqiv|2021"},
+	}
+	for _, tt := range tests {
+		if got := MatchKey(tt.make, tt.model, tt.year); got != tt.want {
+			t.Errorf("MatchKey(%q, %q, %d) = %q, want %q", tt.make, tt.model, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestMatchKeyKeepsYearsApart(t *testing.T) {
+	if MatchKey("Tesla", "Model 3", 2022) == MatchKey("Tesla", "Model 3", 2023) {
+		t.Error("specs of different years share a match key")
+	}
+}
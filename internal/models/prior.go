@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SourcePrior marks a field filled in from a configured prior rather than
+// reported by the database or LLM
+const SourcePrior = "prior"
+
+// Prior is what is typically true of a make, or of the models of a make
+// starting with a prefix, e.g. "Tesla → usually NMC or NCA"
+type Prior struct {
+	Make        string   `json:"make"`
+	Model       string   `json:"model,omitempty"`     // Optional model prefix, case-insensitive
+	Chemistries []string `json:"chemistry,omitempty"` // Typical chemistries, most common first
+	Capacity    float64  `json:"capacity_kwh,omitempty"`
+	MinCapacity float64  `json:"min_capacity_kwh,omitempty"`
+	MaxCapacity float64  `json:"max_capacity_kwh,omitempty"`
+}
+
+// Priors are the configured priors. Lookup prefers the longest matching model
+// prefix over a make-wide prior.
+type Priors []Prior
+
+// LoadPriors reads priors from a JSON file holding an array of Prior objects
+func LoadPriors(path string) (Priors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read priors file: %w", err)
+	}
+
+	var priors Priors
+	if err := json.Unmarshal(data, &priors); err != nil {
+		return nil, fmt.Errorf("failed to parse priors file %s: %w", path, err)
+	}
+	for i, p := range priors {
+		if strings.TrimSpace(p.Make) == "" {
+			return nil, fmt.Errorf("invalid prior %d in %s: make is required", i+1, path)
+		}
+		if p.MaxCapacity > 0 && p.MinCapacity > p.MaxCapacity {
+			return nil, fmt.Errorf("invalid prior %d in %s: min_capacity_kwh exceeds max_capacity_kwh", i+1, path)
+		}
+	}
+	return priors, nil
+}
+
+// Lookup returns the most specific prior for make and model
+func (priors Priors) Lookup(make, model string) (Prior, bool) {
+	make = strings.TrimSpace(make)
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	best, found := Prior{}, false
+	for _, p := range priors {
+		if !strings.EqualFold(p.Make, make) || !strings.HasPrefix(model, strings.ToLower(p.Model)) {
+			continue
+		}
+		if !found || len(p.Model) > len(best.Model) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// Hint describes the prior in a sentence for an LLM prompt
+func (p Prior) Hint() string {
+	vehicle := p.Make
+	if p.Model != "" {
+		vehicle += " " + p.Model
+	}
+
+	var facts []string
+	if len(p.Chemistries) > 0 {
+		facts = append(facts, "typically use "+strings.Join(p.Chemistries, " or ")+" batteries")
+	}
+	switch {
+	case p.MinCapacity > 0 && p.MaxCapacity > 0:
+		facts = append(facts, fmt.Sprintf("have %g-%g kWh packs", p.MinCapacity, p.MaxCapacity))
+	case p.Capacity > 0:
+		facts = append(facts, fmt.Sprintf("have packs of around %g kWh", p.Capacity))
+	}
+	if len(facts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("For reference, %s vehicles %s.", vehicle, strings.Join(facts, " and "))
+}
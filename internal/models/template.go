@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TextFields are the fields available to query and document text templates,
+// e.g. {{.Make}} {{.Model}} {{.Year}}. The query template only sees Make,
+// Model and Year; BodyStyle and Notes are empty there.
+type TextFields struct {
+	Make      string
+	Model     string
+	Year      int
+	BodyStyle string
+	Notes     string
+}
+
+// sampleTextFields is rendered once when a template is loaded
+var sampleTextFields = TextFields{Make: "Tesla", Model: "Model 3", Year: 2023, BodyStyle: "sedan", Notes: "RWD"}
+
+// parseTextTemplate parses an embedding text template and renders it once
+// with sample fields, so a template that fails at execution time, e.g. by
+// naming an unknown field, or renders only whitespace is refused at load
+// rather than on the first embedding
+func parseTextTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, sampleTextFields); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sb.String()) == "" {
+		return nil, fmt.Errorf("renders an empty text")
+	}
+	return tmpl, nil
+}
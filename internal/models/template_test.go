@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestParseTextTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		ok   bool
+	}{
+		{"default fields", "{{.Make}} {{.Model}} {{.Year}}", true},
+		{"document fields", "{{.Year}} {{.Make}} {{.Model}}{{if .BodyStyle}} ({{.BodyStyle}}){{end}}\n{{.Notes}}", true},
+		{"parse error", "{{.Make", false},
+		{"unknown field", "{{.Make}} {{.Trim}}", false},
+		{"empty text", "{{if false}}{{.Make}}{{end}}  ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTextTemplate("document", tt.text)
+			if (err == nil) != tt.ok {
+				t.Errorf("parseTextTemplate(%q) error = %v, want ok = %v", tt.text, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewConfigRejectsBrokenDocumentTemplate(t *testing.T) {
+	t.Setenv("EV_ORACLE_NO_DOTENV", "1")
+	t.Setenv("NEON_DATABASE_URL", "postgres://env/db")
+	t.Setenv("EMBEDDING_DOCUMENT_TEMPLATE", "{{.Make}} {{.Modle}}")
+
+	if _, err := NewConfig(WithDatabaseOnly()); err == nil {
+		t.Fatal("NewConfig accepted a document template naming an unknown field")
+	}
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest make or model accepted, matching the
+// VARCHAR(100) make and model columns
+const MaxNameLength = 100
+
+// MaxSourceLength is the longest source accepted, matching the VARCHAR(20)
+// source column
+const MaxSourceLength = 20
+
+// MinModelYear is the earliest model year accepted in a query
+const MinModelYear = 1900
+
+// MaxModelYearAhead is how many years past the current one a query may ask
+// for, since manufacturers announce model years early
+const MaxModelYearAhead = 2
+
+// Plausible ranges for passenger EV specs, shared by add validation and LLM
+// response parsing
+const (
+	MaxCapacityKWh = 300.0  // Largest plausible battery capacity in kWh
+	MaxPowerKW     = 1500.0 // Largest plausible power rating in kW
+)
+
+// ErrInvalidInput is returned when a query is rejected before any lookup
+var ErrInvalidInput = errors.New("invalid input")
+
+// ValidateVehicle checks that make and model are non-blank and fit the
+// database columns, so malformed queries fail before any embedding or LLM call
+func ValidateVehicle(make, model string) error {
+	if err := validateName("make", make); err != nil {
+		return err
+	}
+	return validateName("model", model)
+}
+
+// ValidateQuery checks make, model and year together, as ValidateVehicle and
+// ValidateYear do. It is meant for untrusted input such as server query
+// parameters, so oversized or malformed values never reach an embedding or
+// LLM call.
+func ValidateQuery(make, model string, year int) error {
+	if err := ValidateVehicle(make, model); err != nil {
+		return err
+	}
+	return ValidateYear(year)
+}
+
+// ValidateYear checks that year is between MinModelYear and
+// MaxModelYearAhead years past the current year
+func ValidateYear(year int) error {
+	latest := time.Now().Year() + MaxModelYearAhead
+	if year < MinModelYear || year > latest {
+		return fmt.Errorf("%w: year must be between %d and %d, got %d", ErrInvalidInput, MinModelYear, latest, year)
+	}
+	return nil
+}
+
+// ValidateFilter checks an optional filter value such as a make or chemistry:
+// an empty value is accepted, anything else must pass the make and model
+// checks
+func ValidateFilter(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	return validateName(field, value)
+}
+
+// validateName checks a single make or model value
+func validateName(field, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%w: %s must not be empty", ErrInvalidInput, field)
+	}
+	if utf8.RuneCountInString(value) > MaxNameLength {
+		return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidInput, field, MaxNameLength)
+	}
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%w: %s must be valid UTF-8", ErrInvalidInput, field)
+	}
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: %s must not contain control characters", ErrInvalidInput, field)
+	}
+	return nil
+}
+
+// FieldError describes one invalid spec field
+type FieldError struct {
+	Field   string // "capacity", "power", "chemistry", "source" or "confidence"
+	Message string
+}
+
+// ValidationError lists every invalid field of a spec
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidInput, strings.Join(parts, "; "))
+}
+
+// Is reports whether target is ErrInvalidInput
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// CapacityInRange reports whether kwh is a plausible battery capacity
+func CapacityInRange(kwh float64) bool {
+	return kwh > 0 && kwh <= MaxCapacityKWh
+}
+
+// PowerInRange reports whether kw is a plausible power rating
+func PowerInRange(kw float64) bool {
+	return kw > 0 && kw <= MaxPowerKW
+}
+
+// ValidateSpec checks capacity, power, chemistry, source and confidence,
+// returning a *ValidationError that lists every invalid field rather than
+// just the first. A zero confidence means "not supplied" and is accepted.
+func ValidateSpec(spec *EVSpec) error {
+	return validateSpec(spec, false)
+}
+
+// ValidatePartialSpec is ValidateSpec for specs that may lack capacity, power
+// or chemistry, such as exported rows stored with NULL there: a zero capacity
+// or power and an empty chemistry are accepted as missing.
+func ValidatePartialSpec(spec *EVSpec) error {
+	return validateSpec(spec, true)
+}
+
+func validateSpec(spec *EVSpec, partial bool) error {
+	var fields []FieldError
+	if !CapacityInRange(spec.Capacity) && !(partial && spec.Capacity == 0) {
+		fields = append(fields, FieldError{"capacity", fmt.Sprintf("must be greater than 0 and at most %g kWh, got %g", MaxCapacityKWh, spec.Capacity)})
+	}
+	if !PowerInRange(spec.Power) && !(partial && spec.Power == 0) {
+		fields = append(fields, FieldError{"power", fmt.Sprintf("must be greater than 0 and at most %g kW, got %g", MaxPowerKW, spec.Power)})
+	}
+	if strings.TrimSpace(spec.Chemistry) == "" && !partial {
+		fields = append(fields, FieldError{"chemistry", "must not be empty"})
+	}
+	if utf8.RuneCountInString(spec.Source) > MaxSourceLength {
+		fields = append(fields, FieldError{"source", fmt.Sprintf("must be at most %d characters", MaxSourceLength)})
+	}
+	if spec.Confidence < 0 || spec.Confidence > 1 {
+		fields = append(fields, FieldError{"confidence", fmt.Sprintf("must be between 0 and 1, got %g", spec.Confidence)})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
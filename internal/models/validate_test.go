@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateVehicle(t *testing.T) {
+	long := strings.Repeat("x", MaxNameLength+1)
+	tests := []struct {
+		name        string
+		make, model string
+		wantErr     string
+	}{
+		{"valid", "Tesla", "Model 3", ""},
+		{"empty make", "", "Model 3", "make must not be empty"},
+		{"empty model", "Tesla", "", "model must not be empty"},
+		{"whitespace make", "   ", "Model 3", "make must not be empty"},
+		{"whitespace model", "Tesla", "\t\n ", "model must not be empty"},
+		{"overlong make", long, "Model 3", "make must be at most 100 characters"},
+		{"overlong model", "Tesla", long, "model must be at most 100 characters"},
+		{"longest make", strings.Repeat("x", MaxNameLength), "Model 3", ""},
+		// Length is counted in characters, not bytes
+		{"longest multibyte model", "Škoda", strings.Repeat("é", MaxNameLength), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVehicle(tt.make, tt.model)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateVehicle(%q, %q) = %v, want nil", tt.make, tt.model, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateVehicle(%q, %q) = %v, want %q", tt.make, tt.model, err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ValidateVehicle error %v does not wrap ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestValidateQueryChecksNamesBeforeYear(t *testing.T) {
+	err := ValidateQuery(" ", "Model 3", 0)
+	if err == nil || !strings.Contains(err.Error(), "make must not be empty") {
+		t.Errorf("ValidateQuery = %v, want the empty make reported", err)
+	}
+	if err := ValidateQuery("Tesla", "Model 3", 2023); err != nil {
+		t.Errorf("ValidateQuery(Tesla, Model 3, 2023) = %v, want nil", err)
+	}
+}
+
+func TestValidateVehicleRejectsMalformedText(t *testing.T) {
+	tests := []struct {
+		name        string
+		make, model string
+		wantErr     string
+	}{
+		{"null byte", "Tesla\x00", "Model 3", "make must not contain control characters"},
+		{"newline", "Tesla", "Model\n3", "model must not contain control characters"},
+		{"escape sequence", "Tesla", "\x1b[31mModel 3", "model must not contain control characters"},
+		{"delete", "Tesla\x7f", "Model 3", "make must not contain control characters"},
+		{"C1 control", "Tesla", "Model\u00853", "model must not contain control characters"},
+		{"invalid UTF-8", "Tesla", "Model \xff", "model must be valid UTF-8"},
+		{"truncated UTF-8", "\xc3", "Model 3", "make must be valid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVehicle(tt.make, tt.model)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateVehicle(%q, %q) = %v, want %q", tt.make, tt.model, err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ValidateVehicle error %v does not wrap ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestValidateYear(t *testing.T) {
+	latest := time.Now().Year() + MaxModelYearAhead
+	for _, year := range []int{MinModelYear, 2023, latest} {
+		if err := ValidateYear(year); err != nil {
+			t.Errorf("ValidateYear(%d) = %v, want nil", year, err)
+		}
+	}
+	for _, year := range []int{0, -2023, MinModelYear - 1, latest + 1, 1 << 40} {
+		err := ValidateYear(year)
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "year must be between") {
+			t.Errorf("ValidateYear(%d) = %v, want a year range error", year, err)
+		}
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	if err := ValidateFilter("make", ""); err != nil {
+		t.Errorf("ValidateFilter with an empty value = %v, want nil", err)
+	}
+	if err := ValidateFilter("chemistry", "LFP"); err != nil {
+		t.Errorf("ValidateFilter(chemistry, LFP) = %v, want nil", err)
+	}
+	for _, value := range []string{" ", strings.Repeat("x", MaxNameLength+1), "LFP\r\n", "\xfe"} {
+		err := ValidateFilter("chemistry", value)
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "chemistry") {
+			t.Errorf("ValidateFilter(chemistry, %q) = %v, want an error naming chemistry", value, err)
+		}
+	}
+}
+
+func TestValidatePartialSpec(t *testing.T) {
+	missing := &EVSpec{Make: "Nissan", Model: "Leaf", Year: 2019, Source: "import"}
+	if err := ValidatePartialSpec(missing); err != nil {
+		t.Errorf("ValidatePartialSpec without capacity, power and chemistry = %v, want nil", err)
+	}
+	if err := ValidateSpec(missing); err == nil {
+		t.Error("ValidateSpec accepted a spec without capacity, power and chemistry")
+	}
+
+	for _, spec := range []*EVSpec{
+		{Capacity: -1},
+		{Capacity: MaxCapacityKWh + 1},
+		{Power: -5},
+		{Confidence: 1.5},
+	} {
+		err := ValidatePartialSpec(spec)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ValidatePartialSpec(%+v) = %v, want an invalid input error", spec, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package ollama holds helpers shared by the Ollama embedding and LLM clients
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ModelNotFoundError is returned when Ollama does not have the requested model
+// pulled
+type ModelNotFoundError struct {
+	Model string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	return fmt.Sprintf("model '%s' not found on Ollama; run `ollama pull %s`", e.Model, e.Model)
+}
+
+// CheckModel returns a *ModelNotFoundError if an Ollama error response reports
+// that model is not available, and nil for any other error response. Ollama
+// answers 404 with a body such as {"error":"model \"gemma3\" not found, try
+// pulling it first"}.
+func CheckModel(status int, body []byte, model string) error {
+	if status == http.StatusNotFound && strings.Contains(string(body), "not found") {
+		return &ModelNotFoundError{Model: model}
+	}
+	return nil
+}
+
+// pullRequest represents the request to Ollama's pull API
+type pullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+// pullResponse represents the final response from Ollama's pull API
+type pullResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// Pull downloads model to the Ollama server at baseURL and blocks until the
+// pull has finished, which can take minutes for large models
+func Pull(ctx context.Context, client *http.Client, baseURL, model, userAgent string) error {
+	jsonData, err := json.Marshal(pullRequest{Model: model, Stream: false})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/pull", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull model '%s': %w", model, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to pull model '%s' (status %d): %s", model, resp.StatusCode, string(body))
+	}
+
+	var pullResp pullResponse
+	if err := json.Unmarshal(body, &pullResp); err != nil {
+		return fmt.Errorf("failed to decode pull response: %w", err)
+	}
+	if pullResp.Error != "" {
+		return fmt.Errorf("failed to pull model '%s': %s", model, pullResp.Error)
+	}
+	if pullResp.Status != "success" {
+		return fmt.Errorf("failed to pull model '%s': unexpected status %q", model, pullResp.Status)
+	}
+	return nil
+}
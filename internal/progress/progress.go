@@ -0,0 +1,108 @@
+// Package progress draws a single-line terminal progress bar with a rate and
+// an estimated time remaining for long batch commands.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	barWidth = 30
+	// redrawInterval limits how often Add redraws, so fast loops don't
+	// spend their time writing to the terminal
+	redrawInterval = 100 * time.Millisecond
+)
+
+// Bar is a progress bar redrawn in place on a terminal. A hidden bar draws
+// nothing, but messages written to it still reach the underlying writer, so
+// callers can report errors through a Bar without checking whether it is shown.
+type Bar struct {
+	w      io.Writer
+	label  string
+	total  int
+	hidden bool
+
+	mu    sync.Mutex
+	done  int
+	start time.Time
+	drawn time.Time // last redraw, zero before the first
+}
+
+// New returns a bar for total items drawn on w, which should be a terminal
+func New(w io.Writer, label string, total int) *Bar {
+	return &Bar{w: w, label: label, total: total, start: time.Now()}
+}
+
+// Hidden returns a bar that never draws and passes messages through to w
+func Hidden(w io.Writer) *Bar {
+	return &Bar{w: w, hidden: true}
+}
+
+// Add records n more finished items and redraws the bar at most every 100ms
+func (b *Bar) Add(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.done += n
+	if b.hidden || time.Since(b.drawn) < redrawInterval {
+		return
+	}
+	b.draw()
+}
+
+// Finish draws the final state and ends the line. Nothing is written if no
+// item was ever added.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.hidden || b.drawn.IsZero() {
+		return
+	}
+	b.draw()
+	fmt.Fprintln(b.w)
+	b.hidden = true
+}
+
+// Write clears the bar, writes p on its own line and redraws the bar below it
+func (b *Bar) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.hidden || b.drawn.IsZero() {
+		return b.w.Write(p)
+	}
+	fmt.Fprint(b.w, "\r\033[K")
+	n, err := b.w.Write(p)
+	b.draw()
+	return n, err
+}
+
+// draw renders the bar over the current line. Callers hold b.mu.
+func (b *Bar) draw() {
+	b.drawn = time.Now()
+
+	fraction := 1.0
+	if b.total > 0 {
+		fraction = min(float64(b.done)/float64(b.total), 1)
+	}
+	filled := int(fraction * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+
+	elapsed := b.drawn.Sub(b.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(b.done) / elapsed.Seconds()
+	}
+	eta := "--"
+	if rate > 0 && b.done < b.total {
+		remaining := time.Duration(float64(b.total-b.done) / rate * float64(time.Second))
+		eta = remaining.Round(time.Second).String()
+	}
+
+	fmt.Fprintf(b.w, "\r\033[K%s [%s] %3.0f%% %d/%d  %.1f/s  ETA %s", b.label, bar, fraction*100, b.done, b.total, rate, eta)
+}
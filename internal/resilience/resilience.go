@@ -0,0 +1,288 @@
+// Package resilience provides an http.RoundTripper that retries transient
+// provider failures with exponential backoff, bounds each attempt with a
+// timeout, and stops calling a failing host with a circuit breaker.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBackoff caps a single retry delay when Policy.MaxBackoff is 0
+const DefaultMaxBackoff = 10 * time.Second
+
+// ErrCircuitOpen is returned without sending the request while a host's
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Policy configures a Transport. The zero Policy sends every request once,
+// like the wrapped transport.
+type Policy struct {
+	// MaxRetries is how often a request failing with 429, 5xx or a network
+	// error is retried after the first attempt
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for each further
+	// one. A Retry-After header on a 429 or 503 response takes precedence.
+	Backoff time.Duration
+	// MaxBackoff caps a single delay, including Retry-After; 0 means
+	// DefaultMaxBackoff
+	MaxBackoff time.Duration
+	// Timeout bounds each attempt, including reading the response body; 0
+	// for none
+	Timeout time.Duration
+	// BreakerThreshold is the number of consecutive failed attempts to one
+	// host that opens its breaker; 0 disables the breaker
+	BreakerThreshold int
+	// BreakerCooldown is how long an open breaker refuses requests. Requests
+	// are let through again after it, and a failure reopens the breaker.
+	BreakerCooldown time.Duration
+}
+
+// Enabled reports whether the policy changes anything about a request
+func (p Policy) Enabled() bool {
+	return p.MaxRetries > 0 || p.Timeout > 0 || p.BreakerThreshold > 0
+}
+
+// Client returns a copy of client whose transport applies the policy, or
+// client itself if the policy is not enabled
+func (p Policy) Client(client *http.Client) *http.Client {
+	if !p.Enabled() {
+		return client
+	}
+	wrapped := *client
+	wrapped.Transport = NewTransport(client.Transport, p)
+	return &wrapped
+}
+
+// Transport applies a Policy to the requests sent through Base
+type Transport struct {
+	Base   http.RoundTripper // Underlying transport; http.DefaultTransport if nil
+	Policy Policy
+
+	mu       sync.Mutex
+	breakers map[string]*breaker // by request host
+
+	// sleep and now are replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+// NewTransport creates a Transport wrapping base
+func NewTransport(base http.RoundTripper, policy Policy) *Transport {
+	return &Transport{Base: base, Policy: policy}
+}
+
+// RoundTrip implements http.RoundTripper. A request whose body cannot be
+// replayed, i.e. without GetBody, is sent only once.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retries := t.Policy.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+	b := t.breaker(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if !b.allow(t.clock()) {
+			return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, req.URL.Host)
+		}
+
+		resp, err := t.try(base, req, attempt)
+		if req.Context().Err() != nil {
+			// The caller gave up, which says nothing about the host
+			return resp, err
+		}
+		if !t.transient(resp, err) {
+			b.succeed()
+			return resp, err
+		}
+		b.fail(t.clock(), t.Policy)
+		if attempt >= retries {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// try sends one attempt of req, bounded by the policy's timeout
+func (t *Transport) try(base http.RoundTripper, req *http.Request, attempt int) (*http.Response, error) {
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if t.Policy.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Policy.Timeout)
+	}
+
+	out := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		out.Body = body
+	}
+
+	resp, err := base.RoundTrip(out)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt's deadline also covers reading the body
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// transient reports whether an attempt failed in a way worth retrying: a
+// network error or attempt timeout, 429 Too Many Requests, or a 5xx other
+// than 501 Not Implemented
+func (t *Transport) transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before retrying after attempt
+func (t *Transport) delay(attempt int, resp *http.Response) time.Duration {
+	maxBackoff := t.Policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if after, ok := retryAfter(resp, t.clock()); ok {
+		return min(after, maxBackoff)
+	}
+	d := t.Policy.Backoff
+	for range attempt {
+		if d >= maxBackoff {
+			break
+		}
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// retryAfter parses the Retry-After header of a throttled response, given in
+// seconds or as an HTTP date
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// wait sleeps for d unless ctx ends first
+func (t *Transport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// clock returns the current time
+func (t *Transport) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// breaker returns the circuit breaker of host
+func (t *Transport) breaker(host string) *breaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.breakers == nil {
+		t.breakers = make(map[string]*breaker)
+	}
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &breaker{}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+// breaker counts consecutive failed attempts to one host
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether a request may be sent
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// succeed closes the breaker
+func (b *breaker) succeed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// fail records a failed attempt, opening the breaker at the threshold
+func (b *breaker) fail(now time.Time, p Policy) {
+	if p.BreakerThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= p.BreakerThreshold {
+		b.openUntil = now.Add(p.BreakerCooldown)
+	}
+}
+
+// cancelBody releases an attempt's timeout once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,266 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/resilience/resiliencetest"
+)
+
+// fakeClock records the delays a Transport waits for instead of sleeping,
+// advancing its time by each of them
+type fakeClock struct {
+	now    time.Time
+	delays []time.Duration
+}
+
+func (c *fakeClock) install(t *Transport) *Transport {
+	t.now = func() time.Time { return c.now }
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		c.delays = append(c.delays, d)
+		c.now = c.now.Add(d)
+		return nil
+	}
+	return t
+}
+
+// post sends a request with a body through transport and returns the status
+// and body of the response
+func post(t *testing.T, transport http.RoundTripper, url string) (int, string, error) {
+	t.Helper()
+	client := &http.Client{Transport: transport}
+	resp, err := client.Post(url, "application/json", strings.NewReader(`{"input": "text"}`))
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok",
+		resiliencetest.Status(http.StatusTooManyRequests),
+		resiliencetest.Status(http.StatusInternalServerError),
+		resiliencetest.Status(http.StatusBadGateway),
+		resiliencetest.OK())
+	clock := &fakeClock{}
+	transport := clock.install(NewTransport(nil, Policy{MaxRetries: 3, Backoff: 100 * time.Millisecond}))
+
+	status, body, err := post(t, transport, server.URL)
+	if err != nil || status != http.StatusOK || body != "ok" {
+		t.Fatalf("post = %d %q %v, want 200 ok", status, body, err)
+	}
+	if got := server.Requests(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	if !slices.Equal(clock.delays, want) {
+		t.Errorf("backoff delays = %v, want %v", clock.delays, want)
+	}
+}
+
+func TestGivesUpAfterMaxRetries(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok", resiliencetest.Status(http.StatusServiceUnavailable))
+	clock := &fakeClock{}
+	transport := clock.install(NewTransport(nil, Policy{MaxRetries: 2, Backoff: time.Second}))
+
+	status, body, err := post(t, transport, server.URL)
+	if err != nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("post = %d %q %v, want the last 503", status, body, err)
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("requests = %d, want 3 (one attempt and two retries)", got)
+	}
+}
+
+func TestDoesNotRetryPermanentFailures(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusNotImplemented} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			server := resiliencetest.NewServer(t, "ok", resiliencetest.Status(code))
+			transport := (&fakeClock{}).install(NewTransport(nil, Policy{MaxRetries: 3}))
+
+			if status, _, err := post(t, transport, server.URL); err != nil || status != code {
+				t.Fatalf("post = %d %v, want %d", status, err, code)
+			}
+			if got := server.Requests(); got != 1 {
+				t.Errorf("requests = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHonorsRetryAfter(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok",
+		resiliencetest.Throttled("7"),
+		resiliencetest.Throttled("120"),
+		resiliencetest.OK())
+	clock := &fakeClock{}
+	transport := clock.install(NewTransport(nil, Policy{MaxRetries: 2, Backoff: 100 * time.Millisecond, MaxBackoff: time.Minute}))
+
+	if status, _, err := post(t, transport, server.URL); err != nil || status != http.StatusOK {
+		t.Fatalf("post = %d %v, want 200", status, err)
+	}
+	// The second Retry-After is capped by MaxBackoff
+	want := []time.Duration{7 * time.Second, time.Minute}
+	if !slices.Equal(clock.delays, want) {
+		t.Errorf("delays = %v, want %v", clock.delays, want)
+	}
+}
+
+func TestRetryAfterDate(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	resp.Header.Set("Retry-After", now.Add(90*time.Second).Format(http.TimeFormat))
+
+	if d, ok := retryAfter(resp, now); !ok || d != 90*time.Second {
+		t.Errorf("retryAfter = %v, %v, want 90s", d, ok)
+	}
+	resp.StatusCode = http.StatusInternalServerError
+	if _, ok := retryAfter(resp, now); ok {
+		t.Error("Retry-After was used on a 500 response")
+	}
+}
+
+func TestReplaysRequestBody(t *testing.T) {
+	var bodies []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		status := http.StatusInternalServerError
+		if len(bodies) == 2 {
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+	})
+	transport := (&fakeClock{}).install(NewTransport(base, Policy{MaxRetries: 1}))
+
+	if status, _, err := post(t, transport, "http://provider.test/embed"); err != nil || status != http.StatusOK {
+		t.Fatalf("post = %d %v, want 200", status, err)
+	}
+	want := []string{`{"input": "text"}`, `{"input": "text"}`}
+	if !slices.Equal(bodies, want) {
+		t.Errorf("bodies sent = %q, want %q", bodies, want)
+	}
+}
+
+func TestTimeoutBoundsEachAttempt(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok", resiliencetest.Slow(time.Minute), resiliencetest.OK())
+	transport := (&fakeClock{}).install(NewTransport(nil, Policy{MaxRetries: 1, Timeout: 50 * time.Millisecond}))
+
+	start := time.Now()
+	status, body, err := post(t, transport, server.URL)
+	if err != nil || status != http.StatusOK || body != "ok" {
+		t.Fatalf("post = %d %q %v, want the retry's 200 ok", status, body, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("post took %v, want the slow attempt cut off after 50ms", elapsed)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestTimeoutWithoutRetries(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok", resiliencetest.Slow(time.Minute))
+	transport := NewTransport(nil, Policy{Timeout: 50 * time.Millisecond})
+
+	_, _, err := post(t, transport, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("post error = %v, want a deadline exceeded error", err)
+	}
+}
+
+func TestCallerCancellationIsNotRetried(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok", resiliencetest.Slow(time.Minute))
+	transport := NewTransport(nil, Policy{MaxRetries: 3})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if _, err := (&http.Client{Transport: transport}).Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do error = %v, want the caller's deadline", err)
+	}
+	if got := server.Requests(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok",
+		resiliencetest.Status(http.StatusInternalServerError),
+		resiliencetest.Status(http.StatusInternalServerError),
+		resiliencetest.Status(http.StatusInternalServerError),
+		resiliencetest.OK())
+	clock := &fakeClock{now: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	transport := clock.install(NewTransport(nil, Policy{BreakerThreshold: 3, BreakerCooldown: time.Minute}))
+
+	for range 3 {
+		if status, _, err := post(t, transport, server.URL); err != nil || status != http.StatusInternalServerError {
+			t.Fatalf("post = %d %v, want 500", status, err)
+		}
+	}
+	if _, _, err := post(t, transport, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("post error = %v, want ErrCircuitOpen", err)
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("requests = %d, want 3 (none while the breaker is open)", got)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if status, _, err := post(t, transport, server.URL); err != nil || status != http.StatusOK {
+		t.Fatalf("post after the cooldown = %d %v, want 200", status, err)
+	}
+}
+
+func TestBreakerStopsRetries(t *testing.T) {
+	server := resiliencetest.NewServer(t, "ok", resiliencetest.Status(http.StatusBadGateway))
+	transport := (&fakeClock{}).install(NewTransport(nil, Policy{MaxRetries: 5, BreakerThreshold: 2, BreakerCooldown: time.Minute}))
+
+	if _, _, err := post(t, transport, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("post error = %v, want ErrCircuitOpen once the threshold is reached", err)
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestBreakerIsPerHost(t *testing.T) {
+	failing := resiliencetest.NewServer(t, "ok", resiliencetest.Status(http.StatusInternalServerError))
+	healthy := resiliencetest.NewServer(t, "ok")
+	transport := (&fakeClock{}).install(NewTransport(nil, Policy{BreakerThreshold: 1, BreakerCooldown: time.Minute}))
+
+	post(t, transport, failing.URL)
+	if _, _, err := post(t, transport, failing.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("post error = %v, want ErrCircuitOpen", err)
+	}
+	if status, _, err := post(t, transport, healthy.URL); err != nil || status != http.StatusOK {
+		t.Errorf("post to another host = %d %v, want 200", status, err)
+	}
+}
+
+func TestDisabledPolicyKeepsClient(t *testing.T) {
+	client := &http.Client{}
+	if got := (Policy{Backoff: time.Second}).Client(client); got != client {
+		t.Error("a policy without retries, timeout or breaker wrapped the client")
+	}
+	if got := (Policy{MaxRetries: 1}).Client(client); got == client || client.Transport != nil {
+		t.Error("Client did not return a wrapped copy, leaving the original untouched")
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,111 @@
+// Package resiliencetest provides a scripted flaky HTTP server for testing
+// how provider clients handle throttling, server errors and slow responses.
+package resiliencetest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Step is one scripted response
+type Step struct {
+	Status     int           // HTTP status; 0 means 200
+	RetryAfter string        // Retry-After header, if set
+	Delay      time.Duration // wait before answering, cut short if the client gives up
+	Body       string        // response body; empty for the server's success body
+}
+
+// Status answers with an HTTP status and its status text as the body
+func Status(code int) Step {
+	return Step{Status: code, Body: http.StatusText(code)}
+}
+
+// Throttled answers 429 Too Many Requests with a Retry-After header
+func Throttled(retryAfter string) Step {
+	return Step{Status: http.StatusTooManyRequests, RetryAfter: retryAfter, Body: "rate limited"}
+}
+
+// Slow answers with the success body after d
+func Slow(d time.Duration) Step {
+	return Step{Delay: d}
+}
+
+// OK answers with the success body
+func OK() Step {
+	return Step{}
+}
+
+// Server answers each request with the next step of its script, repeating
+// the last step once the script runs out
+type Server struct {
+	*httptest.Server
+
+	success string
+	mu      sync.Mutex
+	steps   []Step
+	times   []time.Time
+}
+
+// NewServer starts a server answering successful steps with success, e.g. a
+// provider's JSON response. It is closed when the test ends.
+func NewServer(t testing.TB, success string, steps ...Step) *Server {
+	t.Helper()
+	if len(steps) == 0 {
+		steps = []Step{OK()}
+	}
+	s := &Server{success: success, steps: steps}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	// Reading the body lets the server notice a client hanging up
+	io.Copy(io.Discard, r.Body)
+
+	s.mu.Lock()
+	step := s.steps[min(len(s.times), len(s.steps)-1)]
+	s.times = append(s.times, time.Now())
+	s.mu.Unlock()
+
+	if step.Delay > 0 {
+		select {
+		case <-time.After(step.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if step.RetryAfter != "" {
+		w.Header().Set("Retry-After", step.RetryAfter)
+	}
+	body := step.Body
+	if body == "" {
+		body = s.success
+	}
+	if step.Status != 0 {
+		w.WriteHeader(step.Status)
+	}
+	io.WriteString(w, body)
+}
+
+// Requests returns the number of requests received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.times)
+}
+
+// Gaps returns the time between consecutive requests
+func (s *Server) Gaps() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var gaps []time.Duration
+	for i := 1; i < len(s.times); i++ {
+		gaps = append(gaps, s.times[i].Sub(s.times[i-1]))
+	}
+	return gaps
+}
@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// AggregateMethod combines the numeric fields of several matches into one
+// value
+type AggregateMethod string
+
+const (
+	AggregateMean   AggregateMethod = "mean"
+	AggregateMedian AggregateMethod = "median"
+	AggregateMax    AggregateMethod = "max"
+)
+
+// SourceAggregate is the source of a spec synthesized by Aggregate
+const SourceAggregate = "aggregate"
+
+// ParseAggregateMethod converts "mean", "median" or "max" into an
+// AggregateMethod
+func ParseAggregateMethod(name string) (AggregateMethod, error) {
+	switch m := AggregateMethod(strings.ToLower(name)); m {
+	case AggregateMean, AggregateMedian, AggregateMax:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid aggregate method: %s (use mean, median or max)", name)
+	}
+}
+
+// Aggregate synthesizes one spec for q from several matches, such as the trims
+// of a model. Only matches of q's make and model are combined: other years
+// and trims named after the model ("Model 3 Long Range" for "Model 3") count,
+// other models are left out, and an error is returned if no match remains.
+// Capacity, power and confidence are combined with method over the matches
+// that have a value, and chemistry is the most common one, with ties going to
+// the earlier match. The result has source "aggregate" and notes listing the
+// matches it came from.
+func Aggregate(q Query, matches []models.EVSpec, method AggregateMethod) (*models.EVSpec, error) {
+	matches = slices.DeleteFunc(slices.Clone(matches), func(m models.EVSpec) bool {
+		return !sameModel(q, m)
+	})
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no matches of %s %s to aggregate", q.Make, q.Model)
+	}
+
+	var capacities, powers, confidences []float64
+	var chemistries []string
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		if m.Capacity > 0 {
+			capacities = append(capacities, m.Capacity)
+		}
+		if m.Power > 0 {
+			powers = append(powers, m.Power)
+		}
+		if m.Chemistry != "" {
+			chemistries = append(chemistries, m.Chemistry)
+		}
+		confidences = append(confidences, m.Confidence)
+		names[i] = fmt.Sprintf("%d %s %s", m.Year, m.Make, m.Model)
+	}
+
+	return &models.EVSpec{
+		Make:       q.Make,
+		Model:      q.Model,
+		Year:       q.Year,
+		Capacity:   method.combine(capacities),
+		Power:      method.combine(powers),
+		Chemistry:  mostCommon(chemistries),
+		Confidence: method.combine(confidences),
+		Source:     SourceAggregate,
+		Notes:      fmt.Sprintf("%s of %d matches: %s", method, len(matches), strings.Join(names, "; ")),
+	}, nil
+}
+
+// sameModel reports whether m is the make and model of q, or a trim of it
+func sameModel(q Query, m models.EVSpec) bool {
+	if !strings.EqualFold(m.Make, q.Make) {
+		return false
+	}
+	model, want := strings.ToLower(m.Model), strings.ToLower(q.Model)
+	return model == want || strings.HasPrefix(model, want+" ")
+}
+
+// combine reduces values with the method, 0 for no values
+func (m AggregateMethod) combine(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	switch m {
+	case AggregateMax:
+		return slices.Max(values)
+	case AggregateMedian:
+		sorted := slices.Clone(values)
+		slices.Sort(sorted)
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2
+		}
+		return sorted[mid]
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	}
+}
+
+// mostCommon returns the most frequent value, case-insensitively, in the
+// spelling of its first occurrence. Ties go to the earliest value.
+func mostCommon(values []string) string {
+	counts := make(map[string]int)
+	for _, v := range values {
+		counts[strings.ToLower(v)]++
+	}
+
+	best, bestCount := "", 0
+	for _, v := range values {
+		if n := counts[strings.ToLower(v)]; n > bestCount {
+			best, bestCount = v, n
+		}
+	}
+	return best
+}
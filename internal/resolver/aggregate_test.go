@@ -0,0 +1,37 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestAggregateSkipsOtherModels(t *testing.T) {
+	q := Query{Make: "Tesla", Model: "Model 3", Year: 2023}
+	matches := []models.EVSpec{
+		{Make: "Tesla", Model: "Model 3", Year: 2023, Capacity: 60},
+		{Make: "tesla", Model: "Model 3 Long Range", Year: 2022, Capacity: 80},
+		{Make: "Tesla", Model: "Model Y", Year: 2023, Capacity: 200},
+		{Make: "Polestar", Model: "Model 3", Year: 2023, Capacity: 200},
+	}
+
+	spec, err := Aggregate(q, matches, AggregateMean)
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if spec.Capacity != 70 {
+		t.Errorf("capacity = %v, want 70 from the two Model 3 matches", spec.Capacity)
+	}
+	if spec.Make != "Tesla" || spec.Model != "Model 3" || spec.Year != 2023 || spec.Source != SourceAggregate {
+		t.Errorf("spec = %+v, want the queried vehicle with source aggregate", spec)
+	}
+}
+
+func TestAggregateNoMatchingModel(t *testing.T) {
+	q := Query{Make: "Tesla", Model: "Model 3", Year: 2023}
+	matches := []models.EVSpec{{Make: "Tesla", Model: "Model 30", Year: 2023, Capacity: 60}}
+
+	if spec, err := Aggregate(q, matches, AggregateMedian); err == nil {
+		t.Fatalf("Aggregate = %+v, want an error when no match is the queried model", spec)
+	}
+}
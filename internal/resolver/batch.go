@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Query identifies a vehicle to resolve
+type Query struct {
+	Make  string `json:"make"`
+	Model string `json:"model"`
+	Year  int    `json:"year"`
+}
+
+// BatchResult is the outcome of resolving one query of a batch
+type BatchResult struct {
+	Index int            // Position of the query in the input
+	Query Query          // The query that was resolved
+	Spec  *models.EVSpec // Resolved spec, nil if Err is set
+	Err   error          // Resolution error, if any
+}
+
+// ResolveBatch resolves queries with up to concurrency workers and calls emit
+// for every result. When ordered is true, results are emitted in input order;
+// otherwise each is emitted as soon as it completes, so slow LLM-backed rows
+// do not hold up fast database hits. emit is never called concurrently. If
+// emit returns an error, remaining work is cancelled and that error returned.
+func (r *Resolver) ResolveBatch(ctx context.Context, queries []Query, concurrency int, ordered bool, emit func(BatchResult) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan BatchResult)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				q := queries[i]
+				spec, err := r.Resolve(ctx, q.Make, q.Model, q.Year)
+				select {
+				case results <- BatchResult{Index: i, Query: q, Spec: spec, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range queries {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// pending buffers out-of-order results until their turn in ordered mode
+	pending := make(map[int]BatchResult)
+	next := 0
+	for result := range results {
+		if !ordered {
+			if err := emit(result); err != nil {
+				cancel()
+				return err
+			}
+			continue
+		}
+
+		pending[result.Index] = result
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := emit(res); err != nil {
+				cancel()
+				return err
+			}
+		}
+	}
+
+	return ctx.Err()
+}
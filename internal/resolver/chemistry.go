@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// fallbackChemistry is inferred when no rule matches
+const fallbackChemistry = "NMC"
+
+// defaultChemistryRules covers well-known LFP packs. Entries use the
+// "Make[/ModelPrefix]:Chemistry[:MaxKWh]" format described at
+// NewChemistryRules.
+var defaultChemistryRules = []string{
+	"Tesla/Model 3:LFP:62",
+	"Tesla/Model Y:LFP:62",
+	"Ford/Mustang Mach-E:LFP:72",
+	"BYD:LFP",
+}
+
+// ChemistryRule infers a chemistry for matching vehicles
+type ChemistryRule struct {
+	Make        string  // Make, case-insensitive
+	ModelPrefix string  // Optional model prefix, case-insensitive
+	MaxCapacity float64 // Optional upper bound on capacity in kWh; 0 for any capacity
+	Chemistry   string  // Chemistry to infer
+}
+
+// matches reports whether the rule applies to spec
+func (r ChemistryRule) matches(spec *models.EVSpec) bool {
+	if !strings.EqualFold(r.Make, strings.TrimSpace(spec.Make)) {
+		return false
+	}
+	if r.ModelPrefix != "" && !strings.HasPrefix(strings.ToLower(strings.TrimSpace(spec.Model)), strings.ToLower(r.ModelPrefix)) {
+		return false
+	}
+	if r.MaxCapacity > 0 && (spec.Capacity <= 0 || spec.Capacity > r.MaxCapacity) {
+		return false
+	}
+	return true
+}
+
+// ChemistryRules infers missing chemistries; the first matching rule wins
+type ChemistryRules []ChemistryRule
+
+// NewChemistryRules creates rules from the given extra entries followed by the
+// built-in ones, so extra entries take precedence. Each entry has the form
+// "Make[/ModelPrefix]:Chemistry[:MaxKWh]", e.g. "Tesla/Model 3:LFP:62" or
+// "BYD:LFP". Malformed entries are ignored.
+func NewChemistryRules(extra ...string) ChemistryRules {
+	// Cloning keeps append from writing into spare capacity of the caller's slice
+	var rules ChemistryRules
+	for _, entry := range append(slices.Clone(extra), defaultChemistryRules...) {
+		rule, ok := parseChemistryRule(entry)
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseChemistryRule parses one "Make[/ModelPrefix]:Chemistry[:MaxKWh]" entry
+func parseChemistryRule(entry string) (ChemistryRule, bool) {
+	parts := strings.Split(entry, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ChemistryRule{}, false
+	}
+
+	vehicle, modelPrefix, _ := strings.Cut(parts[0], "/")
+	rule := ChemistryRule{
+		Make:        strings.TrimSpace(vehicle),
+		ModelPrefix: strings.TrimSpace(modelPrefix),
+		Chemistry:   strings.TrimSpace(parts[1]),
+	}
+	if rule.Make == "" || rule.Chemistry == "" {
+		return ChemistryRule{}, false
+	}
+
+	if len(parts) == 3 {
+		maxCapacity, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		if err != nil || maxCapacity <= 0 {
+			return ChemistryRule{}, false
+		}
+		rule.MaxCapacity = maxCapacity
+	}
+	return rule, true
+}
+
+// Infer returns the chemistry the rules suggest for spec, falling back to NMC
+func (rules ChemistryRules) Infer(spec *models.EVSpec) string {
+	for _, rule := range rules {
+		if rule.matches(spec) {
+			return rule.Chemistry
+		}
+	}
+	return fallbackChemistry
+}
+
+// Apply fills in a missing chemistry and marks it as inferred. Placeholder
+// answers such as "Unknown" or "N/A" count as missing; specs with any other
+// chemistry are left untouched.
+func (rules ChemistryRules) Apply(spec *models.EVSpec) {
+	if !isMissingChemistry(spec.Chemistry) {
+		return
+	}
+	spec.Chemistry = rules.Infer(spec)
+	spec.ChemistrySource = models.ChemistryInferred
+}
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestChemistryRulesInfer(t *testing.T) {
+	rules := NewChemistryRules("Rivian/R1:LFP:110", "Kia:NCM")
+
+	tests := []struct {
+		name string
+		spec models.EVSpec
+		want string
+	}{
+		{"built-in rule under its capacity bound", models.EVSpec{Make: "Tesla", Model: "Model 3", Capacity: 57.5}, "LFP"},
+		{"built-in rule over its capacity bound", models.EVSpec{Make: "Tesla", Model: "Model 3", Capacity: 82}, "NMC"},
+		{"capacity bound needs a capacity", models.EVSpec{Make: "Tesla", Model: "Model 3"}, "NMC"},
+		{"make-only rule", models.EVSpec{Make: "byd", Model: "Seal", Capacity: 82.5}, "LFP"},
+		{"case-insensitive model prefix", models.EVSpec{Make: "Tesla", Model: "model y rwd", Capacity: 60}, "LFP"},
+		{"extra rule", models.EVSpec{Make: "Rivian", Model: "R1T", Capacity: 105}, "LFP"},
+		{"extra make rule", models.EVSpec{Make: "Kia", Model: "EV6", Capacity: 77.4}, "NCM"},
+		{"no rule", models.EVSpec{Make: "Hyundai", Model: "Ioniq 5", Capacity: 77.4}, "NMC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Infer(&tt.spec); got != tt.want {
+				t.Errorf("Infer(%s %s %.1f kWh) = %q, want %q", tt.spec.Make, tt.spec.Model, tt.spec.Capacity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChemistryRulesExtraTakesPrecedence(t *testing.T) {
+	rules := NewChemistryRules("Tesla:NCA")
+	spec := models.EVSpec{Make: "Tesla", Model: "Model 3", Capacity: 57.5}
+	if got := rules.Infer(&spec); got != "NCA" {
+		t.Errorf("Infer = %q, want the extra rule's NCA over the built-in LFP", got)
+	}
+}
+
+func TestNewChemistryRulesIgnoresMalformedEntries(t *testing.T) {
+	rules := NewChemistryRules("Tesla", ":LFP", "Tesla:", "Tesla:LFP:big", "Tesla:LFP:-1", "a:b:c:d")
+	if len(rules) != len(NewChemistryRules()) {
+		t.Errorf("got %d rules, want only the %d built-in ones", len(rules), len(NewChemistryRules()))
+	}
+}
+
+func TestNewChemistryRulesLeavesCallerSlice(t *testing.T) {
+	extra := make([]string, 1, 8)
+	extra[0] = "Kia:NCM"
+	NewChemistryRules(extra...)
+	if spare := extra[1:cap(extra)]; spare[0] != "" {
+		t.Errorf("NewChemistryRules wrote %q into the caller's spare capacity", spare[0])
+	}
+}
+
+func TestChemistryRulesApply(t *testing.T) {
+	rules := NewChemistryRules()
+
+	missing := models.EVSpec{Make: "BYD", Model: "Atto 3", Chemistry: "Unknown"}
+	rules.Apply(&missing)
+	if missing.Chemistry != "LFP" || missing.ChemistrySource != models.ChemistryInferred {
+		t.Errorf("Apply = %q (source %q), want an inferred LFP", missing.Chemistry, missing.ChemistrySource)
+	}
+
+	known := models.EVSpec{Make: "BYD", Model: "Atto 3", Chemistry: "NMC"}
+	rules.Apply(&known)
+	if known.Chemistry != "NMC" || known.ChemistrySource != "" {
+		t.Errorf("Apply changed a known chemistry to %q (source %q)", known.Chemistry, known.ChemistrySource)
+	}
+}
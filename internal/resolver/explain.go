@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Resolution is the trace of one Resolve call: the stages tried in order,
+// their latencies, and the stage and source that produced the answer
+type Resolution struct {
+	Query      Query        `json:"query"`
+	Stages     []StageTrace `json:"stages"`
+	Outcome    string       `json:"outcome"` // as Event.Outcome for StageResolve
+	Source     string       `json:"source,omitempty"`
+	Confidence float64      `json:"confidence,omitempty"`
+	DurationMS float64      `json:"duration_ms"`
+	Error      string       `json:"error,omitempty"`
+
+	vectorBest float64 // best similarity seen by the vector stage
+}
+
+// StageTrace is one stage of a Resolution
+type StageTrace struct {
+	Stage      Stage   `json:"stage"`
+	DurationMS float64 `json:"duration_ms"`
+	Resolved   bool    `json:"resolved"`
+	// Confidence is the answer's confidence, or for an unresolved vector
+	// stage the best candidate's similarity, which fell below the threshold
+	Confidence float64 `json:"confidence,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// resolutionKey carries the *Resolution being recorded in a context
+type resolutionKey struct{}
+
+// ResolveExplain resolves like Resolve and also returns the resolution
+// trace, which is filled in even when resolving fails
+func (r *Resolver) ResolveExplain(ctx context.Context, make, model string, year int) (*models.EVSpec, *Resolution, error) {
+	trace := &Resolution{Stages: []StageTrace{}}
+	spec, err := r.Resolve(context.WithValue(ctx, resolutionKey{}, trace), make, model, year)
+	return spec, trace, err
+}
+
+// traceOf returns the Resolution being recorded in ctx, or nil
+func traceOf(ctx context.Context) *Resolution {
+	trace, _ := ctx.Value(resolutionKey{}).(*Resolution)
+	return trace
+}
+
+// record adds the finished stage in e to the trace
+func (t *Resolution) record(e *Event) {
+	ms := float64(e.Duration.Microseconds()) / 1000
+	var errText string
+	if e.Err != nil {
+		errText = e.Err.Error()
+	}
+
+	if e.Stage == StageResolve {
+		t.Query, t.Outcome, t.DurationMS, t.Error = e.Query, e.Outcome, ms, errText
+		if e.Spec != nil {
+			t.Source, t.Confidence = e.Spec.Source, e.Spec.Confidence
+		}
+		return
+	}
+
+	stage := StageTrace{Stage: e.Stage, DurationMS: ms, Resolved: e.Spec != nil, Error: errText}
+	switch {
+	case e.Spec != nil:
+		stage.Confidence = e.Spec.Confidence
+	case e.Stage == StageVector:
+		stage.Confidence = t.vectorBest
+	}
+	t.Stages = append(t.Stages, stage)
+}
+
+// noteBest remembers the best similarity candidate of the vector stage, so
+// the trace shows how close a miss came to the threshold
+func (t *Resolution) noteBest(best *models.EVSpec) {
+	if t != nil && best != nil {
+		t.vectorBest = best.Confidence
+	}
+}
+
+// WriteText prints the trace as a few human-readable lines
+func (t *Resolution) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Resolution of %d %s %s:\n", t.Query.Year, t.Query.Make, t.Query.Model)
+	for _, s := range t.Stages {
+		status := "miss"
+		switch {
+		case s.Error != "":
+			status = "error: " + s.Error
+		case s.Resolved:
+			status = fmt.Sprintf("resolved (confidence %.2f)", s.Confidence)
+		case s.Confidence > 0:
+			status = fmt.Sprintf("miss (best confidence %.2f, threshold %.2f)", s.Confidence, models.ConfidenceThreshold)
+		}
+		fmt.Fprintf(w, "  %-7s %8s  %s\n", s.Stage, msDuration(s.DurationMS), status)
+	}
+	if t.Source != "" {
+		fmt.Fprintf(w, "Answered by the %s stage (source %s) in %s\n", t.Outcome, t.Source, msDuration(t.DurationMS))
+	} else {
+		fmt.Fprintf(w, "Outcome %s after %s\n", t.Outcome, msDuration(t.DurationMS))
+	}
+}
+
+// msDuration formats milliseconds as a rounded duration
+func msDuration(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
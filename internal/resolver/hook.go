@@ -0,0 +1,134 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Stage identifies a step of the resolver pipeline
+type Stage string
+
+const (
+	StageResolve Stage = "resolve" // the whole pipeline, around the other stages
+	StageExact   Stage = "exact"   // exact make/model/year database lookup
+	StageVector  Stage = "vector"  // embedding and similarity search
+	StageLLM     Stage = "llm"     // LLM fallback
+)
+
+// Event describes a stage to hooks. Before sees Stage and Query; After also
+// sees the stage's result.
+type Event struct {
+	Stage    Stage
+	Query    Query
+	Spec     *models.EVSpec // the stage's result; nil if it did not resolve the query
+	Err      error
+	Duration time.Duration
+	// Outcome is set for StageResolve: the stage that produced the result, or
+	// "rejected", "error" or "hook" (a hook answered the whole query)
+	Outcome string
+}
+
+// Hook runs around each resolver stage. Before runs in registration order; a
+// non-nil spec skips the stage and uses that spec as its result, e.g. for a
+// cache, and a non-nil error fails the query, e.g. for authorization. The
+// remaining hooks' Before is not called in either case. After runs for every
+// hook, in registration order, once the stage has finished or been skipped.
+type Hook interface {
+	Before(ctx context.Context, e *Event) (*models.EVSpec, error)
+	After(ctx context.Context, e *Event)
+}
+
+// HookFuncs adapts a pair of functions to Hook. Either may be nil.
+type HookFuncs struct {
+	BeforeFunc func(ctx context.Context, e *Event) (*models.EVSpec, error)
+	AfterFunc  func(ctx context.Context, e *Event)
+}
+
+// Before calls BeforeFunc if set
+func (h HookFuncs) Before(ctx context.Context, e *Event) (*models.EVSpec, error) {
+	if h.BeforeFunc == nil {
+		return nil, nil
+	}
+	return h.BeforeFunc(ctx, e)
+}
+
+// After calls AfterFunc if set
+func (h HookFuncs) After(ctx context.Context, e *Event) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(ctx, e)
+	}
+}
+
+// WithHooks appends hooks to the chain run around every stage
+func WithHooks(hooks ...Hook) Option {
+	return func(r *Resolver) {
+		r.hooks = append(r.hooks, hooks...)
+	}
+}
+
+// runStage runs fn as stage, wrapped in the hook chain
+func (r *Resolver) runStage(ctx context.Context, e *Event, fn func() (*models.EVSpec, error)) (*models.EVSpec, error) {
+	start := time.Now()
+
+	var spec *models.EVSpec
+	var err error
+	for _, h := range r.hooks {
+		if spec, err = h.Before(ctx, e); spec != nil || err != nil {
+			break
+		}
+	}
+	if spec == nil && err == nil {
+		spec, err = fn()
+	}
+
+	e.Spec, e.Err, e.Duration = spec, err, time.Since(start)
+	for _, h := range r.hooks {
+		h.After(ctx, e)
+	}
+	if trace := traceOf(ctx); trace != nil {
+		trace.record(e)
+	}
+	return spec, err
+}
+
+// logHook reports the LLM fallback as progress on w
+type logHook struct {
+	w io.Writer
+}
+
+func (h logHook) Before(ctx context.Context, e *Event) (*models.EVSpec, error) {
+	if e.Stage == StageLLM {
+		// Progress goes to stderr so machine-readable stdout (JSON, NDJSON) stays clean
+		fmt.Fprintln(h.w, "Falling back to LLM")
+	}
+	return nil, nil
+}
+
+func (h logHook) After(ctx context.Context, e *Event) {}
+
+// defaultLogHook is registered by New
+var defaultLogHook Hook = logHook{w: os.Stderr}
+
+// metricsHook records how each query was resolved and how long it took
+type metricsHook struct {
+	recorder metrics.Recorder
+}
+
+func (h metricsHook) Before(ctx context.Context, e *Event) (*models.EVSpec, error) {
+	return nil, nil
+}
+
+func (h metricsHook) After(ctx context.Context, e *Event) {
+	if e.Stage != StageResolve {
+		return
+	}
+	labels := map[string]string{"stage": e.Outcome}
+	h.recorder.IncCounter(metrics.ResolveTotal, labels)
+	h.recorder.ObserveHistogram(metrics.ResolveDurationSeconds, e.Duration.Seconds(), labels)
+}
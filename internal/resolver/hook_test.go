@@ -0,0 +1,196 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// recordingHook records the order in which hook methods run
+type recordingHook struct {
+	calls []string
+}
+
+func (h *recordingHook) Before(ctx context.Context, e *Event) (*models.EVSpec, error) {
+	h.calls = append(h.calls, "before "+string(e.Stage))
+	return nil, nil
+}
+
+func (h *recordingHook) After(ctx context.Context, e *Event) {
+	h.calls = append(h.calls, "after "+string(e.Stage))
+}
+
+// hookResolver returns a resolver over a json store holding a 2023 Tesla
+// Model 3, with providers that answer every request and count them in calls
+func hookResolver(t *testing.T, calls *int, opts ...Option) *Resolver {
+	t.Helper()
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		*calls++
+		if r.URL.Path == "/api/embed" {
+			fmt.Fprint(w, `{"embeddings": [[0, 0, 1]]}`)
+			return
+		}
+		fmt.Fprint(w, `{"response": "Capacity: 80 kWh\nPower: 300 kW\nChemistry: NMC"}`)
+	}))
+	t.Cleanup(provider.Close)
+
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	seed := &models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Capacity: 75, Power: 283, Chemistry: "NMC", Source: "manual"}
+	if err := store.InsertEVSpec(context.Background(), seed, []float32{1, 0, 0}); err != nil {
+		t.Fatalf("failed to seed store: %v", err)
+	}
+
+	res := New(store,
+		embedding.NewWithProvider(embedding.ProviderOllama, "", provider.URL, "test"),
+		llm.NewWithProvider(llm.ProviderOllama, "", provider.URL, "test"),
+		opts...)
+	// Keep the LLM fallback notice out of the test output
+	res.hooks[0] = logHook{w: io.Discard}
+	return res
+}
+
+func TestHookRunsAroundEachStage(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		want  []string
+	}{
+		{"exact match", "Model 3", []string{
+			"before resolve", "before exact", "after exact", "after resolve",
+		}},
+		{"llm fallback", "Cybertruck", []string{
+			"before resolve",
+			"before exact", "after exact",
+			"before vector", "after vector",
+			"before llm", "after llm",
+			"after resolve",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &recordingHook{}
+			var calls int
+			res := hookResolver(t, &calls, WithHooks(hook))
+			if _, err := res.Resolve(context.Background(), "Tesla", tt.model, 2023); err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if !slices.Equal(hook.calls, tt.want) {
+				t.Errorf("hook calls = %q, want %q", hook.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookAnswersStage(t *testing.T) {
+	cached := &models.EVSpec{Make: "Tesla", Model: "Cybertruck", Year: 2023, Capacity: 123, Power: 250, Chemistry: "NCA", Source: "cache"}
+	cache := HookFuncs{BeforeFunc: func(ctx context.Context, e *Event) (*models.EVSpec, error) {
+		if e.Stage == StageLLM {
+			return cached, nil
+		}
+		return nil, nil
+	}}
+	var after []*Event
+	observer := HookFuncs{AfterFunc: func(ctx context.Context, e *Event) {
+		if e.Stage == StageLLM {
+			after = append(after, e)
+		}
+	}}
+
+	var calls int
+	res := hookResolver(t, &calls, WithHooks(cache, observer))
+	spec, err := res.Resolve(context.Background(), "Tesla", "Cybertruck", 2023)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if spec.Capacity != 123 || spec.Source != "cache" {
+		t.Errorf("spec = %+v, want the hook's answer", spec)
+	}
+	// Only the embedding for the vector stage is requested
+	if calls != 1 {
+		t.Errorf("providers were called %d times, want 1 (no LLM call)", calls)
+	}
+	if len(after) != 1 || after[0].Spec != cached {
+		t.Errorf("After saw %d LLM events, want one with the hook's answer", len(after))
+	}
+}
+
+func TestHookRefusesQuery(t *testing.T) {
+	errDenied := errors.New("denied")
+	auth := HookFuncs{BeforeFunc: func(ctx context.Context, e *Event) (*models.EVSpec, error) {
+		if e.Stage == StageResolve && e.Query.Make == "Tesla" {
+			return nil, errDenied
+		}
+		return nil, nil
+	}}
+	hook := &recordingHook{}
+
+	var calls int
+	res := hookResolver(t, &calls, WithHooks(auth, hook))
+	if _, err := res.Resolve(context.Background(), "Tesla", "Cybertruck", 2023); !errors.Is(err, errDenied) {
+		t.Errorf("Resolve error = %v, want the hook's error", err)
+	}
+	if calls != 0 {
+		t.Errorf("providers were called %d times for a refused query, want 0", calls)
+	}
+	// Later hooks skip Before once a hook refuses, but every After runs
+	if want := []string{"after resolve"}; !slices.Equal(hook.calls, want) {
+		t.Errorf("hook calls = %q, want %q", hook.calls, want)
+	}
+}
+
+// ExampleWithHooks logs every stage of an exact-match lookup
+func ExampleWithHooks() {
+	dir, err := os.MkdirTemp("", "ev-oracle-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := db.OpenJSONStore(filepath.Join(dir, "specs.json"))
+	if err != nil {
+		panic(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	seed := &models.EVSpec{Make: "Kia", Model: "EV6", Year: 2023, Capacity: 77.4, Power: 235, Chemistry: "NMC", Source: "manual"}
+	if err := store.InsertEVSpec(ctx, seed, []float32{1, 0}); err != nil {
+		panic(err)
+	}
+
+	logStages := HookFuncs{
+		BeforeFunc: func(ctx context.Context, e *Event) (*models.EVSpec, error) {
+			fmt.Printf("%s: looking up %d %s %s\n", e.Stage, e.Query.Year, e.Query.Make, e.Query.Model)
+			return nil, nil
+		},
+		AfterFunc: func(ctx context.Context, e *Event) {
+			if e.Spec != nil {
+				fmt.Printf("%s: %.1f kWh\n", e.Stage, e.Spec.Capacity)
+			}
+		},
+	}
+	res := New(store, nil, nil, WithHooks(logStages))
+	if _, err := res.Resolve(ctx, "Kia", "EV6", 2023); err != nil {
+		panic(err)
+	}
+	// Output:
+	// resolve: looking up 2023 Kia EV6
+	// exact: looking up 2023 Kia EV6
+	// exact: 77.4 kWh
+	// resolve: 77.4 kWh
+}
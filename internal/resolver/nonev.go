@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotElectric is returned when the queried vehicle is known not to be an EV
+var ErrNotElectric = errors.New("not an electric vehicle")
+
+// defaultNonEVModels lists popular combustion-only make/model pairs. Models are
+// matched exactly, so EV variants with their own model name (e.g. "F-150
+// Lightning", "Silverado EV") are not caught.
+var defaultNonEVModels = []string{
+	"Toyota:Corolla",
+	"Toyota:Camry",
+	"Toyota:Tacoma",
+	"Toyota:Tundra",
+	"Honda:Civic",
+	"Honda:Accord",
+	"Ford:F-150",
+	"Ford:Mustang",
+	"Chevrolet:Silverado",
+	"Chevrolet:Malibu",
+	"Ram:1500",
+	"Jeep:Wrangler",
+	"Nissan:Altima",
+	"Subaru:Outback",
+}
+
+// NonEVList is a set of make/model pairs known not to be electric vehicles
+type NonEVList map[string]struct{}
+
+// NewNonEVList creates a list from the built-in entries plus the given extra
+// "Make:Model" entries. Malformed entries are ignored.
+func NewNonEVList(extra ...string) NonEVList {
+	list := make(NonEVList)
+	for _, entry := range append(defaultNonEVModels, extra...) {
+		make, model, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(make) == "" || strings.TrimSpace(model) == "" {
+			continue
+		}
+		list[vehicleKey(make, model)] = struct{}{}
+	}
+	return list
+}
+
+// Contains reports whether make/model is a known non-EV
+func (l NonEVList) Contains(make, model string) bool {
+	_, ok := l[vehicleKey(make, model)]
+	return ok
+}
+
+// vehicleKey normalizes make/model for case-insensitive lookups
+func vehicleKey(make, model string) string {
+	return strings.ToLower(strings.TrimSpace(make)) + ":" + strings.ToLower(strings.TrimSpace(model))
+}
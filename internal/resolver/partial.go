@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// PartialPolicy decides what happens when a database result clears the
+// confidence threshold but is missing some fields
+type PartialPolicy int
+
+const (
+	// PartialFill asks the LLM for the missing fields and merges them in
+	PartialFill PartialPolicy = iota
+	// PartialAccept returns the partial result as is, without an LLM call
+	PartialAccept
+)
+
+// WithPartialPolicy sets how partial database results are handled. The
+// default is PartialFill.
+func WithPartialPolicy(policy PartialPolicy) Option {
+	return func(r *Resolver) {
+		r.partial = policy
+	}
+}
+
+// missingFields returns the spec fields that have no value
+func missingFields(spec *models.EVSpec) []llm.Field {
+	var fields []llm.Field
+	if spec.Capacity <= 0 {
+		fields = append(fields, llm.FieldCapacity)
+	}
+	if spec.Power <= 0 {
+		fields = append(fields, llm.FieldPower)
+	}
+	if isMissingChemistry(spec.Chemistry) {
+		fields = append(fields, llm.FieldChemistry)
+	}
+	return fields
+}
+
+// isMissingChemistry reports whether chemistry is empty or a placeholder
+// answer such as "Unknown" or "N/A"
+func isMissingChemistry(chemistry string) bool {
+	switch strings.ToLower(strings.TrimSpace(chemistry)) {
+	case "", "unknown", "n/a", "na", "-":
+		return true
+	}
+	return false
+}
+
+// fillMissing completes a partial database result according to the partial
+// policy, asking the LLM for each missing field on its own. The spec's own
+// make, model and year are queried, so a similarity match is completed with
+// data for the matched vehicle. Fields the LLM cannot answer stay empty with
+// a warning on stderr. With WithSaveLLMResults the filled row is written back.
+func (r *Resolver) fillMissing(ctx context.Context, spec *models.EVSpec) *models.EVSpec {
+	if r.partial == PartialAccept {
+		return spec
+	}
+	fields := missingFields(spec)
+	if len(fields) == 0 {
+		return spec
+	}
+
+	filled := *spec
+	changed := false
+	for _, field := range fields {
+		fmt.Fprintf(os.Stderr, "Filling missing %s from LLM\n", field)
+		answer, err := r.llm.QueryField(ctx, spec.Make, spec.Model, spec.Year, field)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to fill %s: %v\n", field, err)
+			continue
+		}
+
+		switch field {
+		case llm.FieldCapacity:
+			filled.Capacity = answer.Capacity
+		case llm.FieldPower:
+			filled.Power = answer.Power
+		case llm.FieldChemistry:
+			filled.Chemistry = answer.Chemistry
+		}
+		changed = true
+	}
+	if !changed {
+		return spec
+	}
+
+	if !strings.HasSuffix(filled.Source, "+llm") {
+		filled.Source += "+llm"
+	}
+	// Authoritative rows are returned filled but never rewritten
+	if r.saveLLM && !spec.Authoritative {
+		err := retrySave(ctx, func() error {
+			return r.db.UpdateSpecFields(ctx, &filled)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save filled fields: %v\n", err)
+		}
+	}
+	return &filled
+}
@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// WithPriors fills fields missing from a result with the matching prior's
+// typical chemistry and capacity, marking them with source "prior", and warns
+// on stderr when an LLM answer's capacity lies outside the prior's range
+func WithPriors(priors models.Priors) Option {
+	return func(r *Resolver) {
+		r.priors = priors
+	}
+}
+
+// applyPrior checks spec against the most specific prior for its vehicle and
+// fills in missing fields from it
+func (r *Resolver) applyPrior(spec *models.EVSpec) {
+	prior, ok := r.priors.Lookup(spec.Make, spec.Model)
+	if !ok {
+		return
+	}
+
+	if spec.Source == "llm" && spec.Capacity > 0 && outsideRange(spec.Capacity, prior.MinCapacity, prior.MaxCapacity) {
+		fmt.Fprintf(os.Stderr, "Warning: %d %s %s capacity %.1f kWh is outside the expected %s range\n",
+			spec.Year, spec.Make, spec.Model, spec.Capacity, capacityRange(prior))
+	}
+
+	if spec.Capacity <= 0 && prior.Capacity > 0 {
+		spec.Capacity = prior.Capacity
+		spec.CapacitySource = models.SourcePrior
+	}
+	if isMissingChemistry(spec.Chemistry) && len(prior.Chemistries) > 0 {
+		spec.Chemistry = prior.Chemistries[0]
+		spec.ChemistrySource = models.SourcePrior
+	}
+}
+
+// outsideRange reports whether v lies outside [min, max], where a zero bound
+// is open
+func outsideRange(v, min, max float64) bool {
+	return (min > 0 && v < min) || (max > 0 && v > max)
+}
+
+// capacityRange formats the prior's capacity bounds, e.g. "50-100 kWh"
+func capacityRange(p models.Prior) string {
+	switch {
+	case p.MinCapacity > 0 && p.MaxCapacity > 0:
+		return fmt.Sprintf("%g-%g kWh", p.MinCapacity, p.MaxCapacity)
+	case p.MinCapacity > 0:
+		return fmt.Sprintf("at least %g kWh", p.MinCapacity)
+	default:
+		return fmt.Sprintf("at most %g kWh", p.MaxCapacity)
+	}
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrBeforeProduction is returned in strict mode when the queried model year
+// precedes the vehicle's first model year
+var ErrBeforeProduction = errors.New("model year precedes production start")
+
+// defaultProductionYears lists the first model year of popular EVs as
+// "Make:Model:Year" entries. An empty model ("Rivian::2022") applies to every
+// model of the make that has no entry of its own.
+var defaultProductionYears = []string{
+	"Tesla:Roadster:2008",
+	"Tesla:Model S:2012",
+	"Tesla:Model X:2016",
+	"Tesla:Model 3:2017",
+	"Tesla:Model Y:2020",
+	"Tesla:Cybertruck:2024",
+	"Nissan:Leaf:2011",
+	"Nissan:Ariya:2023",
+	"Chevrolet:Bolt EV:2017",
+	"Chevrolet:Bolt EUV:2022",
+	"Ford:Mustang Mach-E:2021",
+	"Ford:F-150 Lightning:2022",
+	"Hyundai:Ioniq 5:2022",
+	"Kia:EV6:2022",
+	"Volkswagen:ID.4:2021",
+	"BMW:i3:2014",
+	"Porsche:Taycan:2020",
+	"Audi:e-tron:2019",
+	"Jaguar:I-Pace:2019",
+	"Rivian::2022",
+	"Lucid::2022",
+	"Polestar::2020",
+}
+
+// ProductionYears maps make/model pairs to their first model year
+type ProductionYears map[string]int
+
+// NewProductionYears creates a table from the built-in entries plus the given
+// extra "Make:Model:Year" entries, which override built-in ones. Malformed
+// entries are ignored.
+func NewProductionYears(extra ...string) ProductionYears {
+	years := make(ProductionYears)
+	for _, entry := range append(defaultProductionYears, extra...) {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		year, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		if err != nil || year <= 0 {
+			continue
+		}
+		years[vehicleKey(parts[0], parts[1])] = year
+	}
+	return years
+}
+
+// FirstYear returns the first model year of make/model, falling back to the
+// make-wide entry. It reports false if neither is known.
+func (p ProductionYears) FirstYear(make, model string) (int, bool) {
+	if year, ok := p[vehicleKey(make, model)]; ok {
+		return year, true
+	}
+	year, ok := p[vehicleKey(make, "")]
+	return year, ok
+}
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// WithRecencyBoost makes similarity searches prefer specs close to the
+// queried year, as scored by db.WithRecencyBoost with weight per model year.
+// The vector stage then answers with the first match in that order that
+// meets the confidence threshold, rather than the most similar one. A weight
+// of 0, the default, disables the boost.
+func WithRecencyBoost(weight float64) Option {
+	return func(r *Resolver) {
+		r.recency = weight
+	}
+}
+
+// searchOptions returns the similarity search options for a query of year
+func (r *Resolver) searchOptions(year int) []db.SearchOption {
+	if r.recency <= 0 {
+		return r.searchOpts
+	}
+	opts := append([]db.SearchOption{}, r.searchOpts...)
+	return append(opts, db.WithRecencyBoost(year, r.recency))
+}
+
+// pickCandidate returns the similarity match the vector stage answers with,
+// or nil if none meets the confidence threshold
+func (r *Resolver) pickCandidate(candidates []models.EVSpec) *models.EVSpec {
+	if r.recency > 0 {
+		for i := range candidates {
+			if candidates[i].Confidence >= models.ConfidenceThreshold {
+				return &candidates[i]
+			}
+		}
+		return nil
+	}
+	if best := mostConfident(candidates); best != nil && best.Confidence >= models.ConfidenceThreshold {
+		return best
+	}
+	return nil
+}
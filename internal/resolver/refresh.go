@@ -0,0 +1,148 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// RefreshReport counts the outcome of a RefreshLLMRows run
+type RefreshReport struct {
+	Upgraded  int // rows filled in or replaced by a more confident answer
+	Unchanged int // complete confident rows, and rows the new answer did not improve
+	Failed    int // rows whose LLM query or update failed
+	Skipped   int // authoritative rows, and rows left unchecked because the call budget ran out
+}
+
+// RefreshLLMRows re-queries the LLM for stored rows with source "llm" that
+// are missing fields or have a confidence below minConfidence. An answer more
+// confident than the stored row replaces the values it provides and the
+// confidence; any other answer only fills in missing fields, so a refresh
+// never churns values that were already answered. Complete rows at or above
+// minConfidence cost no LLM call, and authoritative rows are skipped. At most
+// maxCalls LLM queries are made, incomplete rows first; 0 means no limit.
+// Failures are reported on stderr and counted, not returned.
+func (r *Resolver) RefreshLLMRows(ctx context.Context, maxCalls int, minConfidence float64) (*RefreshReport, error) {
+	rows, report, err := r.refreshCandidates(ctx, maxCalls, minConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		stored := &rows[i]
+		upgraded, err := r.refreshRow(ctx, stored)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: failed to refresh %d %s %s: %v\n", stored.Year, stored.Make, stored.Model, err)
+			report.Failed++
+		case upgraded:
+			report.Upgraded++
+		default:
+			report.Unchanged++
+		}
+	}
+	return report, nil
+}
+
+// RefreshQueries returns the rows RefreshLLMRows would re-query with the same
+// arguments, in the order it queries them
+func (r *Resolver) RefreshQueries(ctx context.Context, maxCalls int, minConfidence float64) ([]Query, error) {
+	rows, _, err := r.refreshCandidates(ctx, maxCalls, minConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]Query, len(rows))
+	for i, row := range rows {
+		queries[i] = Query{Make: row.Make, Model: row.Model, Year: row.Year}
+	}
+	return queries, nil
+}
+
+// refreshCandidates selects the stored "llm" rows a refresh re-queries, up to
+// maxCalls of them, and returns a report counting the rows it passed over.
+// Incomplete rows come before complete ones below minConfidence, so a tight
+// budget goes to filling gaps first. RefreshLLMRows and RefreshQueries share
+// it so the cost estimate covers exactly the rows that are queried.
+func (r *Resolver) refreshCandidates(ctx context.Context, maxCalls int, minConfidence float64) ([]models.EVSpec, *RefreshReport, error) {
+	rows, err := r.db.ListSpecs(ctx, db.SpecFilter{Source: "llm"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &RefreshReport{}
+	var incomplete, uncertain []models.EVSpec
+	for _, row := range rows {
+		switch {
+		case row.Authoritative:
+			report.Skipped++
+		case len(missingFields(&row)) > 0:
+			incomplete = append(incomplete, row)
+		case row.Confidence < minConfidence:
+			uncertain = append(uncertain, row)
+		default:
+			report.Unchanged++
+		}
+	}
+
+	candidates := append(incomplete, uncertain...)
+	if maxCalls > 0 && len(candidates) > maxCalls {
+		report.Skipped += len(candidates) - maxCalls
+		candidates = candidates[:maxCalls]
+	}
+	return candidates, report, nil
+}
+
+// refreshRow re-queries one stored row and writes back the improved values,
+// reporting whether the row changed
+func (r *Resolver) refreshRow(ctx context.Context, stored *models.EVSpec) (bool, error) {
+	answer, err := r.llm.QueryEVSpecs(ctx, stored.Make, stored.Model, stored.Year)
+	if err != nil {
+		return false, err
+	}
+
+	updated := *stored
+	updated.Source = "llm"
+	if answer.Confidence > stored.Confidence {
+		// A more confident answer wins wherever it has a value
+		if answer.Capacity > 0 {
+			updated.Capacity = answer.Capacity
+		}
+		if answer.Power > 0 {
+			updated.Power = answer.Power
+		}
+		if !isMissingChemistry(answer.Chemistry) {
+			updated.Chemistry = answer.Chemistry
+		}
+		updated.Confidence = answer.Confidence
+		if err := r.db.UpdateSpecFields(ctx, &updated, db.WithConfidence()); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if stored.Capacity <= 0 {
+		updated.Capacity = answer.Capacity
+	}
+	if stored.Power <= 0 {
+		updated.Power = answer.Power
+	}
+	if isMissingChemistry(stored.Chemistry) && !isMissingChemistry(answer.Chemistry) {
+		updated.Chemistry = answer.Chemistry
+	}
+	if len(missingFields(&updated)) >= len(missingFields(stored)) {
+		return false, nil
+	}
+
+	if err := r.db.UpdateSpecFields(ctx, &updated); err != nil {
+		return false, err
+	}
+	return true, nil
+}
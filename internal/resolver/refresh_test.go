@@ -0,0 +1,106 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestRefreshLLMRows(t *testing.T) {
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, `{"response": "Capacity: 80 kWh\nPower: 300 kW\nChemistry: NMC"}`)
+	}))
+	defer llmServer.Close()
+
+	ctx := context.Background()
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	seed := []models.EVSpec{
+		// Complete but unsure: the more confident answer replaces it
+		{Make: "Kia", Model: "EV6", Year: 2023, Capacity: 70, Power: 250, Chemistry: "LFP", Confidence: 0.3},
+		// Incomplete: only the missing chemistry is filled in
+		{Make: "Kia", Model: "EV9", Year: 2024, Capacity: 99.8, Power: 283, Confidence: 0.5},
+		// Complete and confident enough: not queried
+		{Make: "Kia", Model: "Niro EV", Year: 2023, Capacity: 64.8, Power: 150, Chemistry: "NMC", Confidence: 0.9},
+		// Authoritative: skipped even though it is incomplete
+		{Make: "Kia", Model: "Soul EV", Year: 2020, Capacity: 64, Confidence: 0.3, Authoritative: true},
+	}
+	for i := range seed {
+		seed[i].Source = "llm"
+		if err := store.InsertEVSpec(ctx, &seed[i], []float32{1, 0}); err != nil {
+			t.Fatalf("failed to seed %s: %v", seed[i].Model, err)
+		}
+	}
+
+	res := New(store, nil, llm.NewWithProvider(llm.ProviderOllama, "", llmServer.URL, "test"))
+	queries, err := res.RefreshQueries(ctx, 0, 0.7)
+	if err != nil {
+		t.Fatalf("RefreshQueries: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Model != "EV9" || queries[1].Model != "EV6" {
+		t.Errorf("queries = %+v, want EV9 (incomplete) before EV6 (low confidence)", queries)
+	}
+
+	report, err := res.RefreshLLMRows(ctx, 0, 0.7)
+	if err != nil {
+		t.Fatalf("RefreshLLMRows: %v", err)
+	}
+	want := RefreshReport{Upgraded: 2, Unchanged: 1, Skipped: 1}
+	if *report != want {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+
+	ev6, err := store.GetByMakeModelYear(ctx, "Kia", "EV6", 2023)
+	if err != nil {
+		t.Fatalf("failed to get EV6: %v", err)
+	}
+	if ev6.Capacity != 80 || ev6.Chemistry != "NMC" || ev6.Confidence != models.LLMConfidenceScore {
+		t.Errorf("EV6 = %+v, want the more confident answer", ev6)
+	}
+
+	ev9, err := store.GetByMakeModelYear(ctx, "Kia", "EV9", 2024)
+	if err != nil {
+		t.Fatalf("failed to get EV9: %v", err)
+	}
+	if ev9.Capacity != 99.8 || ev9.Chemistry != "NMC" {
+		t.Errorf("EV9 = %+v, want the stored capacity kept and the chemistry filled in", ev9)
+	}
+}
+
+func TestRefreshQueriesBudget(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	for _, model := range []string{"EV3", "EV6", "EV9"} {
+		spec := &models.EVSpec{Make: "Kia", Model: model, Year: 2024, Source: "llm"}
+		if err := store.InsertEVSpec(ctx, spec, []float32{1, 0}); err != nil {
+			t.Fatalf("failed to seed %s: %v", model, err)
+		}
+	}
+
+	res := New(store, nil, nil)
+	queries, err := res.RefreshQueries(ctx, 2, 0.7)
+	if err != nil {
+		t.Fatalf("RefreshQueries: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Errorf("got %d queries, want the budget of 2", len(queries))
+	}
+}
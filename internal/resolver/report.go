@@ -0,0 +1,44 @@
+package resolver
+
+// FleetReport aggregates the results of resolving a fleet of vehicles
+type FleetReport struct {
+	Vehicles         int            `json:"vehicles"`           // Queries in the fleet
+	Resolved         int            `json:"resolved"`           // Queries that produced a spec
+	Failed           int            `json:"failed"`             // Queries that returned an error
+	TotalCapacityKWh float64        `json:"total_capacity_kwh"` // Sum of resolved battery capacities
+	AveragePowerKW   float64        `json:"average_power_kw"`   // Mean power over resolved vehicles
+	Chemistries      map[string]int `json:"chemistries"`        // Resolved vehicles per chemistry
+	Sources          map[string]int `json:"sources"`            // Resolved vehicles per source (database, llm)
+
+	totalPower float64
+}
+
+// NewFleetReport creates an empty fleet report
+func NewFleetReport() *FleetReport {
+	return &FleetReport{
+		Chemistries: make(map[string]int),
+		Sources:     make(map[string]int),
+	}
+}
+
+// Add folds one batch result into the report
+func (r *FleetReport) Add(result BatchResult) {
+	r.Vehicles++
+	if result.Err != nil || result.Spec == nil {
+		r.Failed++
+		return
+	}
+
+	spec := result.Spec
+	r.Resolved++
+	r.TotalCapacityKWh += spec.Capacity
+	r.totalPower += spec.Power
+	r.AveragePowerKW = r.totalPower / float64(r.Resolved)
+
+	chemistry := spec.Chemistry
+	if chemistry == "" {
+		chemistry = "unknown"
+	}
+	r.Chemistries[chemistry]++
+	r.Sources[spec.Source]++
+}
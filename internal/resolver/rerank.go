@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"math"
+	"sort"
+
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// RerankOptions configures numeric reranking of similarity search candidates.
+// A candidate's score is the weighted average of its embedding similarity and
+// its closeness to each numeric target:
+//
+//	score = (VectorWeight*confidence + CapacityWeight*closeness(capacity) + PowerWeight*closeness(power)) / sum(weights)
+//
+// closeness falls linearly from 1 at the target to 0 at the tolerance, so a
+// 60 kWh target with a 10 kWh tolerance scores 65 kWh as 0.5 and 75 kWh as 0.
+type RerankOptions struct {
+	TargetCapacity    float64 // Target capacity in kWh (0 disables the capacity term)
+	TargetPower       float64 // Target power in kW (0 disables the power term)
+	CapacityTolerance float64 // Capacity difference in kWh at which closeness reaches 0
+	PowerTolerance    float64 // Power difference in kW at which closeness reaches 0
+	VectorWeight      float64 // Weight of the embedding similarity
+	CapacityWeight    float64 // Weight of the capacity closeness
+	PowerWeight       float64 // Weight of the power closeness
+}
+
+// Enabled reports whether any numeric target is set
+func (o RerankOptions) Enabled() bool {
+	return o.TargetCapacity > 0 || o.TargetPower > 0
+}
+
+// Rerank reorders the candidates by their weighted score, best first.
+// Candidates are returned unchanged if no numeric target is set.
+func Rerank(candidates []models.EVSpec, opts RerankOptions) []models.EVSpec {
+	if !opts.Enabled() {
+		return candidates
+	}
+
+	type scored struct {
+		spec  models.EVSpec
+		score float64
+	}
+	results := make([]scored, len(candidates))
+	for i, spec := range candidates {
+		results[i] = scored{spec: spec, score: opts.score(spec)}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	reranked := make([]models.EVSpec, len(results))
+	for i, r := range results {
+		reranked[i] = r.spec
+	}
+	return reranked
+}
+
+// score computes the weighted score for a single candidate
+func (o RerankOptions) score(spec models.EVSpec) float64 {
+	total := o.VectorWeight * spec.Confidence
+	weights := o.VectorWeight
+
+	if o.TargetCapacity > 0 {
+		total += o.CapacityWeight * closeness(spec.Capacity, o.TargetCapacity, o.CapacityTolerance)
+		weights += o.CapacityWeight
+	}
+	if o.TargetPower > 0 {
+		total += o.PowerWeight * closeness(spec.Power, o.TargetPower, o.PowerTolerance)
+		weights += o.PowerWeight
+	}
+
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+// closeness maps the distance between value and target onto [0, 1]
+func closeness(value, target, tolerance float64) float64 {
+	if tolerance <= 0 {
+		if value == target {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, 1-math.Abs(value-target)/tolerance)
+}
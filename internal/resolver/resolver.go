@@ -0,0 +1,289 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/metrics"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+// Resolver runs the lookup pipeline: exact match, similarity search, then LLM fallback
+type Resolver struct {
+	db         db.SpecStore
+	embedding  *embedding.Service
+	llm        *llm.Service
+	searchOpts []db.SearchOption
+	pool       int     // similarity candidates considered before thresholding
+	recency    float64 // recency boost weight per model year, 0 to disable
+	nonEV      NonEVList
+	hooks      []Hook // run around every stage, in order
+
+	chemistry ChemistryRules // nil disables chemistry inference
+	priors    models.Priors  // nil disables priors
+	partial   PartialPolicy
+	firstYear ProductionYears // nil disables the production year check
+	strict    bool            // reject rather than warn on pre-production years
+
+	saveLLM bool // store LLM answers in the database
+	saveRaw bool // keep the raw LLM response with saved answers
+
+	ownsStore bool // Close closes db, set by NewResolver
+}
+
+// Option is a functional option for Resolver
+type Option func(*Resolver)
+
+// WithSearchOptions sets the options passed to every similarity search
+func WithSearchOptions(opts ...db.SearchOption) Option {
+	return func(r *Resolver) {
+		r.searchOpts = append(r.searchOpts, opts...)
+	}
+}
+
+// WithCandidatePool considers the n nearest similarity matches and picks the
+// most confident one, so approximate-index jitter in the ranking does not
+// force an LLM fallback. The default is 1.
+func WithCandidatePool(n int) Option {
+	return func(r *Resolver) {
+		if n > 0 {
+			r.pool = n
+		}
+	}
+}
+
+// WithNonEVGuard short-circuits queries for known non-EVs with ErrNotElectric
+// before any embedding or LLM call is made
+func WithNonEVGuard(list NonEVList) Option {
+	return func(r *Resolver) {
+		r.nonEV = list
+	}
+}
+
+// WithMetrics records how each query was resolved and how long it took, by
+// appending a metrics hook to the chain
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(r *Resolver) {
+		r.hooks = append(r.hooks, metricsHook{recorder: recorder})
+	}
+}
+
+// WithChemistryInference fills in a missing chemistry using rules, marking the
+// result with ChemistrySource "inferred"
+func WithChemistryInference(rules ChemistryRules) Option {
+	return func(r *Resolver) {
+		r.chemistry = rules
+	}
+}
+
+// WithProductionYearCheck warns on stderr when the queried year precedes the
+// vehicle's first model year. With strict, such queries fail with
+// ErrBeforeProduction instead.
+func WithProductionYearCheck(years ProductionYears, strict bool) Option {
+	return func(r *Resolver) {
+		r.firstYear = years
+		r.strict = strict
+	}
+}
+
+// WithSaveLLMResults stores LLM fallback answers in the database with source
+// "llm", so later queries for the same vehicle are answered by exact match.
+// With keepRawResponse the unparsed LLM output is stored alongside for auditing.
+func WithSaveLLMResults(keepRawResponse bool) Option {
+	return func(r *Resolver) {
+		r.saveLLM = true
+		r.saveRaw = keepRawResponse
+	}
+}
+
+// New creates a new resolver over the given services
+func New(dbClient db.SpecStore, embeddingSvc *embedding.Service, llmSvc *llm.Service, opts ...Option) *Resolver {
+	r := &Resolver{
+		db:        dbClient,
+		embedding: embeddingSvc,
+		llm:       llmSvc,
+		hooks:     []Hook{defaultLogHook},
+		pool:      1,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve looks up the EV spec for make/model/year
+func (r *Resolver) Resolve(ctx context.Context, make, model string, year int) (*models.EVSpec, error) {
+	q := Query{Make: make, Model: model, Year: year}
+	e := &Event{Stage: StageResolve, Query: q, Outcome: "hook"}
+	return r.runStage(ctx, e, func() (*models.EVSpec, error) {
+		spec, outcome, err := r.resolve(ctx, q)
+		e.Outcome = outcome
+		if spec != nil {
+			// Raw LLM output is for auditing via describe/history, not query results
+			spec.RawResponse = ""
+			// Priors and inference run after any save so guesses are never
+			// stored as facts. Priors are user-supplied, so they go first.
+			if r.priors != nil {
+				r.applyPrior(spec)
+			}
+			if r.chemistry != nil {
+				r.chemistry.Apply(spec)
+			}
+		}
+		return spec, err
+	})
+}
+
+// resolve runs the pipeline and reports the stage that produced the outcome:
+// "exact", "vector", "llm", or "rejected"/"error" on failure
+func (r *Resolver) resolve(ctx context.Context, q Query) (*models.EVSpec, string, error) {
+	make, model, year := q.Make, q.Model, q.Year
+	if err := models.ValidateQuery(make, model, year); err != nil {
+		return nil, "rejected", err
+	}
+
+	// Try exact match first
+	spec, err := r.runStage(ctx, &Event{Stage: StageExact, Query: q}, func() (*models.EVSpec, error) {
+		spec, err := r.db.GetByMakeModelYear(ctx, make, model, year)
+		if err != nil {
+			return nil, fmt.Errorf("database query error: %w", err)
+		}
+		if spec == nil {
+			return nil, nil
+		}
+		return r.fillMissing(ctx, spec), nil
+	})
+	if err != nil {
+		return nil, "error", err
+	}
+
+	// If exact match found, return it
+	if spec != nil {
+		return spec, "exact", nil
+	}
+
+	// Skip the expensive stages for vehicles we know are not EVs
+	if r.nonEV.Contains(make, model) {
+		return nil, "rejected", fmt.Errorf("%d %s %s: %w", year, make, model, ErrNotElectric)
+	}
+
+	// Catch impossible years before the LLM confidently invents an answer
+	if first, ok := r.firstYear.FirstYear(make, model); ok && year < first {
+		if r.strict {
+			return nil, "rejected", fmt.Errorf("%d %s %s: first model year is %d: %w", year, make, model, first, ErrBeforeProduction)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s %s was first produced for model year %d; %d is likely wrong\n", make, model, first, year)
+	}
+
+	// The query embedding is kept for saving an LLM answer under it
+	var embeddingVector []float32
+	spec, err = r.runStage(ctx, &Event{Stage: StageVector, Query: q}, func() (*models.EVSpec, error) {
+		// Build query text and get embedding
+		queryText, err := r.embedding.QueryText(make, model, year)
+		if err != nil {
+			return nil, err
+		}
+		embeddingVector, err = r.embedding.GetEmbedding(ctx, queryText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get embedding: %w", err)
+		}
+
+		// Perform similarity search
+		results, err := r.db.SimilaritySearch(ctx, embeddingVector, r.pool, r.searchOptions(year)...)
+		if err != nil {
+			return nil, fmt.Errorf("similarity search error: %w", err)
+		}
+
+		// Check if a candidate in the pool has sufficient confidence
+		traceOf(ctx).noteBest(mostConfident(results))
+		if best := r.pickCandidate(results); best != nil {
+			return r.fillMissing(ctx, best), nil
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, "error", err
+	}
+	if spec != nil {
+		return spec, "vector", nil
+	}
+
+	// Fall back to LLM
+	spec, err = r.runStage(ctx, &Event{Stage: StageLLM, Query: q}, func() (*models.EVSpec, error) {
+		spec, err := r.llm.QueryEVSpecs(ctx, make, model, year)
+		if err != nil {
+			return nil, fmt.Errorf("LLM query error: %w", err)
+		}
+
+		// A hook may have answered the vector stage without an embedding
+		if r.saveLLM && embeddingVector != nil {
+			r.saveLLMResult(ctx, spec, embeddingVector)
+		}
+		return spec, nil
+	})
+	if err != nil {
+		return nil, "error", err
+	}
+	if spec == nil {
+		return nil, "error", fmt.Errorf("LLM query error: no result")
+	}
+
+	return spec, "llm", nil
+}
+
+// Search returns up to limit similarity matches for make/model/year, best
+// first, without confidence thresholding or LLM fallback
+func (r *Resolver) Search(ctx context.Context, make, model string, year, limit int) ([]models.EVSpec, error) {
+	if err := models.ValidateQuery(make, model, year); err != nil {
+		return nil, err
+	}
+
+	queryText, err := r.embedding.QueryText(make, model, year)
+	if err != nil {
+		return nil, err
+	}
+	embeddingVector, err := r.embedding.GetEmbedding(ctx, queryText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding: %w", err)
+	}
+
+	results, err := r.db.SimilaritySearch(ctx, embeddingVector, limit, r.searchOptions(year)...)
+	if err != nil {
+		return nil, fmt.Errorf("similarity search error: %w", err)
+	}
+
+	return results, nil
+}
+
+// saveLLMResult stores an LLM answer under the query embedding, retrying
+// transient database errors. Failures are reported on stderr but do not fail
+// the query, since the answer is still valid.
+func (r *Resolver) saveLLMResult(ctx context.Context, spec *models.EVSpec, embeddingVector []float32) {
+	saved := *spec
+	if !r.saveRaw {
+		saved.RawResponse = ""
+	}
+	err := retrySave(ctx, func() error {
+		return r.db.InsertEVSpec(ctx, &saved, embeddingVector)
+	})
+	if err != nil && !errors.Is(err, db.ErrAuthoritative) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save LLM result: %v\n", err)
+	}
+}
+
+// mostConfident returns the candidate with the highest confidence, or nil if
+// there are none. Earlier candidates win ties.
+func mostConfident(candidates []models.EVSpec) *models.EVSpec {
+	var best *models.EVSpec
+	for i := range candidates {
+		if best == nil || candidates[i].Confidence > best.Confidence {
+			best = &candidates[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,161 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+func TestResolveRejectsInvalidInput(t *testing.T) {
+	calls := 0
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls++
+		http.Error(w, "unexpected call", http.StatusInternalServerError)
+	}))
+	defer provider.Close()
+
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+	res := New(store,
+		embedding.NewWithProvider(embedding.ProviderOllama, "", provider.URL, "test"),
+		llm.NewWithProvider(llm.ProviderOllama, "", provider.URL, "test"))
+
+	ctx := context.Background()
+	long := strings.Repeat("x", models.MaxNameLength+1)
+	for _, q := range []Query{
+		{Make: "", Model: "Model 3", Year: 2023},
+		{Make: "Tesla", Model: "", Year: 2023},
+		{Make: "  ", Model: "Model 3", Year: 2023},
+		{Make: "Tesla", Model: "\t", Year: 2023},
+		{Make: long, Model: "Model 3", Year: 2023},
+		{Make: "Tesla", Model: long, Year: 2023},
+		{Make: "Tesla\x00", Model: "Model 3", Year: 2023},
+		{Make: "Tesla", Model: "Model\n3", Year: 2023},
+		{Make: "Tesla", Model: "Model \xff", Year: 2023},
+		{Make: "Tesla", Model: "Model 3", Year: models.MinModelYear - 1},
+		{Make: "Tesla", Model: "Model 3", Year: time.Now().Year() + models.MaxModelYearAhead + 1},
+	} {
+		if _, err := res.Resolve(ctx, q.Make, q.Model, q.Year); !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("Resolve(%q, %q, %d) = %v, want ErrInvalidInput", q.Make, q.Model, q.Year, err)
+		}
+		if _, err := res.Search(ctx, q.Make, q.Model, q.Year, 5); !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("Search(%q, %q, %d) = %v, want ErrInvalidInput", q.Make, q.Model, q.Year, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("providers were called %d times for invalid input, want 0", calls)
+	}
+}
+
+// benchmarkResolver returns a resolver over a json store seeded with one
+// Tesla Model 3, whose embedding server answers every text with that row's
+// embedding and whose LLM server answers every prompt with a full spec
+func benchmarkResolver(b *testing.B) *Resolver {
+	b.Helper()
+	embedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, `{"embeddings": [[0.6, 0.8, 0]]}`)
+	}))
+	b.Cleanup(embedServer.Close)
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, `{"response": "Capacity: 80 kWh\nPower: 300 kW\nChemistry: NMC"}`)
+	}))
+	b.Cleanup(llmServer.Close)
+
+	store, err := db.OpenJSONStore(filepath.Join(b.TempDir(), "specs.json"))
+	if err != nil {
+		b.Fatalf("failed to open store: %v", err)
+	}
+	b.Cleanup(store.Close)
+	seed := &models.EVSpec{Make: "Tesla", Model: "Model 3", Year: 2023, Capacity: 75, Power: 283, Chemistry: "NMC", Source: "manual"}
+	if err := store.InsertEVSpec(context.Background(), seed, []float32{0.6, 0.8, 0}); err != nil {
+		b.Fatalf("failed to seed store: %v", err)
+	}
+
+	res := New(store,
+		embedding.NewWithProvider(embedding.ProviderOllama, "", embedServer.URL, "test"),
+		llm.NewWithProvider(llm.ProviderOllama, "", llmServer.URL, "test"))
+	// Keep the LLM fallback notice out of the benchmark output
+	res.hooks = []Hook{logHook{w: io.Discard}}
+	return res
+}
+
+func BenchmarkResolve(b *testing.B) {
+	ctx := context.Background()
+	res := benchmarkResolver(b)
+
+	b.Run("exact", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := res.Resolve(ctx, "Tesla", "Model 3", 2023); err != nil {
+				b.Fatalf("Resolve: %v", err)
+			}
+		}
+	})
+	b.Run("vector", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := res.Resolve(ctx, "Tesla", "Model 3 Long Range", 2023); err != nil {
+				b.Fatalf("Resolve: %v", err)
+			}
+		}
+	})
+
+	// Nothing is similar enough, so every query reaches the LLM
+	res.embedding = embedding.NewWithProvider(embedding.ProviderOllama, "", orthogonalEmbedServer(b), "test")
+	b.Run("llm", func(b *testing.B) {
+		discardStderr(b)
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := res.Resolve(ctx, "Kia", "EV6", 2023); err != nil {
+				b.Fatalf("Resolve: %v", err)
+			}
+		}
+	})
+}
+
+// orthogonalEmbedServer starts an embedding server whose embedding matches
+// no row stored by benchmarkResolver
+func orthogonalEmbedServer(b *testing.B) string {
+	b.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, `{"embeddings": [[0, 0, 1]]}`)
+	}))
+	b.Cleanup(server.Close)
+	return server.URL
+}
+
+// discardStderr silences the LLM service's progress output on stderr for the
+// rest of the benchmark
+func discardStderr(b *testing.B) {
+	b.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	stderr := os.Stderr
+	os.Stderr = devNull
+	b.Cleanup(func() {
+		os.Stderr = stderr
+		devNull.Close()
+	})
+}
@@ -0,0 +1,35 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+)
+
+// saveAttempts is how many times a write-back of an LLM answer is tried
+// before giving up on a transient database error
+const saveAttempts = 3
+
+// saveRetryDelay is the wait before the first retry, doubled for each later one
+const saveRetryDelay = 200 * time.Millisecond
+
+// retrySave runs write, retrying it while it fails with a transient database
+// error. A retried upsert of the same values is idempotent, and the history
+// trigger never records the same superseded revision twice, so a write that
+// did commit before its error was reported leaves no duplicate audit entry.
+func retrySave(ctx context.Context, write func() error) error {
+	delay := saveRetryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = write(); err == nil || attempt == saveAttempts || !db.IsTransient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,352 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resilience"
+)
+
+// defaultProbeTimeout bounds each provider probe made by NewResolver
+const defaultProbeTimeout = 15 * time.Second
+
+// SetupOption is a functional option for NewResolver
+type SetupOption func(*setup)
+
+// setup collects the options of NewResolver
+type setup struct {
+	probeEmbedding bool
+	probeLLM       bool
+	probeTimeout   time.Duration
+	noCache        bool
+	embeddingCfg   *models.Config
+	storeOpts      []db.Option
+	embeddingOpts  []embedding.Option
+	llmOpts        []llm.Option
+	resolverOpts   []Option
+}
+
+// WithProbe makes NewResolver embed a short text and send a minimal LLM
+// prompt before returning, so a missing key or unreachable provider fails at
+// startup rather than on the first query. Each probe is a billed request.
+func WithProbe() SetupOption {
+	return func(s *setup) {
+		s.probeEmbedding = true
+		s.probeLLM = true
+	}
+}
+
+// WithEmbeddingProbe probes only the embedding provider, e.g. when most
+// queries are answered from the database and the LLM is rarely needed
+func WithEmbeddingProbe() SetupOption {
+	return func(s *setup) {
+		s.probeEmbedding = true
+	}
+}
+
+// WithProbeTimeout bounds each provider probe. The default is 15 seconds.
+func WithProbeTimeout(d time.Duration) SetupOption {
+	return func(s *setup) {
+		if d > 0 {
+			s.probeTimeout = d
+		}
+	}
+}
+
+// WithoutEmbeddingCache disables caching embeddings in the database
+func WithoutEmbeddingCache() SetupOption {
+	return func(s *setup) {
+		s.noCache = true
+	}
+}
+
+// WithEmbeddingConfig creates the embedding service from embeddingCfg
+// instead of the resolver's configuration, e.g. to embed queries for the
+// secondary embedding column with EMBEDDING_ALT_PROVIDER
+func WithEmbeddingConfig(embeddingCfg *models.Config) SetupOption {
+	return func(s *setup) {
+		s.embeddingCfg = embeddingCfg
+	}
+}
+
+// WithStoreOptions sets the options passed to db.New for the Postgres backend
+func WithStoreOptions(opts ...db.Option) SetupOption {
+	return func(s *setup) {
+		s.storeOpts = append(s.storeOpts, opts...)
+	}
+}
+
+// WithEmbeddingOptions adds options applied after the configured ones when
+// creating the embedding service
+func WithEmbeddingOptions(opts ...embedding.Option) SetupOption {
+	return func(s *setup) {
+		s.embeddingOpts = append(s.embeddingOpts, opts...)
+	}
+}
+
+// WithLLMOptions adds options applied after the configured ones when creating
+// the LLM service
+func WithLLMOptions(opts ...llm.Option) SetupOption {
+	return func(s *setup) {
+		s.llmOpts = append(s.llmOpts, opts...)
+	}
+}
+
+// WithResolverOptions adds resolver options applied after ConfigOptions
+func WithResolverOptions(opts ...Option) SetupOption {
+	return func(s *setup) {
+		s.resolverOpts = append(s.resolverOpts, opts...)
+	}
+}
+
+// NewResolver builds a ready-to-use resolver from configuration: it opens the
+// spec store selected by STORE_BACKEND (pinging Postgres), creates the
+// embedding and LLM services, and with WithProbe checks both providers.
+// Embeddings are cached in Postgres unless WithoutEmbeddingCache is given.
+// On success the caller must Close the resolver to release the store.
+func NewResolver(ctx context.Context, cfg *models.Config, opts ...SetupOption) (*Resolver, error) {
+	s := &setup{probeTimeout: defaultProbeTimeout}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	store, err := OpenStore(ctx, cfg, s.storeOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingCfg := cfg
+	if s.embeddingCfg != nil {
+		embeddingCfg = s.embeddingCfg
+	}
+	var embeddingOpts []embedding.Option
+	if dbClient, ok := store.(*db.Client); ok && !s.noCache {
+		embeddingOpts = append(embeddingOpts, embedding.WithCache(dbClient))
+	}
+	if dbClient, ok := store.(*db.Client); ok && (len(embeddingCfg.EmbeddingRace) > 1 || len(embeddingCfg.EmbeddingChain) > 1) {
+		opt, err := columnDimension(ctx, embeddingCfg, dbClient)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		if opt != nil {
+			embeddingOpts = append(embeddingOpts, opt)
+		}
+	}
+	embeddingSvc := NewEmbeddingService(embeddingCfg, append(embeddingOpts, s.embeddingOpts...)...)
+	llmSvc := NewLLMService(cfg, s.llmOpts...)
+
+	if s.probeEmbedding {
+		probeCtx, cancel := context.WithTimeout(ctx, s.probeTimeout)
+		_, err := embeddingSvc.Probe(probeCtx, embedding.ProviderType(embeddingCfg.EmbeddingProvider))
+		cancel()
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to probe %s embedding provider: %w", embeddingCfg.EmbeddingProvider, err)
+		}
+	}
+	if s.probeLLM {
+		probeCtx, cancel := context.WithTimeout(ctx, s.probeTimeout)
+		err := llmSvc.Probe(probeCtx)
+		cancel()
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to probe %s LLM provider: %w", cfg.LLMProvider, err)
+		}
+	}
+
+	r := New(store, embeddingSvc, llmSvc, append(ConfigOptions(cfg), s.resolverOpts...)...)
+	r.ownsStore = true
+	return r, nil
+}
+
+// Close closes the spec store opened by NewResolver. A resolver created with
+// New leaves its store to the caller, and Close does nothing.
+func (r *Resolver) Close() {
+	if r.ownsStore {
+		r.db.Close()
+	}
+}
+
+// Store returns the spec store the resolver reads from
+func (r *Resolver) Store() db.SpecStore {
+	return r.db
+}
+
+// Embedding returns the resolver's embedding service
+func (r *Resolver) Embedding() *embedding.Service {
+	return r.embedding
+}
+
+// LLM returns the resolver's LLM service
+func (r *Resolver) LLM() *llm.Service {
+	return r.llm
+}
+
+// OpenStore opens the spec store selected by STORE_BACKEND, passing
+// DBOptions and then opts to db.New for the Postgres backend
+func OpenStore(ctx context.Context, cfg *models.Config, opts ...db.Option) (db.SpecStore, error) {
+	if cfg.StoreBackend == "json" {
+		store, err := db.OpenJSONStore(cfg.StorePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spec store: %w", err)
+		}
+		return store, nil
+	}
+
+	dbClient, err := db.New(ctx, cfg.DatabaseURL, append(DBOptions(cfg), opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return dbClient, nil
+}
+
+// columnDimension pins raced and chained embeddings to the dimension of the
+// embedding column, so a fallback answering with another size is rejected
+// from the first query, and refuses a LOCAL_EMBEDDING_DIMENSION that cannot
+// fit the column. A column whose dimension cannot be read, e.g. in an
+// unmigrated database, pins nothing.
+func columnDimension(ctx context.Context, cfg *models.Config, dbClient *db.Client) (embedding.Option, error) {
+	dimension, err := dbClient.EmbeddingDimension(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	local := slices.Contains(cfg.EmbeddingRace, "local") || slices.Contains(cfg.EmbeddingChain, "local")
+	if local && cfg.LocalEmbedDim > 0 && cfg.LocalEmbedDim != dimension {
+		return nil, fmt.Errorf("LOCAL_EMBEDDING_DIMENSION is %d but the embedding column has %d dimensions; every raced or chained provider must match it", cfg.LocalEmbedDim, dimension)
+	}
+	return embedding.WithDimension(dimension), nil
+}
+
+// DBOptions returns the database client options derived from configuration
+func DBOptions(cfg *models.Config) []db.Option {
+	var opts []db.Option
+	if cfg.DBQueryAttempts > 0 {
+		opts = append(opts, db.WithQueryAttempts(cfg.DBQueryAttempts))
+	}
+	return opts
+}
+
+// ProviderPolicy returns the retry, timeout and circuit breaker policy for
+// provider requests described by the configuration
+func ProviderPolicy(cfg *models.Config) resilience.Policy {
+	return resilience.Policy{
+		MaxRetries:       max(cfg.ProviderRetries, 0),
+		Backoff:          cfg.ProviderBackoff,
+		Timeout:          cfg.ProviderTimeout,
+		BreakerThreshold: max(cfg.BreakerThreshold, 0),
+		BreakerCooldown:  cfg.BreakerCooldown,
+	}
+}
+
+// NewEmbeddingService creates the embedding service described by the
+// configuration, applying extra after the configured options
+func NewEmbeddingService(cfg *models.Config, extra ...embedding.Option) *embedding.Service {
+	opts := []embedding.Option{
+		embedding.WithLocalHTTP(cfg.LocalEmbedURL, embedding.ResponseShape(cfg.LocalEmbedShape), cfg.LocalEmbedModel),
+		embedding.WithResilience(ProviderPolicy(cfg)),
+	}
+	if cfg.LocalEmbedDim > 0 && (cfg.EmbeddingProvider == "local" || slices.Contains(cfg.EmbeddingRace, "local") || slices.Contains(cfg.EmbeddingChain, "local")) {
+		opts = append(opts, embedding.WithDimension(cfg.LocalEmbedDim))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, embedding.WithUserAgent(cfg.UserAgent))
+	}
+	if cfg.EmbedMaxInput != 0 {
+		opts = append(opts, embedding.WithMaxInputChars(cfg.EmbedMaxInput))
+	}
+	if cfg.QueryTemplate != nil || cfg.DocumentTemplate != nil {
+		opts = append(opts, embedding.WithTextTemplates(cfg.QueryTemplate, cfg.DocumentTemplate))
+	}
+	if len(cfg.EmbeddingRace) > 1 {
+		providers := make([]embedding.ProviderType, len(cfg.EmbeddingRace))
+		for i, p := range cfg.EmbeddingRace {
+			providers[i] = embedding.ProviderType(p)
+		}
+		opts = append(opts, embedding.WithRacing(providers...))
+	}
+	if len(cfg.EmbeddingChain) > 1 {
+		providers := make([]embedding.ProviderType, len(cfg.EmbeddingChain))
+		for i, p := range cfg.EmbeddingChain {
+			providers[i] = embedding.ProviderType(p)
+		}
+		opts = append(opts, embedding.WithFallback(providers...))
+	}
+	opts = append(opts, extra...)
+
+	return embedding.NewWithProvider(
+		embedding.ProviderType(cfg.EmbeddingProvider),
+		cfg.OpenAIAPIKey,
+		cfg.OllamaURL,
+		cfg.OllamaModel,
+		opts...,
+	)
+}
+
+// NewLLMService creates the LLM service described by the configuration,
+// applying extra after the configured options
+func NewLLMService(cfg *models.Config, extra ...llm.Option) *llm.Service {
+	opts := []llm.Option{
+		llm.WithClaudeModel(cfg.ClaudeModel),
+		llm.WithAnthropicVersion(cfg.AnthropicVersion),
+		llm.WithResilience(ProviderPolicy(cfg)),
+	}
+	if len(cfg.LLMExtraParams) > 0 {
+		opts = append(opts, llm.WithExtraParams(cfg.LLMExtraParams))
+	}
+	if cfg.ChemistryCands {
+		opts = append(opts, llm.WithChemistryCandidates())
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, llm.WithUserAgent(cfg.UserAgent))
+	}
+	if len(cfg.Priors) > 0 {
+		opts = append(opts, llm.WithPriors(cfg.Priors))
+	}
+	if len(cfg.LLMChain) > 1 {
+		providers := make([]llm.ProviderType, len(cfg.LLMChain))
+		for i, p := range cfg.LLMChain {
+			providers[i] = llm.ProviderType(p)
+		}
+		opts = append(opts, llm.WithFallback(providers...))
+	}
+	opts = append(opts, extra...)
+
+	return llm.NewWithProvider(
+		llm.ProviderType(cfg.LLMProvider),
+		cfg.AnthropicAPIKey,
+		cfg.OllamaURL,
+		cfg.OllamaLLMModel,
+		opts...,
+	)
+}
+
+// ConfigOptions returns the resolver options derived from configuration
+func ConfigOptions(cfg *models.Config) []Option {
+	opts := []Option{WithCandidatePool(cfg.SimilarityPool)}
+	if cfg.RecencyWeight > 0 {
+		opts = append(opts, WithRecencyBoost(cfg.RecencyWeight))
+	}
+	if cfg.NonEVGuard {
+		opts = append(opts, WithNonEVGuard(NewNonEVList(cfg.NonEVModels...)))
+	}
+	if cfg.ProductionCheck {
+		opts = append(opts, WithProductionYearCheck(NewProductionYears(cfg.ProductionYears...), false))
+	}
+	if len(cfg.Priors) > 0 {
+		opts = append(opts, WithPriors(cfg.Priors))
+	}
+	if cfg.ChemistryInfer {
+		opts = append(opts, WithChemistryInference(NewChemistryRules(cfg.ChemistryRules...)))
+	}
+	if cfg.SaveLLMResults {
+		opts = append(opts, WithSaveLLMResults(cfg.StoreRawResponse))
+	}
+	return opts
+}
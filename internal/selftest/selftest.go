@@ -0,0 +1,227 @@
+// Package selftest runs the resolver pipeline end to end against built-in mock
+// providers and a throwaway spec store, with no network or database access.
+// Each Case walks one branch of the pipeline, so the cases double as a
+// description of how a query is answered.
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// Mock provider endpoints. Requests never leave the process: mockTransport
+// answers them directly.
+const (
+	mockEmbedURL  = "http://selftest.invalid/v1/embeddings"
+	mockOllamaURL = "http://selftest.invalid"
+)
+
+// mockDimension is the size of the bag-of-words embeddings served by the mock
+const mockDimension = 64
+
+// mockAnswer is what the mock LLM replies to every spec prompt
+const mockAnswer = "Capacity: 135 kWh\nPower: 220 kW\nChemistry: NMC"
+
+// seedSpecs are stored before the cases run
+var seedSpecs = []models.EVSpec{
+	{Make: "Tesla", Model: "Model 3", Year: 2022, Capacity: 75, Power: 250, Chemistry: "NMC", Source: "database"},
+	{Make: "Nissan", Model: "Leaf", Year: 2020, Capacity: 40, Power: 50, Chemistry: "NMC", Source: "database"},
+}
+
+// Case is one query and the result it must produce
+type Case struct {
+	Name     string
+	Query    resolver.Query
+	Outcome  string // stage expected to answer: "exact", "vector" or "llm"
+	Source   string
+	Year     int     // year of the answering spec
+	Capacity float64 // capacity of the answering spec in kWh
+}
+
+// Cases are the checks Run performs, one per branch of the pipeline
+var Cases = []Case{
+	{
+		Name:     "exact",
+		Query:    resolver.Query{Make: "Tesla", Model: "Model 3", Year: 2022},
+		Outcome:  "exact",
+		Source:   "database",
+		Year:     2022,
+		Capacity: 75,
+	},
+	{
+		// No 2023 row, but the 2022 row's embedding is close enough
+		Name:     "vector",
+		Query:    resolver.Query{Make: "Tesla", Model: "Model 3", Year: 2023},
+		Outcome:  "vector",
+		Source:   "database",
+		Year:     2022,
+		Capacity: 75,
+	},
+	{
+		// Nothing stored is similar, so the LLM answers
+		Name:     "llm",
+		Query:    resolver.Query{Make: "Rivian", Model: "R1T", Year: 2023},
+		Outcome:  "llm",
+		Source:   "llm",
+		Year:     2023,
+		Capacity: 135,
+	},
+}
+
+// Result is the outcome of one Case
+type Result struct {
+	Case Case
+	Spec *models.EVSpec
+	Err  error // why the case failed, nil if it passed
+}
+
+// Run seeds a temporary spec store, resolves every case against the mock
+// providers and reports each result to report as it completes. It returns
+// the number of failed cases, and an error only if the test could not be set
+// up.
+func Run(ctx context.Context, report func(Result)) (int, error) {
+	dir, err := os.MkdirTemp("", "ev-oracle-selftest")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := db.OpenJSONStore(filepath.Join(dir, "specs.json"))
+	if err != nil {
+		return 0, err
+	}
+	defer store.Close()
+
+	client := &http.Client{Transport: mockTransport{}}
+	embeddingSvc := embedding.NewWithProvider(embedding.ProviderLocalHTTP, "", "", "",
+		embedding.WithLocalHTTP(mockEmbedURL, embedding.ShapeOpenAI, "selftest"),
+		embedding.WithHTTPClient(client),
+	)
+	llmSvc := llm.NewWithProvider(llm.ProviderOllama, "", mockOllamaURL, "selftest", llm.WithHTTPClient(client))
+
+	for i := range seedSpecs {
+		spec := seedSpecs[i]
+		text, err := embeddingSvc.DocumentText(spec.Make, spec.Model, spec.Year, spec.BodyStyle, spec.Notes)
+		if err != nil {
+			return 0, err
+		}
+		vector, err := embeddingSvc.GetEmbedding(ctx, text)
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed seed spec: %w", err)
+		}
+		if err := store.InsertEVSpec(ctx, &spec, vector); err != nil {
+			return 0, fmt.Errorf("failed to seed spec store: %w", err)
+		}
+	}
+
+	res := resolver.New(store, embeddingSvc, llmSvc)
+	failed := 0
+	for _, c := range Cases {
+		result := runCase(ctx, res, c)
+		if result.Err != nil {
+			failed++
+		}
+		report(result)
+	}
+	return failed, nil
+}
+
+// runCase resolves c and checks the answer against its expectations
+func runCase(ctx context.Context, res *resolver.Resolver, c Case) Result {
+	spec, trace, err := res.ResolveExplain(ctx, c.Query.Make, c.Query.Model, c.Query.Year)
+	result := Result{Case: c, Spec: spec}
+	switch {
+	case err != nil:
+		result.Err = err
+	case trace.Outcome != c.Outcome:
+		result.Err = fmt.Errorf("answered by the %s stage, want %s", trace.Outcome, c.Outcome)
+	case spec.Source != c.Source:
+		result.Err = fmt.Errorf("source %q, want %q", spec.Source, c.Source)
+	case spec.Year != c.Year:
+		result.Err = fmt.Errorf("year %d, want %d", spec.Year, c.Year)
+	case spec.Capacity != c.Capacity:
+		result.Err = fmt.Errorf("capacity %g kWh, want %g kWh", spec.Capacity, c.Capacity)
+	case c.Outcome != "llm" && spec.Confidence < models.ConfidenceThreshold:
+		result.Err = fmt.Errorf("confidence %.2f is below the %.2f threshold", spec.Confidence, models.ConfidenceThreshold)
+	}
+	return result
+}
+
+// mockTransport answers embedding and Ollama generate requests in process
+type mockTransport struct{}
+
+// RoundTrip implements http.RoundTripper
+func (mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer req.Body.Close()
+
+	var body any
+	switch req.URL.String() {
+	case mockEmbedURL:
+		var in struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			return nil, fmt.Errorf("mock embedding server: %w", err)
+		}
+		body = map[string]any{"data": []map[string]any{{"embedding": bagOfWords(in.Input)}}}
+	case mockOllamaURL + "/api/generate":
+		body = map[string]any{"response": mockAnswer, "done": true}
+	default:
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(strings.NewReader("not found")),
+			Request:    req,
+		}, nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// bagOfWords embeds text by hashing its lowercase words into mockDimension
+// buckets and normalizing, so texts sharing most words are similar
+func bagOfWords(text string) []float32 {
+	vector := make([]float64, mockDimension)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%mockDimension]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	embedding := make([]float32, mockDimension)
+	for i, v := range vector {
+		if norm > 0 {
+			embedding[i] = float32(v / norm)
+		}
+	}
+	return embedding
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// specPage is the JSON envelope for the paginated /specs collection
+type specPage struct {
+	Items []models.EVSpec `json:"items"`
+	Total int             `json:"total"`
+	Next  *string         `json:"next"` // URL of the next page, null on the last page
+}
+
+// handleCollection lists stored specs for GET /specs without model and year,
+// filtered by make and chemistry, ordered by sort and paginated with limit and
+// offset
+func (s *Server) handleCollection(w http.ResponseWriter, r *http.Request, f format.Format) {
+	if f != format.JSON {
+		http.Error(w, "the spec collection is only available as JSON", http.StatusNotAcceptable)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, ok := pageParam(w, query, "limit", defaultPageLimit)
+	if !ok {
+		return
+	}
+	offset, ok := pageParam(w, query, "offset", 0)
+	if !ok {
+		return
+	}
+	limit = min(max(limit, 1), maxPageLimit)
+	offset = max(offset, 0)
+	sort, err := db.ParseSort(query.Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := db.SpecFilter{
+		Make:      query.Get("make"),
+		Chemistry: query.Get("chemistry"),
+	}
+	if err := models.ValidateFilter("make", filter.Make); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateFilter("chemistry", filter.Chemistry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	total, err := s.catalog.CountSpecs(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	items, err := s.catalog.ListSpecs(r.Context(), filter, db.WithLimit(limit), db.WithOffset(offset), db.WithSort(sort))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := specPage{Items: items, Total: total}
+	if page.Items == nil {
+		page.Items = []models.EVSpec{}
+	}
+	if offset+len(items) < total {
+		next := *r.URL
+		values := next.Query()
+		values.Set("limit", strconv.Itoa(limit))
+		values.Set("offset", strconv.Itoa(offset+len(items)))
+		next.RawQuery = values.Encode()
+		link := next.RequestURI()
+		page.Next = &link
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType(format.JSON))
+	w.Write(buf.Bytes())
+}
+
+// pageParam parses an integer pagination parameter, writing a 400 response
+// and reporting false if it is malformed
+func pageParam(w http.ResponseWriter, query url.Values, name string, fallback int) (int, bool) {
+	value := query.Get(name)
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, "invalid "+name+": "+value, http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
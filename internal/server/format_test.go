@@ -0,0 +1,55 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/scaryPonens/ev-oracle/internal/format"
+)
+
+func TestUnsupportedFormatListsAcceptedFormats(t *testing.T) {
+	api, _ := validationServer(t)
+	for _, req := range []func() (*http.Response, error){
+		func() (*http.Response, error) {
+			return http.Get(api.URL + "/specs?make=Tesla&model=Model%203&year=2023&format=yaml")
+		},
+		func() (*http.Response, error) {
+			r, _ := http.NewRequest(http.MethodGet, api.URL+"/specs?make=Tesla&model=Model%203&year=2023", nil)
+			r.Header.Set("Accept", "application/xml")
+			return http.DefaultClient.Do(r)
+		},
+	} {
+		resp, err := req()
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotAcceptable {
+			t.Errorf("status = %d, want 406", resp.StatusCode)
+		}
+		for _, f := range format.Formats {
+			if !strings.Contains(string(body), string(f)) {
+				t.Errorf("406 body %q does not mention the %s format", body, f)
+			}
+		}
+		for _, mediaType := range []string{"application/json", "text/csv", "text/markdown", "text/plain"} {
+			if !strings.Contains(string(body), mediaType) {
+				t.Errorf("406 body %q does not mention %s", body, mediaType)
+			}
+		}
+	}
+}
+
+func TestEveryParsedFormatIsNegotiable(t *testing.T) {
+	for _, f := range format.Formats {
+		r := httptest.NewRequest(http.MethodGet, "/specs?format="+string(f), nil)
+		if got, ok := negotiateFormat(r); !ok || got != f {
+			t.Errorf("negotiateFormat(?format=%s) = %q, %v", f, got, ok)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/format"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// Server exposes the resolver and the stored spec catalog over HTTP
+type Server struct {
+	resolver *resolver.Resolver
+	catalog  *db.Client
+	mux      *http.ServeMux
+	formats  []format.Option
+}
+
+// Option is a functional option for Server
+type Option func(*Server)
+
+// WithMetricsHandler mounts h at GET /metrics
+func WithMetricsHandler(h http.Handler) Option {
+	return func(s *Server) {
+		s.mux.Handle("GET /metrics", h)
+	}
+}
+
+// WithFormatOptions applies opts when rendering a resolved spec, e.g.
+// format.WithConfidenceBands
+func WithFormatOptions(opts ...format.Option) Option {
+	return func(s *Server) {
+		s.formats = append(s.formats, opts...)
+	}
+}
+
+// New creates a new HTTP server that resolves lookups with res and lists
+// stored specs from catalog
+func New(res *resolver.Resolver, catalog *db.Client, opts ...Option) *Server {
+	s := &Server{
+		resolver: res,
+		catalog:  catalog,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /specs", s.handleSpecs)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleSpecs resolves a single spec from the make, model and year query
+// params. Without model and year it serves the paginated collection instead.
+func (s *Server) handleSpecs(w http.ResponseWriter, r *http.Request) {
+	f, ok := negotiateFormat(r)
+	if !ok {
+		http.Error(w, unsupportedFormatMessage, http.StatusNotAcceptable)
+		return
+	}
+
+	query := r.URL.Query()
+	make := query.Get("make")
+	model := query.Get("model")
+	yearStr := query.Get("year")
+	if model == "" && yearStr == "" {
+		s.handleCollection(w, r, f)
+		return
+	}
+	if make == "" || model == "" || yearStr == "" {
+		http.Error(w, "make, model and year query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		// Not echoed back, since it may be arbitrarily long
+		http.Error(w, "invalid year: must be a whole number", http.StatusBadRequest)
+		return
+	}
+
+	// Reject oversized or malformed input before any embedding or LLM call
+	if err := models.ValidateQuery(make, model, year); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	explain := false
+	if value := query.Get("explain"); value != "" {
+		if explain, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "invalid explain: must be true or false", http.StatusBadRequest)
+			return
+		}
+		if explain && f != format.JSON {
+			http.Error(w, "explain requires JSON output", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var spec *models.EVSpec
+	var trace *resolver.Resolution
+	if explain {
+		spec, trace, err = s.resolver.ResolveExplain(r.Context(), make, model, year)
+	} else {
+		spec, err = s.resolver.Resolve(r.Context(), make, model, year)
+	}
+	if err != nil {
+		// The client went away, which cancelled the request's context and
+		// aborted any embedding or LLM call in flight; nobody is left to answer
+		if r.Context().Err() != nil {
+			return
+		}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrInvalidInput):
+			status = http.StatusBadRequest
+		case errors.Is(err, resolver.ErrNotElectric), errors.Is(err, resolver.ErrBeforeProduction):
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if trace != nil {
+		writeExplained(w, spec, trace)
+		return
+	}
+	writeSpec(w, f, spec, s.formats)
+}
+
+// explainedSpec is the JSON body for ?explain=true: the spec's fields plus
+// the resolution trace under _meta
+type explainedSpec struct {
+	*models.EVSpec
+	Meta *resolver.Resolution `json:"_meta"`
+}
+
+// writeExplained writes the spec with its resolution trace as JSON
+func writeExplained(w http.ResponseWriter, spec *models.EVSpec, trace *resolver.Resolution) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(explainedSpec{EVSpec: spec, Meta: trace}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType(format.JSON))
+	w.Write(buf.Bytes())
+}
+
+// writeSpec renders the spec into a buffer first so that an encoding
+// failure can still be reported with a proper status code
+func writeSpec(w http.ResponseWriter, f format.Format, spec *models.EVSpec, opts []format.Option) {
+	var buf bytes.Buffer
+	if err := format.WriteSpec(&buf, f, spec, opts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType(f))
+	w.Write(buf.Bytes())
+}
+
+// mediaTypeFormats maps accepted media types to output formats
+var mediaTypeFormats = map[string]format.Format{
+	"application/json": format.JSON,
+	"application/*":    format.JSON,
+	"*/*":              format.JSON,
+	"text/csv":         format.CSV,
+	"text/markdown":    format.Markdown,
+	"text/plain":       format.Text,
+	"text/*":           format.Text,
+}
+
+// unsupportedFormatMessage is the 406 body, listing the ?format= names and
+// media types negotiateFormat accepts
+var unsupportedFormatMessage = func() string {
+	names := make([]string, len(format.Formats))
+	for i, f := range format.Formats {
+		names[i] = string(f)
+	}
+	var mediaTypes []string
+	for mediaType := range mediaTypeFormats {
+		if !strings.Contains(mediaType, "*") {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	sort.Strings(mediaTypes)
+	return fmt.Sprintf("unsupported format; use ?format= with one of %s, or an Accept header of %s",
+		strings.Join(names, ", "), strings.Join(mediaTypes, ", "))
+}()
+
+// negotiateFormat picks the output format from the ?format= query param,
+// falling back to the Accept header and defaulting to JSON. It reports false
+// if the client asked only for formats we cannot produce.
+func negotiateFormat(r *http.Request) (format.Format, bool) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		f, err := format.Parse(name)
+		return f, err == nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return format.JSON, true
+	}
+
+	type candidate struct {
+		format format.Format
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, ok := mediaTypeFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if qStr, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qStr, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{format: f, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	// Highest quality wins; ties keep the client's listed order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].format, true
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// TestClientDisconnectCancelsLLMCall checks that a client hanging up while
+// the LLM is still answering aborts the upstream request
+func TestClientDisconnectCancelsLLMCall(t *testing.T) {
+	embedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": [{"embedding": [0.1, 0.2, 0.3]}]}`)
+	}))
+	defer embedServer.Close()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a closed connection once the body is read
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			t.Errorf("failed to read LLM request: %v", err)
+		}
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer llmServer.Close()
+
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	embeddingSvc := embedding.NewWithProvider(embedding.ProviderLocalHTTP, "", "", "",
+		embedding.WithLocalHTTP(embedServer.URL, embedding.ShapeOpenAI, ""))
+	llmSvc := llm.NewWithProvider(llm.ProviderOllama, "", llmServer.URL, "test")
+	api := httptest.NewServer(New(resolver.New(store, embeddingSvc, llmSvc), nil))
+	defer api.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.URL+"/specs?make=Rivian&model=R1T&year=2023", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs <- err
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("LLM was never queried")
+	}
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("client error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("client request did not return after cancelling")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream LLM request was not cancelled after the client disconnected")
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scaryPonens/ev-oracle/internal/db"
+	"github.com/scaryPonens/ev-oracle/internal/embedding"
+	"github.com/scaryPonens/ev-oracle/internal/llm"
+	"github.com/scaryPonens/ev-oracle/internal/models"
+	"github.com/scaryPonens/ev-oracle/internal/resolver"
+)
+
+// validationServer starts an API whose embedding and LLM providers count
+// the calls they receive
+func validationServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls.Add(1)
+		http.Error(w, "unexpected call", http.StatusInternalServerError)
+	}))
+	t.Cleanup(provider.Close)
+
+	store, err := db.OpenJSONStore(filepath.Join(t.TempDir(), "specs.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	res := resolver.New(store,
+		embedding.NewWithProvider(embedding.ProviderOllama, "", provider.URL, "test"),
+		llm.NewWithProvider(llm.ProviderOllama, "", provider.URL, "test"))
+	// The collection cases are rejected before the catalog is used
+	api := httptest.NewServer(New(res, nil))
+	t.Cleanup(api.Close)
+	return api, &calls
+}
+
+func TestInvalidQueriesAreRejectedBeforeProviderCalls(t *testing.T) {
+	api, calls := validationServer(t)
+	long := strings.Repeat("x", models.MaxNameLength+1)
+	huge := strings.Repeat("x", 64*1024)
+	tooLate := strconv.Itoa(time.Now().Year() + models.MaxModelYearAhead + 1)
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"missing year", url.Values{"make": {"Tesla"}, "model": {"Model 3"}}, "make, model and year query parameters are required"},
+		{"missing make", url.Values{"model": {"Model 3"}, "year": {"2023"}}, "make, model and year query parameters are required"},
+		{"overlong make", url.Values{"make": {long}, "model": {"Model 3"}, "year": {"2023"}}, "make must be at most 100 characters"},
+		{"huge model", url.Values{"make": {"Tesla"}, "model": {huge}, "year": {"2023"}}, "model must be at most 100 characters"},
+		{"blank model", url.Values{"make": {"Tesla"}, "model": {"   "}, "year": {"2023"}}, "model must not be empty"},
+		{"null byte", url.Values{"make": {"Tesla\x00"}, "model": {"Model 3"}, "year": {"2023"}}, "make must not contain control characters"},
+		{"newline", url.Values{"make": {"Tesla"}, "model": {"Model\r\n3"}, "year": {"2023"}}, "model must not contain control characters"},
+		{"escape sequence", url.Values{"make": {"\x1b[2J"}, "model": {"Model 3"}, "year": {"2023"}}, "make must not contain control characters"},
+		{"invalid UTF-8", url.Values{"make": {"Tesla"}, "model": {"Model \xff"}, "year": {"2023"}}, "model must be valid UTF-8"},
+		{"non-numeric year", url.Values{"make": {"Tesla"}, "model": {"Model 3"}, "year": {"twenty"}}, "invalid year: must be a whole number"},
+		{"huge year", url.Values{"make": {"Tesla"}, "model": {"Model 3"}, "year": {strings.Repeat("9", 4096)}}, "invalid year: must be a whole number"},
+		{"year too early", url.Values{"make": {"Tesla"}, "model": {"Model 3"}, "year": {"1899"}}, "year must be between 1900"},
+		{"year too late", url.Values{"make": {"Tesla"}, "model": {"Model 3"}, "year": {tooLate}}, "year must be between 1900"},
+		{"negative year", url.Values{"make": {"Tesla"}, "model": {"Model 3"}, "year": {"-2023"}}, "year must be between 1900"},
+		{"overlong make filter", url.Values{"make": {long}}, "make must be at most 100 characters"},
+		{"control character in chemistry filter", url.Values{"chemistry": {"LFP\x00"}}, "chemistry must not contain control characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(api.URL + "/specs?" + tt.query.Encode())
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read response: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", resp.StatusCode)
+			}
+			if !strings.Contains(string(body), tt.wantErr) {
+				t.Errorf("body = %q, want it to contain %q", body, tt.wantErr)
+			}
+			// Oversized values are described, never echoed back
+			if len(body) > 200 {
+				t.Errorf("response is %d bytes long, want a short message", len(body))
+			}
+		})
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("providers were called %d times for invalid queries, want 0", n)
+	}
+}
+
+func TestLongestValidNameReachesProviders(t *testing.T) {
+	api, calls := validationServer(t)
+	query := url.Values{"make": {strings.Repeat("é", models.MaxNameLength)}, "model": {"Model 3"}, "year": {"2023"}}
+
+	resp, err := http.Get(fmt.Sprintf("%s/specs?%s", api.URL, query.Encode()))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusBadRequest {
+		t.Errorf("status = 400 for a make of exactly %d characters", models.MaxNameLength)
+	}
+	if calls.Load() == 0 {
+		t.Error("a valid query was not passed on to the providers")
+	}
+}
@@ -0,0 +1,25 @@
+// Package version reports the ev-oracle build version
+package version
+
+import "runtime/debug"
+
+// Version is set at build time with
+// -ldflags "-X github.com/scaryPonens/ev-oracle/internal/version.Version=v1.2.3".
+// When unset, the module version recorded by go install is used.
+var Version = ""
+
+// Get returns the build version, or "dev" for untagged local builds
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// UserAgent returns the User-Agent sent with outbound provider requests
+func UserAgent() string {
+	return "ev-oracle/" + Get()
+}
@@ -0,0 +1,5 @@
+-- Drop the tags index
+DROP INDEX IF EXISTS ev_specs_tags_idx;
+
+-- Drop the tags column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,5 @@
+-- Add free-form tags for organizing curated subsets of specs
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- GIN index supports the array containment (@>) and overlap (&&) filters
+CREATE INDEX IF NOT EXISTS ev_specs_tags_idx ON ev_specs USING GIN (tags);
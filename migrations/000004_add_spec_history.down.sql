@@ -0,0 +1,9 @@
+-- Drop the history trigger and function
+DROP TRIGGER IF EXISTS ev_specs_history_trigger ON ev_specs;
+DROP FUNCTION IF EXISTS ev_specs_record_history();
+
+-- Drop the history table
+DROP TABLE IF EXISTS ev_specs_history;
+
+-- Drop the updated_at column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS updated_at;
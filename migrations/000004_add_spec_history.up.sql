@@ -0,0 +1,48 @@
+-- Track when each spec was last changed
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Append-only audit trail of superseded spec values
+CREATE TABLE IF NOT EXISTS ev_specs_history (
+    id SERIAL PRIMARY KEY,
+    spec_id INTEGER NOT NULL,
+    make VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INTEGER NOT NULL,
+    capacity_kwh FLOAT NOT NULL,
+    power_kw FLOAT NOT NULL,
+    chemistry VARCHAR(100) NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    operation VARCHAR(10) NOT NULL,  -- UPDATE or DELETE
+    valid_from TIMESTAMP,            -- when the superseded value was written
+    superseded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS ev_specs_history_key_idx ON ev_specs_history (LOWER(make), LOWER(model), year);
+
+-- Copy the old row into the history table whenever its values change or it is
+-- deleted. Updates that only touch the embedding are not recorded.
+CREATE OR REPLACE FUNCTION ev_specs_record_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags)
+            IS NOT DISTINCT FROM (NEW.capacity_kwh, NEW.power_kw, NEW.chemistry, NEW.tags) THEN
+            RETURN NEW;
+        END IF;
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+
+    INSERT INTO ev_specs_history (spec_id, make, model, year, capacity_kwh, power_kw, chemistry, tags, operation, valid_from)
+    VALUES (OLD.id, OLD.make, OLD.model, OLD.year, OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, TG_OP,
+            COALESCE(OLD.updated_at, OLD.created_at));
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ev_specs_history_trigger ON ev_specs;
+CREATE TRIGGER ev_specs_history_trigger
+    BEFORE UPDATE OR DELETE ON ev_specs
+    FOR EACH ROW EXECUTE FUNCTION ev_specs_record_history();
@@ -0,0 +1,5 @@
+-- Drop the source index
+DROP INDEX IF EXISTS ev_specs_source_idx;
+
+-- Drop the source column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS source;
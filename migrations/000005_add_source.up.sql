@@ -0,0 +1,4 @@
+-- Record where each stored spec came from (database for curated rows, llm for saved fallbacks)
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'database';
+
+CREATE INDEX IF NOT EXISTS ev_specs_source_idx ON ev_specs (source);
@@ -0,0 +1,26 @@
+-- Restore the 000004 history trigger function
+CREATE OR REPLACE FUNCTION ev_specs_record_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags)
+            IS NOT DISTINCT FROM (NEW.capacity_kwh, NEW.power_kw, NEW.chemistry, NEW.tags) THEN
+            RETURN NEW;
+        END IF;
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+
+    INSERT INTO ev_specs_history (spec_id, make, model, year, capacity_kwh, power_kw, chemistry, tags, operation, valid_from)
+    VALUES (OLD.id, OLD.make, OLD.model, OLD.year, OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, TG_OP,
+            COALESCE(OLD.updated_at, OLD.created_at));
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Drop the raw response and source columns
+ALTER TABLE ev_specs_history DROP COLUMN IF EXISTS raw_response;
+ALTER TABLE ev_specs_history DROP COLUMN IF EXISTS source;
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS raw_response;
@@ -0,0 +1,26 @@
+-- Raw LLM output kept for auditing specs saved from the LLM fallback
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS raw_response TEXT;
+ALTER TABLE ev_specs_history ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+ALTER TABLE ev_specs_history ADD COLUMN IF NOT EXISTS raw_response TEXT;
+
+-- Same as 000004, but also keeps the source and raw response of superseded rows
+CREATE OR REPLACE FUNCTION ev_specs_record_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response)
+            IS NOT DISTINCT FROM (NEW.capacity_kwh, NEW.power_kw, NEW.chemistry, NEW.tags, NEW.source, NEW.raw_response) THEN
+            RETURN NEW;
+        END IF;
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+
+    INSERT INTO ev_specs_history (spec_id, make, model, year, capacity_kwh, power_kw, chemistry, tags, source, raw_response, operation, valid_from)
+    VALUES (OLD.id, OLD.make, OLD.model, OLD.year, OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response, TG_OP,
+            COALESCE(OLD.updated_at, OLD.created_at));
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
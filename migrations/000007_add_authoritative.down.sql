@@ -0,0 +1,2 @@
+-- Drop the authoritative column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS authoritative;
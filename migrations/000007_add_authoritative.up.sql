@@ -0,0 +1,2 @@
+-- Hand-verified specs that upserts and saved LLM answers must never overwrite
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS authoritative BOOLEAN NOT NULL DEFAULT false;
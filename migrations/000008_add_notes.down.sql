@@ -0,0 +1,2 @@
+-- Drop the notes column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS notes;
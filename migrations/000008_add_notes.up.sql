@@ -0,0 +1,2 @@
+-- Free-text annotations such as trim differences or where the data came from
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS notes TEXT;
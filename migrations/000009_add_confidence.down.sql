@@ -0,0 +1,2 @@
+-- Drop the stored confidence column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS confidence;
@@ -0,0 +1,3 @@
+-- Confidence supplied when the spec was stored, e.g. 1.0 for a trusted import.
+-- NULL means the confidence is derived from the source.
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS confidence FLOAT;
@@ -0,0 +1,2 @@
+-- Drop the body_style column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS body_style;
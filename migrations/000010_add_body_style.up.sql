@@ -0,0 +1,2 @@
+-- Optional body style such as SUV or Sedan, normalized by the application
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS body_style VARCHAR(20);
@@ -0,0 +1,38 @@
+-- Look up the revisions of a spec by the version they superseded
+CREATE INDEX IF NOT EXISTS ev_specs_history_spec_idx ON ev_specs_history (spec_id, valid_from);
+
+-- Same as 000006, but a superseded version already in the history, identified
+-- by its spec id, operation, valid_from and values, is not recorded again, so a
+-- retried write cannot log the same revision twice. Updates that only change
+-- the notes, confidence, authoritative flag or body style are revisions too.
+CREATE OR REPLACE FUNCTION ev_specs_record_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response,
+            OLD.notes, OLD.confidence, OLD.authoritative, OLD.body_style)
+            IS NOT DISTINCT FROM (NEW.capacity_kwh, NEW.power_kw, NEW.chemistry, NEW.tags, NEW.source, NEW.raw_response,
+            NEW.notes, NEW.confidence, NEW.authoritative, NEW.body_style) THEN
+            RETURN NEW;
+        END IF;
+        NEW.updated_at := CURRENT_TIMESTAMP;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM ev_specs_history h
+        WHERE h.spec_id = OLD.id
+          AND h.operation = TG_OP
+          AND h.valid_from IS NOT DISTINCT FROM COALESCE(OLD.updated_at, OLD.created_at)
+          AND (h.capacity_kwh, h.power_kw, h.chemistry, h.tags, h.source, h.raw_response)
+              IS NOT DISTINCT FROM (OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response)
+    ) THEN
+        INSERT INTO ev_specs_history (spec_id, make, model, year, capacity_kwh, power_kw, chemistry, tags, source, raw_response, operation, valid_from)
+        VALUES (OLD.id, OLD.make, OLD.model, OLD.year, OLD.capacity_kwh, OLD.power_kw, OLD.chemistry, OLD.tags, OLD.source, OLD.raw_response, TG_OP,
+                COALESCE(OLD.updated_at, OLD.created_at));
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+-- Drop the embedding cache
+DROP TABLE IF EXISTS embedding_cache;
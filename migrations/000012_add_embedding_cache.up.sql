@@ -0,0 +1,9 @@
+-- Embeddings shared across processes, keyed by model and SHA-256 of the input
+-- text. REAL[] rather than vector, since cached models differ in dimension.
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    model VARCHAR(200) NOT NULL,
+    text_sha256 CHAR(64) NOT NULL,
+    embedding REAL[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model, text_sha256)
+);
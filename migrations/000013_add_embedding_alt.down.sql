@@ -0,0 +1,2 @@
+-- Drop the secondary embedding column
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS embedding_alt;
@@ -0,0 +1,3 @@
+-- Optional second embedding per row for comparing embedding models. The
+-- dimension is left open so any model fits, which also means it has no index.
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS embedding_alt vector;
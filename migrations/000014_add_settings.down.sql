@@ -0,0 +1,2 @@
+-- Drop the settings table
+DROP TABLE IF EXISTS settings;
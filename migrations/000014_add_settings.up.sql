@@ -0,0 +1,7 @@
+-- Key/value settings recorded by the CLI, such as which embedding text
+-- template the stored embeddings were built with
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
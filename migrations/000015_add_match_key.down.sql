@@ -0,0 +1,4 @@
+-- Drop the normalized lookup key
+DROP INDEX IF EXISTS ev_specs_match_key_idx;
+ALTER TABLE ev_specs DROP COLUMN IF EXISTS match_key;
+DROP FUNCTION IF EXISTS ev_match_key(TEXT, TEXT, INTEGER);
@@ -0,0 +1,27 @@
+-- Normalized lookup key: lowercase make and model with whitespace and
+-- punctuation removed, plus the year, e.g. 'tesla|model3|2022'. Exact lookups
+-- compare keys instead of LOWER() at query time, so they use the unique index
+-- and also match "Model-3" or "model3" to a stored "Model 3".
+CREATE OR REPLACE FUNCTION ev_match_key(make TEXT, model TEXT, year INTEGER) RETURNS TEXT AS $$
+    SELECT regexp_replace(lower(make), '[^[:alnum:]]+', '', 'g') || '|' ||
+           regexp_replace(lower(model), '[^[:alnum:]]+', '', 'g') || '|' || year
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- A generated column is filled for existing rows here and on every insert
+ALTER TABLE ev_specs ADD COLUMN IF NOT EXISTS match_key TEXT
+    GENERATED ALWAYS AS (ev_match_key(make, model, year)) STORED;
+
+-- Rows that only differed by case or punctuation now share a key; fail with
+-- the offending keys rather than an opaque unique violation
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(match_key, ', ') INTO duplicates
+    FROM (SELECT match_key FROM ev_specs GROUP BY match_key HAVING COUNT(*) > 1 LIMIT 20) d;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'ev_specs has rows that differ only by case or punctuation (keys: %); delete or rename all but one of each and run the migration again', duplicates;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ev_specs_match_key_idx ON ev_specs (match_key);